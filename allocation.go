@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// unlabeledValue groups billed cost from resources without the allocation label
+const unlabeledValue = "(unlabeled)"
+
+// lastMonthLabelCostQuery sums last month's net cost per service and resource label for one project
+const lastMonthLabelCostQuery = "SELECT service.description AS service, l.key AS key, l.value AS value, " +
+	"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS cost " +
+	"FROM `%s`, UNNEST(labels) l " +
+	"WHERE invoice.month = FORMAT_DATE('%%Y%%m', DATE_SUB(CURRENT_DATE(), INTERVAL 1 MONTH)) " +
+	"AND project.id = @project " +
+	"AND l.key IN UNNEST(@keys) " +
+	"GROUP BY service, key, value"
+
+// CostAllocation is the billed cost carrying one label value, e.g. team=payments
+type CostAllocation struct {
+	Label string  `json:"label"`
+	Value string  `json:"value"`
+	Cost  float64 `json:"cost"`
+	// APIs is the cost per API name within this label value
+	APIs map[string]float64 `json:"apis"`
+}
+
+// getLabelCosts queries the billing export for last month's cost per API, label key and label value
+func (c *GoogleAPIChecker) getLabelCosts(table string, labels []string) (map[string]map[string]map[string]float64, error) {
+	keys := make([]map[string]string, len(labels))
+	for i, label := range labels {
+		keys[i] = map[string]string{"value": label}
+	}
+	params := []map[string]interface{}{{
+		"name": "keys",
+		"parameterType": map[string]interface{}{
+			"type":      "ARRAY",
+			"arrayType": map[string]string{"type": "STRING"},
+		},
+		"parameterValue": map[string]interface{}{"arrayValues": keys},
+	}}
+
+	rows, _, err := c.queryBillingExport(fmt.Sprintf(lastMonthLabelCostQuery, table), params)
+	if err != nil {
+		return nil, err
+	}
+
+	// API name -> label key -> label value -> cost
+	costs := make(map[string]map[string]map[string]float64)
+	for _, row := range rows {
+		if len(row) < 4 || row[0] == nil || row[1] == nil || row[3] == nil {
+			continue
+		}
+		apiName, ok := billingServiceAPIs[*row[0]]
+		if !ok {
+			continue
+		}
+		cost, err := strconv.ParseFloat(*row[3], 64)
+		if err != nil {
+			continue
+		}
+		value := unlabeledValue
+		if row[2] != nil && *row[2] != "" {
+			value = *row[2]
+		}
+
+		if costs[apiName] == nil {
+			costs[apiName] = make(map[string]map[string]float64)
+		}
+		if costs[apiName][*row[1]] == nil {
+			costs[apiName][*row[1]] = make(map[string]float64)
+		}
+		costs[apiName][*row[1]][value] += cost
+	}
+
+	return costs, nil
+}
+
+// allocateByLabel splits an API's billed cost by label value; cost from resources
+// without the label is attributed to unlabeledValue
+func allocateByLabel(total float64, labelCosts map[string]map[string]float64, labels []string) map[string]map[string]float64 {
+	if total == 0 {
+		return nil
+	}
+
+	allocation := make(map[string]map[string]float64, len(labels))
+	for _, label := range labels {
+		values := make(map[string]float64)
+		remaining := total
+		for value, cost := range labelCosts[label] {
+			values[value] += cost
+			remaining -= cost
+		}
+		// Ignore rounding leftovers below a cent
+		if math.Abs(remaining) >= 0.005 {
+			values[unlabeledValue] += remaining
+		}
+		allocation[label] = values
+	}
+	return allocation
+}
+
+// buildCostAllocation totals billed cost per label value across all projects,
+// sorted by label and then by cost (highest first)
+func buildCostAllocation(results []APIResult) []CostAllocation {
+	index := make(map[[2]string]*CostAllocation)
+	var allocations []*CostAllocation
+	for _, result := range results {
+		for label, values := range result.CostInfo.ActualCostByLabel {
+			for value, cost := range values {
+				key := [2]string{label, value}
+				entry, ok := index[key]
+				if !ok {
+					entry = &CostAllocation{Label: label, Value: value, APIs: make(map[string]float64)}
+					index[key] = entry
+					allocations = append(allocations, entry)
+				}
+				entry.Cost += cost
+				entry.APIs[result.Name] += cost
+			}
+		}
+	}
+
+	sorted := make([]CostAllocation, len(allocations))
+	for i, entry := range allocations {
+		sorted[i] = *entry
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Label != sorted[j].Label {
+			return sorted[i].Label < sorted[j].Label
+		}
+		if sorted[i].Cost != sorted[j].Cost {
+			return sorted[i].Cost > sorted[j].Cost
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+// writeCostAllocationCSV writes one row per project, label value and API for chargeback
+func writeCostAllocationCSV(filename string, results []APIResult, delimiter rune) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+
+	if err := writer.Write([]string{"project", "label", "value", "name", "display_name", "actual_cost"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %v", err)
+	}
+
+	for _, result := range results {
+		labels := make([]string, 0, len(result.CostInfo.ActualCostByLabel))
+		for label := range result.CostInfo.ActualCostByLabel {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		for _, label := range labels {
+			values := result.CostInfo.ActualCostByLabel[label]
+			names := make([]string, 0, len(values))
+			for value := range values {
+				names = append(names, value)
+			}
+			sort.Strings(names)
+
+			for _, value := range names {
+				row := []string{result.ProjectID, label, value, result.Name, result.DisplayName,
+					strconv.FormatFloat(values[value], 'f', 2, 64)}
+				if err := writer.Write(row); err != nil {
+					return fmt.Errorf("failed to write CSV row: %v", err)
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %v", err)
+	}
+
+	fmt.Printf("✅ Cost allocation CSV exported to: %s\n", filename)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Annotation formats for --annotations
+const (
+	annotationsNone   = ""
+	annotationsGitHub = "github"
+)
+
+// ParseAnnotations validates an --annotations format
+func ParseAnnotations(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case annotationsNone:
+		return annotationsNone, nil
+	case annotationsGitHub:
+		return annotationsGitHub, nil
+	}
+	return "", fmt.Errorf("invalid --annotations %q (use github)", format)
+}
+
+// printGitHubAnnotations writes a GitHub Actions workflow command for every policy violation and drift
+// (as an error) and unlimited-cost API (as a warning), so they show up in the workflow run summary
+func printGitHubAnnotations(w io.Writer, report *Report) {
+	for _, violation := range report.PolicyViolations {
+		title := "Policy violation: " + violation.Rule
+		if violation.ProjectID != "" {
+			title += " (" + violation.ProjectID + ")"
+		}
+		fmt.Fprintf(w, "::error title=%s::%s\n", escapeAnnotationProperty(title), escapeAnnotationData(violation.Message))
+	}
+
+	for _, drift := range report.Drift {
+		title := "Drift: " + drift.Kind + " API"
+		if drift.ProjectID != "" {
+			title += " (" + drift.ProjectID + ")"
+		}
+		fmt.Fprintf(w, "::error title=%s::%s\n", escapeAnnotationProperty(title), escapeAnnotationData(drift.String()))
+	}
+
+	for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
+		message := fmt.Sprintf("%s has unlimited cost potential; set a quota or budget alert. %s", incidentAPILabel(api), api.CostInfo.PricingDetails)
+		fmt.Fprintf(w, "::warning title=%s::%s\n", escapeAnnotationProperty("Unlimited cost: "+api.Name), escapeAnnotationData(strings.TrimSpace(message)))
+	}
+}
+
+// escapeAnnotationData escapes a workflow command message so it stays on one line
+func escapeAnnotationData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeAnnotationProperty escapes a workflow command property such as title
+func escapeAnnotationProperty(s string) string {
+	s = escapeAnnotationData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
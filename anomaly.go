@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defaultAnomalyThreshold is the cost increase, in percent, that is flagged as an anomaly
+const defaultAnomalyThreshold = 50.0
+
+// CostAnomaly is an API whose estimated cost jumped versus the previous scan
+type CostAnomaly struct {
+	ProjectID    string  `json:"project_id,omitempty"`
+	API          string  `json:"api"`
+	DisplayName  string  `json:"display_name"`
+	PreviousCost float64 `json:"previous_cost"`
+	CurrentCost  float64 `json:"current_cost"`
+	// ChangePercent is 0 when the API had no cost in the previous scan
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// String describes the cost change
+func (a CostAnomaly) String() string {
+	name := a.DisplayName
+	if a.ProjectID != "" {
+		name = fmt.Sprintf("[%s] %s", a.ProjectID, name)
+	}
+	if a.PreviousCost == 0 {
+		return fmt.Sprintf("%s: new cost of %s", name, formatCost(a.CurrentCost))
+	}
+	return fmt.Sprintf("%s: $%.2f → %s (+%.0f%%)", name, projection.amount(a.PreviousCost), formatCost(a.CurrentCost), a.ChangePercent)
+}
+
+// DetectCostAnomalies compares enabled APIs with the previous scan and returns those
+// whose estimated cost grew by more than thresholdPercent
+func DetectCostAnomalies(previous, current []APIResult, thresholdPercent float64) []CostAnomaly {
+	previousCosts := make(map[string]float64)
+	for _, result := range previous {
+		if result.Enabled && result.CostInfo.HasPricing {
+			previousCosts[result.ProjectID+"/"+result.Name] = result.CostInfo.EstimatedCost
+		}
+	}
+
+	var anomalies []CostAnomaly
+	for _, result := range current {
+		if !result.Enabled || !result.CostInfo.HasPricing || result.CostInfo.EstimatedCost == 0 {
+			continue
+		}
+
+		previousCost := previousCosts[result.ProjectID+"/"+result.Name]
+		anomaly := CostAnomaly{
+			ProjectID:    result.ProjectID,
+			API:          result.Name,
+			DisplayName:  result.DisplayName,
+			PreviousCost: previousCost,
+			CurrentCost:  result.CostInfo.EstimatedCost,
+		}
+
+		if previousCost > 0 {
+			anomaly.ChangePercent = (anomaly.CurrentCost - previousCost) / previousCost * 100
+			if anomaly.ChangePercent <= thresholdPercent {
+				continue
+			}
+		}
+
+		anomalies = append(anomalies, anomaly)
+	}
+
+	// Largest absolute increase first
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].CurrentCost-anomalies[i].PreviousCost > anomalies[j].CurrentCost-anomalies[j].PreviousCost
+	})
+
+	return anomalies
+}
+
+// SetCostAnomalies records the anomalies on the report and puts them first in the recommendations
+func (r *Report) SetCostAnomalies(anomalies []CostAnomaly, thresholdPercent float64) {
+	r.CostAnomalies = anomalies
+	if len(anomalies) == 0 {
+		return
+	}
+
+	recommendations := []string{
+		fmt.Sprintf("📈 ALERT: %d APIs increased in cost by more than %.0f%% since the previous scan:", len(anomalies), thresholdPercent),
+	}
+	for _, anomaly := range anomalies {
+		recommendations = append(recommendations, fmt.Sprintf("   - %s", anomaly))
+	}
+	r.Recommendations = append(recommendations, r.Recommendations...)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// apiRetryBackoff is the wait before the first retry of a status check; each further retry waits one more
+const apiRetryBackoff = time.Second
+
+// APIOverride changes how one service is checked, so a flaky API, e.g. an allow-listed
+// private service, does not poison the scan
+type APIOverride struct {
+	// Timeout bounds each status request, e.g. 10s; 0 keeps the default of 30s
+	Timeout time.Duration `yaml:"timeout"`
+	// Retries is how many more times a failed status check is tried
+	Retries int `yaml:"retries"`
+	// Skip leaves the service out of the scan
+	Skip bool `yaml:"skip"`
+	// Warn reports a failed check as a warning with an UNKNOWN status instead of an error
+	Warn bool `yaml:"warn"`
+}
+
+// APIOverrides maps a service, e.g. myapi.googleapis.com or just myapi, to its override
+type APIOverrides map[string]APIOverride
+
+// lookup returns the override of a service, the zero override when it has none
+func (o APIOverrides) lookup(apiName string) APIOverride {
+	if override, ok := o[apiName]; ok {
+		return override
+	}
+	return o[strings.TrimSuffix(apiName, "."+defaultUniverseDomain)]
+}
+
+// validate rejects negative timeouts and retries
+func (o APIOverrides) validate() error {
+	for service, override := range o {
+		if override.Timeout < 0 {
+			return fmt.Errorf("apis.%s.timeout must not be negative", service)
+		}
+		if override.Retries < 0 {
+			return fmt.Errorf("apis.%s.retries must not be negative", service)
+		}
+	}
+	return nil
+}
+
+// skipOverridden drops the services whose override skips them
+func (c *GoogleAPIChecker) skipOverridden(apis []string) []string {
+	kept := make([]string, 0, len(apis))
+	for _, api := range apis {
+		if !c.options.APIOverrides.lookup(api).Skip {
+			kept = append(kept, api)
+		}
+	}
+	if skipped := len(apis) - len(kept); skipped > 0 {
+		c.status("⏭️  Skipping %d APIs per the config file", skipped)
+	}
+	return kept
+}
+
+// checkServiceState returns the service's state, with the timeout and retries of its override
+func (c *GoogleAPIChecker) checkServiceState(ctx context.Context, apiName string) (string, error) {
+	override := c.options.APIOverrides.lookup(apiName)
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if override.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, override.Timeout)
+		}
+		state, err := c.states.ServiceState(attemptCtx, apiName)
+		cancel()
+		if err == nil || errors.Is(err, errUndetermined) || attempt > override.Retries {
+			return state, err
+		}
+
+		c.trace(ctx, "%s: attempt %d of %d failed, retrying: %v", apiName, attempt, override.Retries+1, err)
+		select {
+		case <-time.After(time.Duration(attempt) * apiRetryBackoff):
+		case <-ctx.Done():
+			return state, err
+		}
+	}
+}
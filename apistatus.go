@@ -0,0 +1,79 @@
+package main
+
+// API statuses recorded in APIResult.Status
+const (
+	statusEnabled          = "ENABLED"
+	statusDisabled         = "DISABLED"
+	statusEnabling         = "ENABLING"  // Enable requested, not serving yet
+	statusDisabling        = "DISABLING" // Disable requested, still serving
+	statusStateUnspecified = "STATE_UNSPECIFIED"
+	statusDeprecated       = "DEPRECATED" // Marked deprecated in the Discovery directory
+	statusUnknown          = "UNKNOWN"    // Cannot be determined, e.g. without a project
+	statusSkipped          = "SKIPPED"    // Not checked because the Service Usage quota ran out
+	statusError            = "ERROR"
+)
+
+// apiStatusStyle is how a status is shown in the console, PDF and HTML reports
+type apiStatusStyle struct {
+	Symbol string // Symbol shown next to the status so it is not told apart by color alone
+	ANSI   string // Console color
+}
+
+// apiStatusStyles maps each status to its style
+var apiStatusStyles = map[string]apiStatusStyle{
+	statusEnabled:          {"✔", "\033[32m"},
+	statusDisabled:         {"✖", "\033[31m"},
+	statusEnabling:         {"↗", "\033[36m"},
+	statusDisabling:        {"↘", "\033[33m"},
+	statusStateUnspecified: {"?", "\033[90m"},
+	statusDeprecated:       {"⊘", "\033[35m"},
+	statusUnknown:          {"?", "\033[90m"},
+	statusSkipped:          {"»", "\033[90m"},
+	statusError:            {"⚠", "\033[33m"},
+}
+
+// statusForState converts a Service Usage state to a result status
+func statusForState(state string) string {
+	switch state {
+	case statusEnabled, statusDisabled, statusEnabling, statusDisabling:
+		return state
+	}
+	return statusStateUnspecified
+}
+
+// statusServing reports whether an API in this Service Usage state accepts calls, and so can cost money
+func statusServing(status string) bool {
+	return status == statusEnabled || status == statusDisabling
+}
+
+// statusUndetermined reports whether the status says nothing about the API being enabled
+func statusUndetermined(status string) bool {
+	return status == statusUnknown || status == statusStateUnspecified || status == statusSkipped
+}
+
+// countStatus counts the results with the status
+func countStatus(results []APIResult, status string) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// statusBadge returns the status with its symbol, e.g. "✔ ENABLED"
+func statusBadge(status string) string {
+	if style, ok := apiStatusStyles[status]; ok {
+		return style.Symbol + " " + status
+	}
+	return status
+}
+
+// coloredStatus returns the status badge in its console color
+func coloredStatus(status string) string {
+	if style, ok := apiStatusStyles[status]; ok {
+		return style.ANSI + statusBadge(status) + "\033[0m"
+	}
+	return status
+}
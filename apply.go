@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// batchEnableSize is the most services Service Usage enables in one batchEnable request
+const batchEnableSize = 20
+
+// Enabling of missing APIs, set from --apply and --yes
+var (
+	applyDrift bool
+	assumeYes  bool
+)
+
+// missingAPIs lists, per project in drift order, the required APIs that are not enabled or being enabled
+func missingAPIs(drift []Drift) ([]string, map[string][]string) {
+	var projects []string
+	missing := make(map[string][]string)
+	for _, d := range drift {
+		if d.Kind != driftMissing || d.ProjectID == "" || d.Status == statusEnabling {
+			continue
+		}
+		if _, ok := missing[d.ProjectID]; !ok {
+			projects = append(projects, d.ProjectID)
+		}
+		missing[d.ProjectID] = append(missing[d.ProjectID], d.API)
+	}
+	return projects, missing
+}
+
+// confirmApply asks on the terminal whether to go ahead; without a terminal only --yes goes ahead
+func confirmApply(count int) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("--apply needs confirmation on a terminal; pass --yes to enable the APIs without asking")
+	}
+
+	fmt.Printf("❓ Enable these %d APIs? [y/N]: ", count)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// applyMissingAPIs enables the required APIs the drift lists as missing, after confirmation.
+// Enabling runs as a Service Usage operation, so the next scan shows the APIs as enabled.
+func applyMissingAPIs(drift []Drift, options CheckerOptions) error {
+	projects, missing := missingAPIs(drift)
+	if len(projects) == 0 {
+		fmt.Println("🧭 No required APIs to enable")
+		return nil
+	}
+
+	count := 0
+	fmt.Println("🛠️  --apply will enable these required APIs:")
+	for _, projectID := range projects {
+		for _, api := range missing[projectID] {
+			fmt.Printf("   • [%s] %s\n", projectID, api)
+		}
+		count += len(missing[projectID])
+	}
+
+	ok, err := confirmApply(count)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("⏭️  Nothing enabled")
+		return nil
+	}
+
+	failed := 0
+	for _, projectID := range projects {
+		options.NoProgress = true
+		checker := NewGoogleAPIChecker(apiToken, projectID, 1, options)
+		apis := missing[projectID]
+		for start := 0; start < len(apis); start += batchEnableSize {
+			batch := apis[start:min(start+batchEnableSize, len(apis))]
+			operation, err := checker.enableServices(batch)
+			if options.Context != nil && options.Context.Err() != nil {
+				return fmt.Errorf("enabling APIs stopped: %v", options.Context.Err())
+			}
+			if err != nil {
+				failed += len(batch)
+				fmt.Printf("❌ [%s] Could not enable %s: %v\n", projectID, strings.Join(batch, ", "), err)
+				continue
+			}
+			fmt.Printf("✅ [%s] Enabling %d APIs (operation %s)\n", projectID, len(batch), operation)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d APIs could not be enabled", failed, count)
+	}
+	return nil
+}
+
+// enableServices starts enabling the services in the project and returns the operation's name.
+// Service Usage only enables services for an OAuth identity, so the call is authorized with the
+// gcloud or metadata server access token rather than the API key.
+func (c *GoogleAPIChecker) enableServices(apis []string) (string, error) {
+	usage, err := c.serviceUsage()
+	if err != nil {
+		return "", err
+	}
+	accessToken, err := gcloudAccessToken()
+	if err != nil {
+		return "", fmt.Errorf("enabling APIs needs an OAuth access token: %v", err)
+	}
+	operation, err := usage.BatchEnableServices(withAccessToken(c.ctx, accessToken), "projects/"+c.projectID, apis)
+	if err != nil {
+		return "", clientError("batch enable", err)
+	}
+	return operation.Name, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceusage "google.golang.org/api/serviceusage/v1"
+)
+
+// enableRecorder is a Service Usage stub recording the batchEnable calls
+type enableRecorder struct {
+	serviceUsageAPI
+	ctx context.Context
+}
+
+func (u *enableRecorder) BatchEnableServices(ctx context.Context, parent string, serviceIDs []string) (*serviceusage.Operation, error) {
+	u.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &serviceusage.Operation{Name: "operations/enable"}, nil
+}
+
+func TestEnableServicesUsesAccessTokenAndContext(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "oauth-token")
+	ctx, cancel := context.WithCancel(context.Background())
+	usage := &enableRecorder{}
+	checker := NewGoogleAPIChecker("api-key", "test-project", 1, CheckerOptions{NoProgress: true, Context: ctx, ServiceUsage: usage})
+
+	operation, err := checker.enableServices([]string{"compute.googleapis.com"})
+	if err != nil || operation != "operations/enable" {
+		t.Fatalf("got %q, %v", operation, err)
+	}
+	if token, _ := usage.ctx.Value(accessTokenKey{}).(string); token != "oauth-token" {
+		t.Errorf("batchEnable context carries token %q, want the OAuth access token", token)
+	}
+
+	// The scan's context cancels an enable request
+	cancel()
+	if _, err := checker.enableServices([]string{"compute.googleapis.com"}); err == nil {
+		t.Error("enable request ran after the scan was cancelled")
+	}
+}
+
+func TestCheckerTransportCredentials(t *testing.T) {
+	var apiKey, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey, authorization = r.Header.Get("X-Goog-Api-Key"), r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	checker := NewGoogleAPIChecker("api-key", "test-project", 1, CheckerOptions{NoProgress: true})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		apiKey        string
+		authorization string
+	}{
+		{"API key", context.Background(), "api-key", ""},
+		{"OAuth access token", withAccessToken(context.Background(), "oauth-token"), "", "Bearer oauth-token"},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequestWithContext(test.ctx, http.MethodPost, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := checkerTransport{checker}.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if apiKey != test.apiKey || authorization != test.authorization {
+			t.Errorf("%s: sent key %q and authorization %q", test.name, apiKey, authorization)
+		}
+	}
+}
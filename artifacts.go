@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultFilenameTemplate names every artifact after the scanned project, the scan date and the artifact type
+const defaultFilenameTemplate = "{project}_{date}_{type}.{ext}"
+
+// filenamePlaceholder matches the placeholders in a filename template
+var filenamePlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// filenamePlaceholders are the placeholders a filename template may use
+var filenamePlaceholders = map[string]bool{
+	"project": true, "date": true, "time": true, "timestamp": true, "type": true, "ext": true,
+}
+
+// ArtifactNamer builds the paths of a scan's output files from the output directory and filename template
+type ArtifactNamer struct {
+	Dir      string
+	Template string
+	Project  string    // Project ID, "multi-project" or "default" without --project
+	Time     time.Time // When the scan ran, for {date}, {time} and {timestamp}
+
+	named *[]string // Every path handed out, for --bundle
+}
+
+// NewArtifactNamer checks the filename template and names the artifacts of a scan of the projects
+func NewArtifactNamer(dir, template string, projects []string, scanTime time.Time) (ArtifactNamer, error) {
+	if template == "" {
+		template = defaultFilenameTemplate
+	}
+	if err := validateFilenameTemplate(template); err != nil {
+		return ArtifactNamer{}, err
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	project := "default"
+	switch {
+	case len(projects) == 1 && projects[0] != "":
+		project = projects[0]
+	case len(projects) > 1:
+		project = "multi-project"
+	}
+
+	return ArtifactNamer{Dir: dir, Template: template, Project: project, Time: scanTime, named: new([]string)}, nil
+}
+
+// validateFilenameTemplate rejects unknown placeholders and templates whose artifacts would overwrite each other
+func validateFilenameTemplate(template string) error {
+	for _, match := range filenamePlaceholder.FindAllStringSubmatch(template, -1) {
+		if !filenamePlaceholders[match[1]] {
+			return fmt.Errorf("unknown placeholder {%s} in filename template (use {project}, {date}, {time}, {timestamp}, {type} and {ext})", match[1])
+		}
+	}
+	if !strings.Contains(template, "{type}") || !strings.Contains(template, "{ext}") {
+		return fmt.Errorf("filename template %q must contain {type} and {ext} so artifacts do not overwrite each other", template)
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("filename template %q must not contain directories; use --output-dir", template)
+	}
+	return nil
+}
+
+// Path returns the path of the artifact of the given type, e.g. Path("report", "html")
+func (n ArtifactNamer) Path(kind, ext string) string {
+	values := map[string]string{
+		"project":   sanitizeFilename(n.Project),
+		"date":      n.Time.Format("20060102"),
+		"time":      n.Time.Format("150405"),
+		"timestamp": n.Time.Format("20060102_150405"),
+		"type":      kind,
+		"ext":       ext,
+	}
+	name := filenamePlaceholder.ReplaceAllStringFunc(n.Template, func(placeholder string) string {
+		return values[strings.Trim(placeholder, "{}")]
+	})
+
+	path := filepath.Join(n.Dir, name)
+	if n.named != nil {
+		for _, named := range *n.named {
+			if named == path {
+				return path
+			}
+		}
+		*n.named = append(*n.named, path)
+	}
+	return path
+}
+
+// Named returns every artifact path handed out so far
+func (n ArtifactNamer) Named() []string {
+	if n.named == nil {
+		return nil
+	}
+	return append([]string(nil), *n.named...)
+}
+
+// EnsureDir creates the output directory
+func (n ArtifactNamer) EnsureDir() error {
+	if err := os.MkdirAll(n.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	return nil
+}
+
+// sanitizeFilename replaces characters that are not safe in file names
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '-'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// assetServices maps APIs whose resources Cloud Asset Inventory tracks to the service
+// prefix of their asset types, e.g. compute.googleapis.com/Instance
+var assetServices = map[string]string{
+	"compute.googleapis.com":          "compute.googleapis.com",
+	"storage.googleapis.com":          "storage.googleapis.com",
+	"bigquery.googleapis.com":         "bigquery.googleapis.com",
+	"pubsub.googleapis.com":           "pubsub.googleapis.com",
+	"cloudfunctions.googleapis.com":   "cloudfunctions.googleapis.com",
+	"run.googleapis.com":              "run.googleapis.com",
+	"container.googleapis.com":        "container.googleapis.com",
+	"cloudsql.googleapis.com":         "sqladmin.googleapis.com",
+	"sqladmin.googleapis.com":         "sqladmin.googleapis.com",
+	"spanner.googleapis.com":          "spanner.googleapis.com",
+	"bigtable.googleapis.com":         "bigtableadmin.googleapis.com",
+	"bigtableadmin.googleapis.com":    "bigtableadmin.googleapis.com",
+	"redis.googleapis.com":            "redis.googleapis.com",
+	"file.googleapis.com":             "file.googleapis.com",
+	"secretmanager.googleapis.com":    "secretmanager.googleapis.com",
+	"cloudkms.googleapis.com":         "cloudkms.googleapis.com",
+	"dataproc.googleapis.com":         "dataproc.googleapis.com",
+	"dataflow.googleapis.com":         "dataflow.googleapis.com",
+	"composer.googleapis.com":         "composer.googleapis.com",
+	"artifactregistry.googleapis.com": "artifactregistry.googleapis.com",
+	"dns.googleapis.com":              "dns.googleapis.com",
+	"cloudtasks.googleapis.com":       "cloudtasks.googleapis.com",
+	"cloudscheduler.googleapis.com":   "cloudscheduler.googleapis.com",
+	"aiplatform.googleapis.com":       "aiplatform.googleapis.com",
+	"appengine.googleapis.com":        "appengine.googleapis.com",
+	"firestore.googleapis.com":        "firestore.googleapis.com",
+	"datastore.googleapis.com":        "datastore.googleapis.com",
+}
+
+// annotateResourceCounts sets ResourceCount on enabled APIs whose resources Cloud Asset Inventory tracks
+func (c *GoogleAPIChecker) annotateResourceCounts(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("resource counts require a project ID")
+	}
+
+	counts, err := c.getAssetCounts()
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		service, ok := assetServices[results[i].Name]
+		if !results[i].Enabled || !ok {
+			continue
+		}
+		count := counts[service]
+		results[i].ResourceCount = &count
+	}
+	return nil
+}
+
+// getAssetCounts counts the project's resources per asset type service prefix
+func (c *GoogleAPIChecker) getAssetCounts() (map[string]int, error) {
+	counts := make(map[string]int)
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("pageSize", "500")
+		query.Set("readMask", "assetType")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		requestURL := fmt.Sprintf("https://cloudasset.googleapis.com/v1/projects/%s:searchAllResources?%s", c.projectID, query.Encode())
+
+		var result struct {
+			Results []struct {
+				AssetType string `json:"assetType"`
+			} `json:"results"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.getJSON(requestURL, "Cloud Asset Inventory resources", &result); err != nil {
+			return nil, err
+		}
+
+		for _, resource := range result.Results {
+			service, _, _ := strings.Cut(resource.AssetType, "/")
+			counts[service]++
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return counts, nil
+}
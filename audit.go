@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// auditEnableMethods are the Service Usage methods that enable services
+var auditEnableMethods = []string{
+	"google.api.serviceusage.v1.ServiceUsage.EnableService",
+	"google.api.serviceusage.v1.ServiceUsage.BatchEnableServices",
+}
+
+// auditMaxPages caps how many pages of audit log entries are read per project
+const auditMaxPages = 20
+
+// enablementRecord is who enabled a service and when, taken from the audit log
+type enablementRecord struct {
+	Principal string
+	Timestamp time.Time
+}
+
+// annotateEnablement fills EnabledBy/EnabledAt on enabled results from Cloud Audit Logs
+func (c *GoogleAPIChecker) annotateEnablement(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("audit log correlation requires a project ID")
+	}
+
+	records, err := c.getEnablementRecords()
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		if !results[i].Enabled {
+			continue
+		}
+		if record, ok := records[results[i].Name]; ok {
+			enabledAt := record.Timestamp
+			results[i].EnabledBy = record.Principal
+			results[i].EnabledAt = &enabledAt
+		}
+	}
+
+	return nil
+}
+
+// getEnablementRecords reads EnableService audit entries and returns the latest one per service
+func (c *GoogleAPIChecker) getEnablementRecords() (map[string]enablementRecord, error) {
+	var filters []string
+	for _, method := range auditEnableMethods {
+		filters = append(filters, fmt.Sprintf("protoPayload.methodName=%q", method))
+	}
+
+	records := make(map[string]enablementRecord)
+	pageToken := ""
+	for page := 0; page < auditMaxPages; page++ {
+		body := map[string]interface{}{
+			"resourceNames": []string{"projects/" + c.projectID},
+			"filter":        strings.Join(filters, " OR "),
+			"orderBy":       "timestamp desc",
+			"pageSize":      1000,
+		}
+		if pageToken != "" {
+			body["pageToken"] = pageToken
+		}
+
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit log query: %v", err)
+		}
+
+		req, err := http.NewRequest("POST", "https://logging.googleapis.com/v2/entries:list", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Add("X-Goog-Api-Key", c.token)
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query audit logs: %v", err)
+		}
+
+		var result struct {
+			Entries []struct {
+				Timestamp    time.Time `json:"timestamp"`
+				ProtoPayload struct {
+					ResourceName       string `json:"resourceName"`
+					AuthenticationInfo struct {
+						PrincipalEmail string `json:"principalEmail"`
+					} `json:"authenticationInfo"`
+					Request struct {
+						ServiceIDs []string `json:"serviceIds"`
+					} `json:"request"`
+				} `json:"protoPayload"`
+			} `json:"entries"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to query audit logs, status: %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit log response: %v", err)
+		}
+
+		for _, entry := range result.Entries {
+			services := entry.ProtoPayload.Request.ServiceIDs
+			if len(services) == 0 && entry.ProtoPayload.ResourceName != "" {
+				parts := strings.Split(entry.ProtoPayload.ResourceName, "/")
+				services = []string{parts[len(parts)-1]}
+			}
+
+			for _, service := range services {
+				// Entries are newest first, so keep the first one seen
+				if _, seen := records[service]; !seen {
+					records[service] = enablementRecord{
+						Principal: entry.ProtoPayload.AuthenticationInfo.PrincipalEmail,
+						Timestamp: entry.Timestamp,
+					}
+				}
+			}
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return records, nil
+}
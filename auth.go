@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// Keychain entry used to store the API token
+const (
+	keyringService = "googleapichecker"
+	keyringUser    = "api-token"
+)
+
+// keyringToken returns the token stored by "auth login", or "" when none is stored
+func keyringToken() (string, error) {
+	token, err := keyring.Get(keyringService, keyringUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token from keychain: %v", err)
+	}
+	return token, nil
+}
+
+// newAuthCmd creates the auth subcommand group for keychain credential storage
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Store or remove the API token in the OS keychain",
+		Long: `Store the API token in the operating system keychain (macOS Keychain,
+Windows Credential Manager or the Secret Service on Linux) so later runs
+need no credential flags.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "login",
+		Short: "Save an API token in the OS keychain",
+		Long: `Save an API token in the OS keychain. The token is taken from --token or
+--token-from when given, otherwise it is read from stdin without echo.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := apiToken
+			if token == "" {
+				var err error
+				token, err = promptToken()
+				if err != nil {
+					return err
+				}
+			}
+			if token == "" {
+				return fmt.Errorf("no token provided")
+			}
+
+			if err := keyring.Set(keyringService, keyringUser, token); err != nil {
+				return fmt.Errorf("failed to store token in keychain: %v", err)
+			}
+
+			fmt.Println("✅ Token saved to the OS keychain")
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored API token from the OS keychain",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := keyring.Delete(keyringService, keyringUser)
+			if errors.Is(err, keyring.ErrNotFound) {
+				fmt.Println("ℹ️  No token stored in the OS keychain")
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to remove token from keychain: %v", err)
+			}
+
+			fmt.Println("✅ Token removed from the OS keychain")
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// promptToken reads a token from stdin, hiding input on a terminal
+func promptToken() (string, error) {
+	if !isTerminal(os.Stdin) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read token from stdin: %v", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Print("🔑 API token: ")
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// ServiceLister lists the services a scan checks
+type ServiceLister interface {
+	ListServices(ctx context.Context) ([]string, error)
+}
+
+// StatusChecker returns a service's state, e.g. ENABLED or DISABLED
+type StatusChecker interface {
+	ServiceState(ctx context.Context, apiName string) (string, error)
+}
+
+// PricingProvider returns the cost information of a service
+type PricingProvider interface {
+	CostInfo(ctx context.Context, apiName string) (CostInfo, error)
+}
+
+// googleBackend is the default backend: Service Usage and Discovery with a credential, the
+// catalog and simulated states without one
+type googleBackend struct {
+	checker *GoogleAPIChecker
+}
+
+func (b googleBackend) ListServices(ctx context.Context) ([]string, error) {
+	return b.checker.getAvailableAPIs()
+}
+
+func (b googleBackend) ServiceState(ctx context.Context, apiName string) (string, error) {
+	return b.checker.getServiceState(ctx, apiName)
+}
+
+// pricingTable is the default PricingProvider, the built-in cost table
+type pricingTable struct {
+	checker *GoogleAPIChecker
+}
+
+func (p pricingTable) CostInfo(ctx context.Context, apiName string) (CostInfo, error) {
+	return p.checker.getCostInfo(apiName)
+}
+
+// StaticBackend is a mock backend answering from fixed data without network access, e.g. to
+// test the scan pipeline. Set it as CheckerOptions.Services, Status and Pricing
+type StaticBackend struct {
+	// Services is the list ListServices returns
+	Services []string
+	// States holds the state per service; services missing from it get DefaultState
+	States       map[string]string
+	DefaultState string
+	// Errors makes ServiceState fail for a service
+	Errors map[string]error
+	// Costs holds the cost information per service; services missing from it have no pricing
+	Costs map[string]CostInfo
+}
+
+func (b *StaticBackend) ListServices(ctx context.Context) ([]string, error) {
+	return append([]string(nil), b.Services...), nil
+}
+
+func (b *StaticBackend) ServiceState(ctx context.Context, apiName string) (string, error) {
+	if err := b.Errors[apiName]; err != nil {
+		return "", err
+	}
+	if state, ok := b.States[apiName]; ok {
+		return state, nil
+	}
+	if b.DefaultState == "" {
+		return "", fmt.Errorf("no state for %s", apiName)
+	}
+	return b.DefaultState, nil
+}
+
+func (b *StaticBackend) CostInfo(ctx context.Context, apiName string) (CostInfo, error) {
+	if costInfo, ok := b.Costs[apiName]; ok {
+		return costInfo, nil
+	}
+	return CostInfo{Currency: "USD", PricingDetails: "No pricing information available"}, nil
+}
+
+// initBackends sets the backends from the options, the default ones where none is set
+func (c *GoogleAPIChecker) initBackends() {
+	c.services, c.states, c.pricing = c.options.Services, c.options.Status, c.options.Pricing
+	if c.services == nil {
+		c.services = googleBackend{c}
+	}
+	if c.states == nil {
+		c.states = googleBackend{c}
+	}
+	if c.pricing == nil {
+		c.pricing = pricingTable{c}
+	}
+}
+
+// liveProject reports whether the scan reads a project from Service Usage, so the project can be
+// validated and its states prefetched; false when the services or states come from another backend
+func (c *GoogleAPIChecker) liveProject() bool {
+	return c.useRealAPI && c.projectID != "" && c.options.Services == nil && c.options.Status == nil
+}
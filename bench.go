@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// benchProject is the project the mock backend serves
+const benchProject = "bench-project"
+
+// BenchRun is the outcome of scanning the mock backend with one thread count
+type BenchRun struct {
+	Threads      int     `json:"threads"`
+	Checks       int     `json:"checks"`
+	Errors       int     `json:"errors"`
+	DurationMs   int64   `json:"duration_ms"`
+	ChecksPerSec float64 `json:"checks_per_sec"`
+	P50Ms        int64   `json:"p50_ms"`
+	P95Ms        int64   `json:"p95_ms"`
+	MaxMs        int64   `json:"max_ms"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// mockBackend answers the Service Usage and Discovery requests of a scan in-process after a fixed
+// latency, so the scan pipeline can be timed without credentials or network noise
+type mockBackend struct {
+	latency  time.Duration
+	services []string
+}
+
+// newMockBackend serves count services: the catalog's, then made-up ones
+func newMockBackend(count int, latency time.Duration) *mockBackend {
+	services := catalogServiceNames()
+	if len(services) > count {
+		services = services[:count]
+	}
+	for i := len(services); i < count; i++ {
+		services = append(services, fmt.Sprintf("bench%04d.googleapis.com", i))
+	}
+	return &mockBackend{latency: latency, services: services}
+}
+
+func (m *mockBackend) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case <-time.After(m.latency):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	path := req.URL.Path
+	prefix := "/v1/projects/" + benchProject + "/services"
+	switch {
+	case req.URL.Host == "www.googleapis.com" && strings.HasPrefix(path, "/discovery/"):
+		return m.respond(req, http.StatusOK, map[string]interface{}{"items": []interface{}{}})
+	case path == prefix:
+		return m.list(req)
+	case path == prefix+":batchGet":
+		// Without batch lookups every API is checked by a worker, which is what --threads tunes
+		return m.respond(req, http.StatusNotImplemented, map[string]interface{}{"error": map[string]string{"message": "not served by the mock backend"}})
+	case strings.HasPrefix(path, prefix+"/"):
+		name := strings.TrimPrefix(path, prefix+"/")
+		return m.respond(req, http.StatusOK, map[string]string{"name": "projects/" + benchProject + "/services/" + name, "state": m.state(name)})
+	}
+	return m.respond(req, http.StatusNotFound, map[string]interface{}{"error": map[string]string{"message": "not served by the mock backend"}})
+}
+
+// list pages through the services like Service Usage does, leaving out their states
+func (m *mockBackend) list(req *http.Request) (*http.Response, error) {
+	pageSize, _ := strconv.Atoi(req.URL.Query().Get("pageSize"))
+	if pageSize <= 0 {
+		pageSize = maxServicePageSize
+	}
+	start, _ := strconv.Atoi(req.URL.Query().Get("pageToken"))
+	end := min(start+pageSize, len(m.services))
+
+	page := map[string]interface{}{}
+	var services []map[string]string
+	for _, name := range m.services[min(start, end):end] {
+		services = append(services, map[string]string{"name": "projects/" + benchProject + "/services/" + name})
+	}
+	page["services"] = services
+	if end < len(m.services) {
+		page["nextPageToken"] = strconv.Itoa(end)
+	}
+	return m.respond(req, http.StatusOK, page)
+}
+
+// state enables about a third of the services, the same ones on every run
+func (m *mockBackend) state(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	if h.Sum32()%3 == 0 {
+		return "ENABLED"
+	}
+	return "DISABLED"
+}
+
+func (m *mockBackend) respond(req *http.Request, code int, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// runBench scans the mock backend once with the given number of threads
+func runBench(backend *mockBackend, threads int, options CheckerOptions) BenchRun {
+	checker := NewGoogleAPIChecker("bench-token", benchProject, threads, options)
+	checker.client.Transport = backend
+
+	start := time.Now()
+	results, err := checker.CheckAllAPIs()
+	elapsed := time.Since(start)
+
+	run := BenchRun{Threads: threads, Checks: len(results), DurationMs: elapsed.Milliseconds()}
+	if err != nil {
+		run.Error = err.Error()
+		return run
+	}
+	run.Errors = countStatus(results, statusError)
+	if elapsed > 0 {
+		run.ChecksPerSec = float64(len(results)) / elapsed.Seconds()
+	}
+	if perf := computePerformance(results); perf != nil {
+		run.P50Ms, run.P95Ms, run.MaxMs = perf.P50Ms, perf.P95Ms, perf.MaxMs
+	}
+	return run
+}
+
+// suggestedThreads is the fewest threads reaching 90% of the best throughput, since more
+// threads past that point mostly add load on the API quota
+func suggestedThreads(runs []BenchRun) int {
+	best := 0.0
+	for _, run := range runs {
+		best = max(best, run.ChecksPerSec)
+	}
+	suggested := 0
+	for _, run := range runs {
+		if run.Error == "" && run.ChecksPerSec >= 0.9*best && (suggested == 0 || run.Threads < suggested) {
+			suggested = run.Threads
+		}
+	}
+	return suggested
+}
+
+// newBenchCmd creates the subcommand that times the scan pipeline against the mock backend
+func newBenchCmd() *cobra.Command {
+	var (
+		threadCounts []int
+		apis         int
+		latency      time.Duration
+		jsonOutput   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Time the scan pipeline against a mock backend with varying thread counts",
+		Long: `Run the scan pipeline against an in-process mock of Service Usage once per
+thread count and print the throughput and per-check latency of each run. It
+helps picking --threads (the mock's --latency should be close to what -v shows
+for real scans) and spotting performance regressions between releases, e.g. by
+comparing the --json output of two versions. No credentials or network access
+are needed; pricing lookups are skipped and --qps applies as in a real scan.`,
+		Example: "  googleapichecker bench\n  googleapichecker bench --thread-counts 10,20,40 --apis 1000 --latency 250ms --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apis < 1 {
+				return fmt.Errorf("--apis must be at least 1")
+			}
+			for _, n := range threadCounts {
+				if n < 1 {
+					return fmt.Errorf("--thread-counts must be at least 1, got %d", n)
+				}
+			}
+
+			backend := newMockBackend(apis, latency)
+			if !jsonOutput {
+				fmt.Printf("⏱️  Benchmarking Google API Checker %s: %d APIs, %s mock latency\n", toolVersion(), apis, latency)
+			}
+
+			var runs []BenchRun
+			for _, n := range threadCounts {
+				options := CheckerOptions{SkipCost: true, NoProgress: true, Observer: NopObserver{}, RateLimiter: NewRateLimiter(qps)}
+				run := runBench(backend, n, options)
+				if run.Error != "" {
+					return fmt.Errorf("benchmark with %d threads failed: %s", n, run.Error)
+				}
+				runs = append(runs, run)
+				if !jsonOutput {
+					fmt.Printf("   %d threads: %.1f checks/s\n", n, run.ChecksPerSec)
+				}
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(map[string]interface{}{
+					"version":    toolVersion(),
+					"apis":       apis,
+					"latency_ms": latency.Milliseconds(),
+					"runs":       runs,
+				})
+			}
+
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(w, "THREADS\tCHECKS\tERRORS\tDURATION\tCHECKS/S\tP50\tP95\tMAX\t")
+			for _, run := range runs {
+				fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%.1f\t%dms\t%dms\t%dms\t\n", run.Threads, run.Checks, run.Errors,
+					(time.Duration(run.DurationMs) * time.Millisecond).String(), run.ChecksPerSec, run.P50Ms, run.P95Ms, run.MaxMs)
+			}
+			w.Flush()
+
+			if suggested := suggestedThreads(runs); suggested > 0 {
+				fmt.Printf("\n💡 %d threads reach 90%% of the best throughput; more mostly adds load on the Service Usage quota\n", suggested)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntSliceVar(&threadCounts, "thread-counts", []int{1, 5, 10, 25, 50}, "Thread counts to benchmark, in order")
+	cmd.Flags().IntVar(&apis, "apis", 200, "Number of APIs the mock project has")
+	cmd.Flags().DurationVar(&latency, "latency", 100*time.Millisecond, "Latency of every mock response")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the runs as JSON, e.g. to compare releases")
+	return cmd
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+)
+
+// billingExportTablePrefix is the prefix of the tables Cloud Billing exports to BigQuery
+const billingExportTablePrefix = "gcp_billing_export_"
+
+// BillingExportConfig points at the BigQuery dataset holding the billing export
+type BillingExportConfig struct {
+	Project string `yaml:"project"`
+	Dataset string `yaml:"dataset"`
+}
+
+// checkBilling records findings for a missing or closed billing account and a missing billing export
+func (c *GoogleAPIChecker) checkBilling() error {
+	if c.projectID == "" {
+		return fmt.Errorf("billing checks require a project ID")
+	}
+
+	info, err := c.getBillingInfo()
+	if err != nil {
+		return err
+	}
+
+	if !info.BillingEnabled || info.BillingAccountName == "" {
+		c.addFinding("high", "billing", "No active billing account is linked; paid APIs will fail and cost estimates cannot be checked against real spend")
+	} else {
+		open, err := c.isBillingAccountOpen(info.BillingAccountName)
+		if err != nil {
+			fmt.Printf("⚠️  Could not read billing account %s: %s\n", info.BillingAccountName, redactSecrets(err.Error()))
+		} else if !open {
+			c.addFinding("high", "billing", fmt.Sprintf("Linked billing account %s is closed", info.BillingAccountName))
+		}
+	}
+
+	table, err := c.findBillingExport()
+	if err != nil {
+		return err
+	}
+	if table == "" {
+		c.addFinding("medium", "billing", "No billing export to BigQuery found; enable it so cost recommendations can be based on actual spend")
+	} else {
+		fmt.Printf("💳 Billing export found: %s\n", table)
+	}
+
+	return nil
+}
+
+// getBillingInfo reads the project's billing account link
+func (c *GoogleAPIChecker) getBillingInfo() (*cloudbilling.ProjectBillingInfo, error) {
+	billing, err := c.cloudBilling()
+	if err != nil {
+		return nil, err
+	}
+	info, err := billing.GetBillingInfo(context.Background(), "projects/"+c.projectID)
+	if err != nil {
+		return nil, clientError("billing info", err)
+	}
+	return info, nil
+}
+
+// getBillingAccount returns the project's billing account name, empty when billing is disabled
+func (c *GoogleAPIChecker) getBillingAccount() (string, error) {
+	info, err := c.getBillingInfo()
+	if err != nil {
+		return "", err
+	}
+	if !info.BillingEnabled {
+		return "", nil
+	}
+	return info.BillingAccountName, nil
+}
+
+// isBillingAccountOpen reports whether the billing account can be charged
+func (c *GoogleAPIChecker) isBillingAccountOpen(name string) (bool, error) {
+	billing, err := c.cloudBilling()
+	if err != nil {
+		return false, err
+	}
+	account, err := billing.GetBillingAccount(context.Background(), name)
+	if err != nil {
+		return false, clientError("billing account", err)
+	}
+	return account.Open, nil
+}
+
+// listBillingAccountProjects returns the IDs of the projects linked to a billing account, given as
+// an ID such as 012345-6789AB-CDEF01 or as billingAccounts/ID
+func (c *GoogleAPIChecker) listBillingAccountProjects(account string) ([]string, error) {
+	name := account
+	if !strings.HasPrefix(name, "billingAccounts/") {
+		name = "billingAccounts/" + name
+	}
+
+	billing, err := c.cloudBilling()
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []string
+	pageToken := ""
+	for {
+		result, err := billing.ListAccountProjects(context.Background(), name, pageToken)
+		if err != nil {
+			return nil, clientError("billing account projects", err)
+		}
+		for _, info := range result.ProjectBillingInfo {
+			if info.BillingEnabled {
+				projects = append(projects, info.ProjectId)
+			}
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+	return projects, nil
+}
+
+// billingAccountProjects adds the projects linked to the billing accounts to projects, skipping duplicates
+func billingAccountProjects(token string, accounts, projects []string, options CheckerOptions) ([]string, error) {
+	checker := NewGoogleAPIChecker(token, "", 1, options)
+	seen := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		seen[project] = true
+	}
+
+	for _, account := range accounts {
+		linked, err := checker.listBillingAccountProjects(account)
+		if err != nil {
+			return nil, fmt.Errorf("billing account %s: %v", account, err)
+		}
+		fmt.Printf("💳 Billing account %s has %d projects with billing enabled\n", account, len(linked))
+		for _, project := range linked {
+			if !seen[project] {
+				seen[project] = true
+				projects = append(projects, project)
+			}
+		}
+	}
+	return projects, nil
+}
+
+// findBillingExport returns the first billing export table as project.dataset.table, or "" when none exists.
+// It looks in the configured dataset, or in every dataset of the scanned project.
+func (c *GoogleAPIChecker) findBillingExport() (string, error) {
+	project := c.options.BillingExport.Project
+	if project == "" {
+		project = c.projectID
+	}
+
+	datasets := []string{c.options.BillingExport.Dataset}
+	if c.options.BillingExport.Dataset == "" {
+		var err error
+		if datasets, err = c.listBigQueryIDs(fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets", project), "datasets"); err != nil {
+			return "", err
+		}
+	}
+
+	for _, dataset := range datasets {
+		tables, err := c.listBigQueryIDs(fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables", project, dataset), "tables")
+		if err != nil {
+			return "", err
+		}
+		for _, table := range tables {
+			if strings.HasPrefix(table, billingExportTablePrefix) {
+				return fmt.Sprintf("%s.%s.%s", project, dataset, table), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// listBigQueryIDs pages through a BigQuery datasets or tables list and returns the IDs
+func (c *GoogleAPIChecker) listBigQueryIDs(baseURL, kind string) ([]string, error) {
+	var ids []string
+	pageToken := ""
+	for {
+		requestURL := baseURL
+		if pageToken != "" {
+			requestURL += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var result struct {
+			Datasets []struct {
+				DatasetReference struct {
+					DatasetID string `json:"datasetId"`
+				} `json:"datasetReference"`
+			} `json:"datasets"`
+			Tables []struct {
+				TableReference struct {
+					TableID string `json:"tableId"`
+				} `json:"tableReference"`
+			} `json:"tables"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.getJSON(requestURL, "BigQuery "+kind, &result); err != nil {
+			return nil, err
+		}
+
+		for _, dataset := range result.Datasets {
+			ids = append(ids, dataset.DatasetReference.DatasetID)
+		}
+		for _, table := range result.Tables {
+			ids = append(ids, table.TableReference.TableID)
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return ids, nil
+}
+
+// getJSON sends an authenticated GET request and decodes the JSON response into v
+func (c *GoogleAPIChecker) getJSON(requestURL, what string, v interface{}) error {
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	return c.sendJSON(req, what, v)
+}
+
+// postJSON sends body as an authenticated JSON POST request and decodes the response into v
+func (c *GoogleAPIChecker) postJSON(requestURL, what string, body, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %v", what, err)
+	}
+
+	req, err := http.NewRequest("POST", requestURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	return c.sendJSON(req, what, v)
+}
+
+// sendJSON adds credentials to req, sends it and decodes the JSON response into v
+func (c *GoogleAPIChecker) sendJSON(req *http.Request, what string, v interface{}) error {
+	// Public endpoints such as the Discovery directory are also read without a credential
+	if c.token != "" {
+		req.Header.Add("X-Goog-Api-Key", c.token)
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("failed to get %s, status: %d", what, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", what, err)
+	}
+	return nil
+}
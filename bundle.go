@@ -0,0 +1,202 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// bundleMagic starts encrypted bundles; the zip is sealed with sealData
+const bundleMagic = "GACBUNDLE1\n"
+
+// bundlePath names the archive, adding the time when the filename template only has the date
+func bundlePath(artifacts ArtifactNamer) string {
+	if strings.Contains(artifacts.Template, "{time}") || strings.Contains(artifacts.Template, "{timestamp}") {
+		return artifacts.Path("bundle", "zip")
+	}
+	return artifacts.Path("bundle_"+artifacts.Time.Format("150405"), "zip")
+}
+
+// WriteBundle zips the files that exist into one archive, encrypted when a passphrase is given,
+// and returns the path written (with an .enc suffix when encrypted)
+func WriteBundle(filename string, files []string, passphrase string) (string, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	added := 0
+	for _, file := range files {
+		ok, err := addBundleFile(archive, file)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			added++
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %v", err)
+	}
+	if added == 0 {
+		return "", fmt.Errorf("no artifacts to bundle")
+	}
+
+	data := buf.Bytes()
+	if passphrase != "" {
+		sealed, err := encryptBundle(data, passphrase)
+		if err != nil {
+			return "", err
+		}
+		data = sealed
+		filename += ".enc"
+	}
+
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %v", err)
+	}
+	return filename, nil
+}
+
+// addBundleFile copies one artifact into the archive, skipping files that were not written
+func addBundleFile(archive *zip.Writer, filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return false, fmt.Errorf("failed to bundle %s: %v", filename, err)
+	}
+	header.Name = filepath.Base(filename)
+	header.Method = zip.Deflate
+
+	entry, err := archive.CreateHeader(header)
+	if err != nil {
+		return false, fmt.Errorf("failed to bundle %s: %v", filename, err)
+	}
+	if _, err := io.Copy(entry, file); err != nil {
+		return false, fmt.Errorf("failed to bundle %s: %v", filename, err)
+	}
+	return true, nil
+}
+
+// encryptBundle seals the archive with a key derived from the passphrase
+func encryptBundle(data []byte, passphrase string) ([]byte, error) {
+	salt, err := newSealSalt()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := sealCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return sealData(gcm, bundleMagic, salt, data, []byte(bundleMagic))
+}
+
+// decryptBundle opens an archive sealed by encryptBundle
+func decryptBundle(data []byte, passphrase string) ([]byte, error) {
+	if !isSealed(data, bundleMagic) {
+		return nil, fmt.Errorf("not an encrypted bundle")
+	}
+	salt, ok := sealedSalt(data, bundleMagic)
+	if !ok {
+		return nil, fmt.Errorf("encrypted bundle is truncated")
+	}
+
+	gcm, err := sealCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openSealed(gcm, bundleMagic, data, []byte(bundleMagic))
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupted bundle")
+	}
+	return plain, nil
+}
+
+// bundlePassphrase reads the passphrase from an env:, file: or secretmanager: reference
+func bundlePassphrase(from string) (string, error) {
+	if from == "" {
+		return "", nil
+	}
+	passphrase, err := resolveToken("", from)
+	if err != nil {
+		return "", fmt.Errorf("bundle passphrase: %v", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("bundle passphrase is empty")
+	}
+	registerSecret(passphrase)
+	return passphrase, nil
+}
+
+// newBundleCmd creates the subcommand that works with --bundle archives
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Work with the audit archives written by --bundle",
+	}
+	cmd.AddCommand(newBundleDecryptCmd())
+	return cmd
+}
+
+// newBundleDecryptCmd creates the subcommand that turns an encrypted bundle back into a zip file
+func newBundleDecryptCmd() *cobra.Command {
+	var (
+		passphraseFrom string
+		outputFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decrypt FILE",
+		Short: "Decrypt a bundle written with --bundle-passphrase-from",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if passphraseFrom == "" {
+				return fmt.Errorf("--passphrase-from is required")
+			}
+			passphrase, err := bundlePassphrase(passphraseFrom)
+			if err != nil {
+				return err
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read bundle: %v", err)
+			}
+			plain, err := decryptBundle(data, passphrase)
+			if err != nil {
+				return fmt.Errorf("%s: %v", args[0], err)
+			}
+
+			if outputFile == "" {
+				outputFile = strings.TrimSuffix(args[0], ".enc")
+				if outputFile == args[0] {
+					outputFile += ".zip"
+				}
+			}
+			if err := os.WriteFile(outputFile, plain, 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %v", outputFile, err)
+			}
+			fmt.Printf("✅ Bundle decrypted to %s\n", outputFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&passphraseFrom, "passphrase-from", "", "Read the passphrase from env:VAR, file:path or secretmanager:projects/P/secrets/S")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the zip file here (default: FILE without .enc)")
+	return cmd
+}
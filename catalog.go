@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// catalogPath is the catalog file in a source checkout, regenerated by update-catalog
+const catalogPath = "catalog/services.json"
+
+//go:embed catalog/services.json
+var catalogJSON []byte
+
+// CatalogService describes a Google service in the built-in catalog
+type CatalogService struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Category    string `json:"category"`
+	DocsURL     string `json:"docs_url,omitempty"`
+}
+
+// serviceCatalog is the embedded catalog, sorted by name
+var serviceCatalog = mustParseCatalog(catalogJSON)
+
+// serviceCatalogIndex finds catalog entries by service name
+var serviceCatalogIndex = indexCatalog(serviceCatalog)
+
+// mustParseCatalog parses the embedded catalog, which is checked at build time by update-catalog
+func mustParseCatalog(data []byte) []CatalogService {
+	var services []CatalogService
+	if err := json.Unmarshal(data, &services); err != nil {
+		panic(fmt.Sprintf("invalid embedded service catalog: %v", err))
+	}
+	return services
+}
+
+// indexCatalog maps the services by name
+func indexCatalog(services []CatalogService) map[string]CatalogService {
+	index := make(map[string]CatalogService, len(services))
+	for _, service := range services {
+		index[service.Name] = service
+	}
+	return index
+}
+
+// lookupCatalog returns the catalog entry for a service
+func lookupCatalog(apiName string) (CatalogService, bool) {
+	service, ok := serviceCatalogIndex[apiName]
+	return service, ok
+}
+
+// catalogServiceNames returns the names of every service in the catalog
+func catalogServiceNames() []string {
+	names := make([]string, len(serviceCatalog))
+	for i, service := range serviceCatalog {
+		names[i] = service.Name
+	}
+	return names
+}
+
+// mergeCatalog adds the Discovery directory's services to the catalog, keeping curated names and categories
+func mergeCatalog(services []CatalogService, directory map[string]DiscoveryAPI, docsLinks map[string]string) []CatalogService {
+	index := indexCatalog(services)
+	for name, api := range directory {
+		service, ok := index[name]
+		if !ok {
+			service = CatalogService{Name: name, DisplayName: api.Title, Category: prefixCategory(name)}
+		}
+		if service.DisplayName == "" {
+			service.DisplayName = name
+		}
+		if link := docsLinks[name]; link != "" {
+			service.DocsURL = link
+		}
+		index[name] = service
+	}
+
+	merged := make([]CatalogService, 0, len(index))
+	for _, service := range index {
+		merged = append(merged, service)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// fetchDiscoveryCatalog downloads the public Discovery directory, which needs no credentials
+func fetchDiscoveryCatalog() (map[string]DiscoveryAPI, map[string]string, error) {
+	resp, err := (&http.Client{Transport: apiTransport}).Get(endpoints.resolve(discoveryDirectoryURL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get Discovery directory: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, nil, fmt.Errorf("failed to get Discovery directory, status: %d", resp.StatusCode)
+	}
+
+	var directory struct {
+		Items []DiscoveryAPI `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&directory); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Discovery directory: %v", err)
+	}
+
+	docsLinks := make(map[string]string)
+	for _, item := range directory.Items {
+		if item.DocumentationLink != "" {
+			docsLinks[item.Name+".googleapis.com"] = item.DocumentationLink
+		}
+	}
+	return preferredVersions(directory.Items), docsLinks, nil
+}
+
+// newUpdateCatalogCmd creates the subcommand that regenerates the embedded service catalog
+func newUpdateCatalogCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "update-catalog",
+		Short: "Regenerate the built-in service catalog from the Discovery directory",
+		Long: `Merge the public Discovery directory into the built-in service catalog and write it
+to catalog/services.json. Existing display names and categories are kept; new services
+get the directory's title and documentation link. Rebuild to embed the new catalog.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			directory, docsLinks, err := fetchDiscoveryCatalog()
+			if err != nil {
+				return err
+			}
+			services := mergeCatalog(serviceCatalog, directory, docsLinks)
+
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(services); err != nil {
+				return fmt.Errorf("failed to encode catalog: %v", err)
+			}
+			if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write catalog: %v", err)
+			}
+
+			fmt.Printf("📚 Wrote %d services to %s (%d new)\n", len(services), out, len(services)-len(serviceCatalog))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", catalogPath, "Catalog file to write")
+	return cmd
+}
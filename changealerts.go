@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Changes since the previous scan that notify rules alert on
+const (
+	changeAPIEnabled    = "api_enabled"
+	changeAPIDisabled   = "api_disabled"
+	changeStatusChanged = "status_changed"
+	changeCostIncrease  = "cost_increase"
+)
+
+// changeKinds lists the changes in the order they are documented
+var changeKinds = []string{changeAPIEnabled, changeAPIDisabled, changeStatusChanged, changeCostIncrease}
+
+// NotifyRule alerts on one kind of change since the previous scan, so unchanged scans send nothing
+type NotifyRule struct {
+	// On is the change: api_enabled, api_disabled, status_changed or cost_increase
+	On string `yaml:"on"`
+	// Projects and APIs limit the rule to matching projects and services, e.g. *-prod or maps*; empty matches all
+	Projects []string `yaml:"projects"`
+	APIs     []string `yaml:"apis"`
+	// MinCostDelta and MinCostPercent are how much an API's monthly estimate must grow for
+	// cost_increase, in USD and percent of the previous estimate; both must be reached
+	MinCostDelta   float64 `yaml:"min_cost_delta"`
+	MinCostPercent float64 `yaml:"min_cost_percent"`
+	// Severity of the rule's alerts, default high
+	Severity string `yaml:"severity"`
+	// WebhookURL routes the rule's alerts, default notify.webhook_url
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// validate rejects notify rules with unknown changes, negative thresholds or invalid patterns;
+// field names the settings in error messages
+func (n *NotifyConfig) validate(field string) error {
+	for i, rule := range n.Rules {
+		known := false
+		for _, kind := range changeKinds {
+			known = known || rule.On == kind
+		}
+		if !known {
+			return fmt.Errorf("%s.rules[%d]: unknown change %q (use %s)", field, i, rule.On, strings.Join(changeKinds, ", "))
+		}
+		if rule.MinCostDelta < 0 || rule.MinCostPercent < 0 {
+			return fmt.Errorf("%s.rules[%d]: cost thresholds must not be negative", field, i)
+		}
+		for _, pattern := range append(append([]string(nil), rule.Projects...), rule.APIs...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s.rules[%d]: invalid pattern %q", field, i, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the change is one the rule alerts on
+func (r NotifyRule) matches(kind string, entry DiffEntry) bool {
+	if r.On != kind {
+		return false
+	}
+	if len(r.Projects) > 0 && !matchesProject(entry.ProjectID, r.Projects) {
+		return false
+	}
+	if len(r.APIs) > 0 && !matchesAny(entry.API, r.APIs) {
+		return false
+	}
+	if kind != changeCostIncrease {
+		return true
+	}
+	if entry.CostDelta <= 0 || entry.CostDelta < r.MinCostDelta {
+		return false
+	}
+	// A cost rising from nothing grows by any percentage
+	return entry.PreviousCost == 0 || entry.CostDelta/entry.PreviousCost*100 >= r.MinCostPercent
+}
+
+// matchesProject reports whether the project matches one of the patterns
+func matchesProject(project string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, project); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// changeKindsOf returns the changes a diff entry stands for; an API enabled since with a cost is
+// both enabled and more expensive
+func changeKindsOf(entry DiffEntry) []string {
+	var kinds []string
+	switch entry.Kind {
+	case diffAdded:
+		kinds = append(kinds, changeAPIEnabled)
+	case diffRemoved:
+		kinds = append(kinds, changeAPIDisabled)
+	case diffChanged:
+		if entry.PreviousStatus != entry.CurrentStatus {
+			kinds = append(kinds, changeStatusChanged)
+		}
+	}
+	if entry.CostDelta > 0 {
+		kinds = append(kinds, changeCostIncrease)
+	}
+	return kinds
+}
+
+// changeAlert describes one change as an alert
+func changeAlert(kind string, entry DiffEntry, severity string) Alert {
+	name := entry.API
+	if entry.DisplayName != "" {
+		name = entry.DisplayName + " (" + entry.API + ")"
+	}
+	alert := Alert{Severity: severity, ProjectID: entry.ProjectID}
+	switch kind {
+	case changeAPIEnabled:
+		alert.Title = "API enabled"
+		alert.Message = fmt.Sprintf("%s was enabled since the last scan", name)
+	case changeAPIDisabled:
+		alert.Title = "API disabled"
+		alert.Message = fmt.Sprintf("%s is no longer enabled", name)
+	case changeStatusChanged:
+		alert.Title = "API status changed"
+		alert.Message = fmt.Sprintf("%s changed from %s to %s", name, entry.PreviousStatus, entry.CurrentStatus)
+	case changeCostIncrease:
+		alert.Title = "Cost increase"
+		alert.Message = fmt.Sprintf("%s grew from %s to %s (+%s)", name,
+			formatCost(entry.PreviousCost), formatCost(entry.CurrentCost), formatCost(entry.CostDelta))
+	}
+	if entry.ProjectID != "" {
+		alert.Message = entry.ProjectID + ": " + alert.Message
+	}
+	return alert
+}
+
+// sharedProjects keeps the results of the projects both scans cover, so projects scanned only once
+// are not reported as every API enabled or disabled
+func sharedProjects(previous, current []APIResult) ([]APIResult, []APIResult) {
+	before := make(map[string]bool)
+	for _, result := range previous {
+		before[result.ProjectID] = true
+	}
+	both := make(map[string]bool)
+	for _, result := range current {
+		if before[result.ProjectID] {
+			both[result.ProjectID] = true
+		}
+	}
+
+	keep := func(results []APIResult) []APIResult {
+		kept := make([]APIResult, 0, len(results))
+		for _, result := range results {
+			if both[result.ProjectID] {
+				kept = append(kept, result)
+			}
+		}
+		return kept
+	}
+	return keep(previous), keep(current)
+}
+
+// changeAlerts returns the alerts the notify rules raise for the changes since the previous scan,
+// grouped by webhook URL and addressed to the project's billing contacts for cost increases and
+// technical contacts otherwise. Each change is sent once per destination, by the first rule matching it.
+func changeAlerts(diff ScanDiff, notify NotifyConfig, contacts []ProjectContacts) map[string][]Alert {
+	byProject := make(map[string]ProjectContacts, len(contacts))
+	for _, project := range contacts {
+		byProject[project.ProjectID] = project
+	}
+	routed := make(map[string][]Alert)
+	for _, entries := range [][]DiffEntry{diff.Added, diff.Removed, diff.Changed} {
+		for _, entry := range entries {
+			for _, kind := range changeKindsOf(entry) {
+				sent := make(map[string]bool)
+				for _, rule := range notify.Rules {
+					destination := rule.WebhookURL
+					if destination == "" {
+						destination = notify.WebhookURL
+					}
+					if destination == "" || sent[destination] || !rule.matches(kind, entry) {
+						continue
+					}
+					sent[destination] = true
+					severity := rule.Severity
+					if severity == "" {
+						severity = "high"
+					}
+					alert := changeAlert(kind, entry, severity)
+					if project, ok := byProject[entry.ProjectID]; ok {
+						category := "TECHNICAL"
+						if kind == changeCostIncrease {
+							category = "BILLING"
+						}
+						alert.Recipients = project.Recipients(category)
+					}
+					routed[destination] = append(routed[destination], alert)
+				}
+			}
+		}
+	}
+	return routed
+}
+
+// sendAlerts posts the alerts of each destination, in a stable order
+func sendAlerts(routed map[string][]Alert) {
+	destinations := make([]string, 0, len(routed))
+	for destination := range routed {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+	for _, destination := range destinations {
+		if err := NewWebhookNotifier(destination).Notify(routed[destination]); err != nil {
+			log.Printf("Warning: notification failed: %v", err)
+		}
+	}
+}
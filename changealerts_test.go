@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// saveTestScan stores results under a fixed history name
+func saveTestScan(t *testing.T, store StateStore, name string, results []APIResult) {
+	t.Helper()
+	data, err := encodeResults(results, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(name, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangeAlertsSharedHistory(t *testing.T) {
+	store, err := OpenStateStore("", t.TempDir(), "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectA := []APIResult{
+		{ProjectID: "project-a", Name: "compute.googleapis.com", Status: "ENABLED", Enabled: true},
+	}
+	projectB := []APIResult{
+		{ProjectID: "project-b", Name: "bigquery.googleapis.com", Status: "ENABLED", Enabled: true},
+	}
+	// Another schedule scanned project B after the last scan of project A
+	saveTestScan(t, store, "results_20260101_060000.json", projectA)
+	saveTestScan(t, store, "results_20260101_070000.json", projectB)
+
+	current := []APIResult{
+		{ProjectID: "project-a", Name: "compute.googleapis.com", Status: "ENABLED", Enabled: true},
+		{ProjectID: "project-a", Name: "vision.googleapis.com", Status: "ENABLED", Enabled: true},
+	}
+	previous, err := LoadPreviousScan(store, []string{"project-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous) != 1 || previous[0].ProjectID != "project-a" {
+		t.Fatalf("previous scan = %+v, want project-a's", previous)
+	}
+
+	notify := NotifyConfig{WebhookURL: "https://hooks.example.com/all", Rules: []NotifyRule{
+		{On: changeAPIEnabled},
+		{On: changeAPIDisabled},
+	}}
+	tests := []struct {
+		name     string
+		previous []APIResult
+		want     []string
+	}{
+		{"previous scan of the same project", previous, []string{"vision.googleapis.com"}},
+		{"previous scan of another project", projectB, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, after := sharedProjects(test.previous, current)
+			diff := DiffScans(&ResultsFile{Results: before}, &ResultsFile{Results: after}, "previous", "current")
+			alerts := changeAlerts(diff, notify, nil)[notify.WebhookURL]
+			if len(alerts) != len(test.want) {
+				t.Fatalf("got %d alerts %+v, want %d", len(alerts), alerts, len(test.want))
+			}
+			for i, api := range test.want {
+				if alerts[i].Title != "API enabled" || alerts[i].ProjectID != "project-a" || !strings.Contains(alerts[i].Message, api) {
+					t.Errorf("alert %d = %+v, want %s enabled in project-a", i, alerts[i], api)
+				}
+			}
+		})
+	}
+}
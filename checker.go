@@ -3,34 +3,171 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"google.golang.org/api/googleapi"
 )
 
 // APIResult represents the result of checking a single API
 type APIResult struct {
-	Name        string    `json:"name"`
-	DisplayName string    `json:"display_name"`
-	Status      string    `json:"status"`
-	Enabled     bool      `json:"enabled"`
-	CostInfo    CostInfo  `json:"cost_info"`
-	CheckedAt   time.Time `json:"checked_at"`
-	Error       string    `json:"error,omitempty"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Status      string   `json:"status"`
+	Enabled     bool     `json:"enabled"`
+	CostInfo    CostInfo `json:"cost_info"`
+	// CostTier buckets an enabled API's estimate by its environment's thresholds, set with the report
+	CostTier  string     `json:"cost_tier,omitempty"`
+	CheckedAt time.Time  `json:"checked_at"`
+	EnabledBy string     `json:"enabled_by,omitempty"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+	RiskNote  string     `json:"risk_note,omitempty"`
+	// RiskScore is the 0-100 abuse-risk score, nil when the API was not scored
+	RiskScore   *int     `json:"risk_score,omitempty"`
+	RiskFactors []string `json:"risk_factors,omitempty"`
+	// UnrestrictedKeys are the resource names of API keys that can call the API from any application
+	UnrestrictedKeys []string `json:"unrestricted_keys,omitempty"`
+	// RequestCount90d is nil when usage metrics were not collected
+	RequestCount90d *int64 `json:"request_count_90d,omitempty"`
+	// ResourceCount is the number of resources Cloud Asset Inventory tracks for the API, nil when not counted
+	ResourceCount *int `json:"resource_count,omitempty"`
+	// Incidents are ongoing Google Cloud status dashboard incidents affecting the API
+	Incidents []Incident `json:"incidents,omitempty"`
+	// APIVersion is the preferred version in the Discovery directory, set when the directory was used
+	APIVersion string `json:"api_version,omitempty"`
+	// DocsURL is the API's documentation page from the catalog or Discovery directory
+	DocsURL string `json:"docs_url,omitempty"`
+	// Deprecated is set when the Discovery directory labels the API deprecated, also when its state is unknown
+	Deprecated bool `json:"deprecated,omitempty"`
+	// DurationMs is how long the check took, including waiting for the rate limiter
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Throttled is set when the API answered the status check with HTTP 429
+	Throttled bool `json:"throttled,omitempty"`
+	// Raw holds the Service Usage and billing export responses the result was read from, by source, with --include-raw
+	Raw map[string][]json.RawMessage `json:"raw,omitempty"`
+	// Warning is a failed check that the config file's apis section downgrades from an error
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// QuotaInfo describes a single quota limit for an API
+type QuotaInfo struct {
+	Metric      string `json:"metric"`
+	DisplayName string `json:"display_name"`
+	Limit       int64  `json:"limit"`
+	Unit        string `json:"unit"`
 }
 
 // CostInfo contains pricing and cost calculation information
 type CostInfo struct {
-	HasPricing     bool    `json:"has_pricing"`
-	UnlimitedCost  bool    `json:"unlimited_cost"`
-	EstimatedCost  float64 `json:"estimated_cost"`
-	Currency       string  `json:"currency"`
-	PricingDetails string  `json:"pricing_details"`
+	HasPricing bool `json:"has_pricing"`
+	// UnlimitedCost is set by the UnlimitedCostPolicy; pricing providers set it for APIs without a default quota cap
+	UnlimitedCost bool `json:"unlimited_cost"`
+	// UnlimitedReason explains why the policy did or did not flag a priced API
+	UnlimitedReason string  `json:"unlimited_reason,omitempty"`
+	EstimatedCost   float64 `json:"estimated_cost"`
+	Currency        string  `json:"currency"`
+	PricingDetails  string  `json:"pricing_details"`
+	// ExpectedUsage is the monthly quantity the estimate is based on, in UsageUnit
+	ExpectedUsage float64 `json:"expected_usage,omitempty"`
+	UsageUnit     string  `json:"usage_unit,omitempty"`
+	// FreeTierCovered is set when the expected usage fits in the free tier
+	FreeTierCovered bool `json:"free_tier_covered,omitempty"`
+	// ActualCost is last month's billed cost from the billing export, nil when not reconciled
+	ActualCost *float64 `json:"actual_cost,omitempty"`
+	// ActualCostByLabel splits ActualCost by resource label key and value, e.g. team -> payments
+	ActualCostByLabel map[string]map[string]float64 `json:"actual_cost_by_label,omitempty"`
+	// SKUs are the top billing SKUs behind the estimate, for APIs in the pricing table
+	SKUs []SKUCost `json:"skus,omitempty"`
+}
+
+// CheckerOptions contains optional checker behaviour
+type CheckerOptions struct {
+	SkipCost      bool         // Skip pricing lookups entirely
+	NoProgress    bool         // Disable progress output
+	ProgressLines bool         // Force line-based progress, e.g. when projects run in parallel
+	Verbosity     int          // 1 shows per-worker status, 2 also traces requests and status decisions
+	RateLimiter   *RateLimiter // Shared request budget, nil for unlimited
+	AuditLogs     bool         // Look up who enabled each API in Cloud Audit Logs
+	UsageMetrics  bool         // Look up 90-day request counts in Cloud Monitoring
+	AssetCounts   bool         // Count resources behind each API in Cloud Asset Inventory
+	RiskScoring   bool         // Score Maps Platform APIs for key abuse risk
+	Incidents     bool         // Flag APIs with ongoing status dashboard incidents
+	Contacts      bool         // Collect project owners and Essential Contacts
+	// ExpectedUsage is the monthly quantity per service used to price estimates
+	ExpectedUsage map[string]float64
+	BillingCheck  bool                // Verify the billing account link and BigQuery billing export
+	BillingExport BillingExportConfig // Where to look for the billing export, defaults to the scanned project
+	Reconcile     bool                // Compare estimates with last month's billed cost
+	// AllocationLabels are resource label keys that reconciled costs are broken down by
+	AllocationLabels []string
+	// EnvironmentLabel is the project label used to tag results with an environment
+	EnvironmentLabel string
+	// Profile limits the scan to a service group, nil scans everything
+	Profile *ScanProfile
+	// Observer receives the scan's progress; nil prints status lines and a progress bar to stdout
+	Observer ProgressObserver
+	// Collector saves partial results during the scan and skips APIs checked by an interrupted run, nil to skip
+	Collector *ResultCollector
+	// CSVStream writes each finished check to the results CSV during the scan, nil to export after it
+	CSVStream *CSVStream
+	// Context stops handing out checks when cancelled; checks already running finish. Nil never cancels
+	Context context.Context
+	// PageSize is how many services to request per Service Usage page, 0 for the maximum
+	PageSize int
+	// State limits the scan to ENABLED or DISABLED services, empty checks all of them
+	State string
+	// PublishMetrics writes each API's estimated cost and enablement to Cloud Monitoring custom metrics
+	PublishMetrics bool
+	// Keyless scans the public Discovery directory without a credential; enablement is not known
+	Keyless bool
+	// IncludeRaw keeps the Service Usage and billing export responses of each API in APIResult.Raw
+	IncludeRaw bool
+	// ServiceUsage and CloudBilling replace the Google API clients, e.g. with stubs; nil uses the real APIs
+	ServiceUsage serviceUsageAPI
+	CloudBilling cloudBillingAPI
+	// Services, Status and Pricing replace where services, their states and their costs come from,
+	// e.g. with a StaticBackend; nil uses Service Usage and the built-in cost table
+	Services ServiceLister
+	Status   StatusChecker
+	Pricing  PricingProvider
+	// APIOverrides change the timeout, retries and error handling of single services
+	APIOverrides APIOverrides
+	// PricingThreads is how many workers look up costs after the status checks, 0 for as many as status workers
+	PricingThreads int
+	// UnlimitedCost decides which priced APIs are flagged with unlimited cost potential
+	UnlimitedCost UnlimitedCostPolicy
 }
 
+// ParseServiceState converts a --state value to the Service Usage state it selects, empty for all
+func ParseServiceState(state string) (string, error) {
+	switch strings.ToLower(state) {
+	case "", "all":
+		return "", nil
+	case "enabled":
+		return statusEnabled, nil
+	case "disabled":
+		return statusDisabled, nil
+	}
+	return "", fmt.Errorf("unknown service state %q (use enabled, disabled or all)", state)
+}
+
+// Service Usage list limits
+const (
+	maxServicePageSize = 200 // Largest page Service Usage returns
+	maxServicePages    = 100 // Safety cap against a pagination loop
+	batchGetSize       = 30  // Most services services.batchGet accepts per call
+)
+
 // GoogleAPIChecker handles the checking of Google APIs
 type GoogleAPIChecker struct {
 	token      string
@@ -39,10 +176,44 @@ type GoogleAPIChecker struct {
 	client     *http.Client
 	ctx        context.Context
 	useRealAPI bool
+	options    CheckerOptions
+	observer   ProgressObserver
+	// Where services, their states and their costs come from
+	services ServiceLister
+	states   StatusChecker
+	pricing  PricingProvider
+	// serviceStates holds Service Usage states already known from listings and batch lookups.
+	// It is only written before the workers start, so they read it without locking
+	serviceStates map[string]string
+
+	// throttles counts checks throttled in a row; at quotaExhaustedThrottles quotaExhausted is set
+	// and the workers skip the checks left
+	throttles      atomic.Int32
+	quotaExhausted atomic.Bool
+
+	mu       sync.Mutex
+	findings []Finding
+	contacts *ProjectContacts
+	// raw holds the responses recorded with IncludeRaw per API and source until they are attached to results
+	raw map[string]map[string][]json.RawMessage
+
+	// The typed Google API clients, created on first use
+	clientsOnce   sync.Once
+	usageClient   serviceUsageAPI
+	billingClient cloudBillingAPI
+	clientsErr    error
+
+	// The project's budget alert, looked up once
+	budgetOnce sync.Once
+	budget     *bool
+	budgetErr  error
+
+	// calls counts the requests the scan sent, for the scan overhead section
+	calls callCounter
 }
 
 // NewGoogleAPIChecker creates a new instance of the checker
-func NewGoogleAPIChecker(token, projectID string, threads int) *GoogleAPIChecker {
+func NewGoogleAPIChecker(token, projectID string, threads int, options CheckerOptions) *GoogleAPIChecker {
 	// Always use real API if token is provided
 	useRealAPI := token != ""
 
@@ -50,9 +221,25 @@ func NewGoogleAPIChecker(token, projectID string, threads int) *GoogleAPIChecker
 		token:      token,
 		projectID:  projectID,
 		threads:    threads,
-		client:     &http.Client{Timeout: 30 * time.Second},
-		ctx:        context.Background(),
+		client:     &http.Client{Timeout: 30 * time.Second, Transport: apiTransport},
+		ctx:        options.Context,
 		useRealAPI: useRealAPI,
+		options:    options,
+		observer:   options.Observer,
+
+		serviceStates: make(map[string]string),
+	}
+	if checker.ctx == nil {
+		checker.ctx = context.Background()
+	}
+	checker.initBackends()
+	if checker.observer == nil {
+		checker.observer = newConsoleObserver(ProgressOptions{
+			Disabled: options.NoProgress,
+			LineMode: options.ProgressLines || options.Verbosity >= traceVerbosity,
+			Verbose:  options.Verbosity > 0,
+			Label:    projectID,
+		})
 	}
 
 	return checker
@@ -60,107 +247,413 @@ func NewGoogleAPIChecker(token, projectID string, threads int) *GoogleAPIChecker
 
 // CheckAllAPIs performs the main checking operation with multithreading
 func (c *GoogleAPIChecker) CheckAllAPIs() ([]APIResult, error) {
-	fmt.Println("🔍 Discovering available Google APIs...")
+	if err := c.preflight(); err != nil {
+		return nil, err
+	}
 
-	// Get list of all available APIs
-	apis, err := c.getAvailableAPIs()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get available APIs: %v", err)
+	if c.projectID != "" {
+		c.status("🔍 Discovering available Google APIs for project %s...", c.projectID)
+	} else {
+		c.status("🔍 Discovering available Google APIs...")
 	}
 
-	fmt.Printf("📋 Found %d APIs to check\n", len(apis))
+	// Get list of all available APIs, or the profile's services
+	var apis []string
+	if c.options.Profile != nil {
+		apis = c.options.Profile.Services
+		c.status("🎯 Using %s profile: %s", c.options.Profile.Name, c.options.Profile.Description)
+	} else {
+		var err error
+		apis, err = c.services.ListServices(c.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get available APIs: %v", err)
+		}
+	}
+
+	c.status("📋 Found %d APIs to check", len(apis))
+	apis = c.skipOverridden(apis)
+
+	var results []APIResult
+	if c.options.Collector != nil {
+		results, apis = c.options.Collector.Resumed(c.projectID, apis)
+		if len(results) > 0 {
+			c.status("⏯️  Resuming: %d APIs already checked, %d left", len(results), len(apis))
+		}
+	}
+	if c.options.CSVStream != nil {
+		for _, result := range results {
+			c.options.CSVStream.Add(result)
+		}
+	}
+	if c.liveProject() {
+		c.prefetchServiceStates(apis)
+		apis = c.skipOtherStates(apis)
+		// The Discovery directory says which APIs are deprecated
+		if _, err := c.discoveryDirectory(); err != nil {
+			c.projectError(fmt.Errorf("could not read the Discovery directory, deprecated APIs are not flagged: %v", err))
+		}
+	}
+	total := len(results) + len(apis)
+	results = append(results, c.CheckAPIs(apis)...)
+	// The checks finished before the cancellation are returned with the error
+	if err := c.ctx.Err(); err != nil {
+		return results, fmt.Errorf("scan cancelled after %d of %d checks: %v", len(results), total, err)
+	}
+	if c.quotaExhausted.Load() {
+		c.projectError(fmt.Errorf("Service Usage quota exhausted after %d throttled checks in a row; %d APIs were skipped and the report is partial",
+			quotaExhaustedThrottles, countStatus(results, statusSkipped)))
+	}
+
+	if c.options.Profile != nil {
+		c.options.Profile.annotateRisk(results)
+		if c.options.Profile.Inspect != nil && c.useRealAPI {
+			if err := c.options.Profile.Inspect(c); err != nil {
+				c.projectError(fmt.Errorf("%s profile inspection failed: %v", c.options.Profile.Name, err))
+			}
+		}
+	}
+
+	if c.options.EnvironmentLabel != "" && c.projectID != "" {
+		labels, err := c.getProjectLabels()
+		if err != nil {
+			c.projectError(fmt.Errorf("could not read project labels: %v", err))
+		} else if environment := labels[c.options.EnvironmentLabel]; environment != "" {
+			for i := range results {
+				results[i].Environment = environment
+			}
+		}
+	}
+
+	if c.options.AuditLogs {
+		c.status("🕵️  Correlating enabled APIs with Cloud Audit Logs...")
+		if err := c.annotateEnablement(results); err != nil {
+			c.projectError(fmt.Errorf("audit log correlation failed: %v", err))
+		}
+	}
+
+	if c.options.UsageMetrics {
+		c.status("📈 Fetching 90-day request counts from Cloud Monitoring...")
+		if err := c.annotateUsage(results); err != nil {
+			c.projectError(fmt.Errorf("usage metrics lookup failed: %v", err))
+		}
+	}
+
+	if c.options.AssetCounts {
+		c.status("📦 Counting resources per API in Cloud Asset Inventory...")
+		if err := c.annotateResourceCounts(results); err != nil {
+			c.projectError(fmt.Errorf("resource count lookup failed: %v", err))
+		}
+	}
+
+	if c.options.BillingCheck {
+		c.status("💳 Checking billing account and billing export...")
+		if err := c.checkBilling(); err != nil {
+			c.projectError(fmt.Errorf("billing check failed: %v", err))
+		}
+	}
+
+	if c.options.Reconcile {
+		c.status("🧮 Reconciling estimates with last month's billed cost...")
+		if err := c.annotateActualCosts(results); err != nil {
+			c.projectError(fmt.Errorf("cost reconciliation failed: %v", err))
+		}
+	}
+
+	if c.options.Contacts {
+		c.status("👤 Looking up project owners and Essential Contacts...")
+		if err := c.collectContacts(); err != nil {
+			c.projectError(err)
+		}
+	}
+
+	if c.options.Incidents {
+		c.status("🩺 Checking the Google Cloud status dashboard for active incidents...")
+		if err := c.annotateIncidents(results); err != nil {
+			c.projectError(fmt.Errorf("incident lookup failed: %v", err))
+		}
+	}
+
+	if c.options.RiskScoring {
+		c.status("🚨 Scoring Maps Platform APIs for key abuse risk...")
+		if err := c.annotateRiskScores(results); err != nil {
+			c.projectError(err)
+		}
+	}
+
+	if c.options.PublishMetrics {
+		c.status("📡 Publishing results to Cloud Monitoring custom metrics...")
+		if err := c.publishMetrics(results); err != nil {
+			c.projectError(fmt.Errorf("metric publishing failed: %v", err))
+		}
+	}
+
+	return results, nil
+}
+
+// CheckAPIs checks the given APIs concurrently using the configured number of workers
+func (c *GoogleAPIChecker) CheckAPIs(apis []string) []APIResult {
+	workers := c.threads
+	if workers < 1 {
+		workers = 1
+	}
 
-	// Create channels for work distribution and results collection
-	jobs := make(chan string, len(apis))
-	results := make(chan APIResult, len(apis))
+	// Channels hold one item per worker, so the producer waits for free workers
+	// and workers wait for the collector instead of buffering the whole scan
+	jobs := make(chan string, workers)
+	results := make(chan APIEvent, workers)
+
+	// Checked APIs go through the pricing pool on their way to the collector
+	checked := results
+	var pricingWG sync.WaitGroup
+	if !c.options.SkipCost {
+		pricers := c.pricingThreads()
+		checked = make(chan APIEvent, pricers)
+		for i := 0; i < pricers; i++ {
+			pricingWG.Add(1)
+			go c.pricingWorker(i+1, &pricingWG, checked, results)
+		}
+	}
 
 	// Start worker goroutines
 	var wg sync.WaitGroup
-	for i := 0; i < c.threads; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go c.worker(&wg, jobs, results)
+		go c.worker(i+1, &wg, jobs, checked, len(apis))
 	}
 
-	// Send jobs to workers
+	// Send jobs to workers until the scan is cancelled
 	go func() {
 		defer close(jobs)
 		for _, api := range apis {
-			jobs <- api
+			select {
+			case jobs <- api:
+			case <-c.ctx.Done():
+				return
+			}
 		}
 	}()
 
 	// Collect results
 	go func() {
 		wg.Wait()
+		if checked != results {
+			close(checked)
+			pricingWG.Wait()
+		}
 		close(results)
 	}()
 
-	// Create progress bar
-	progress := NewProgressBar(len(apis))
-
 	// Gather all results
 	var allResults []APIResult
-	for result := range results {
-		allResults = append(allResults, result)
-		progress.Update()
+	done := 0
+	for event := range results {
+		done++
+		event.Done = done
+		c.observer.OnAPIDone(event)
+		if event.Result.Error != "" {
+			c.observer.OnError(event, errors.New(event.Result.Error))
+		}
+		// Services whose state was not known before the check are dropped before they are saved
+		if !c.keepsState(event.Result.Status) {
+			continue
+		}
+		allResults = append(allResults, *event.Result)
+		if c.options.Collector != nil {
+			if err := c.options.Collector.Add(*event.Result); err != nil {
+				c.projectError(err)
+			}
+		}
+		if c.options.CSVStream != nil {
+			c.options.CSVStream.Add(*event.Result)
+		}
 	}
 
-	// Complete progress bar
-	progress.Complete()
+	if c.options.Collector != nil && len(apis) > 0 {
+		if err := c.options.Collector.Flush(); err != nil {
+			c.projectError(err)
+		}
+	}
 
-	return allResults, nil
+	return allResults
 }
 
-// worker processes API checking jobs
-func (c *GoogleAPIChecker) worker(wg *sync.WaitGroup, jobs <-chan string, results chan<- APIResult) {
+// worker processes API checking jobs, sending each result with the worker that produced it
+func (c *GoogleAPIChecker) worker(id int, wg *sync.WaitGroup, jobs <-chan string, results chan<- APIEvent, total int) {
 	defer wg.Done()
 
 	for apiName := range jobs {
-		result := c.checkSingleAPI(apiName)
-		results <- result
+		// Jobs queued before a cancellation are dropped
+		if c.ctx.Err() != nil {
+			return
+		}
+		event := APIEvent{ProjectID: c.projectID, API: apiName, Worker: id, Total: total}
+		c.observer.OnAPIStart(event)
+		start := time.Now()
+		var result APIResult
+		if c.quotaExhausted.Load() {
+			// No more requests once the quota is gone; the API is listed as skipped instead
+			result = APIResult{ProjectID: c.projectID, Name: apiName, DisplayName: c.getAPIDisplayName(apiName), Status: statusSkipped, CheckedAt: start}
+		} else {
+			// Running checks are not cancelled with c.ctx, so the context only labels trace lines
+			result = c.checkSingleAPI(withTraceWorker(context.Background(), id), apiName)
+		}
+		result.DurationMs = time.Since(start).Milliseconds()
+		event.Result = &result
+		if observer, ok := c.observer.(PricingObserver); ok && !c.options.SkipCost {
+			observer.OnAPIChecked(event)
+		}
+		results <- event
 	}
 }
 
-// checkSingleAPI checks the status and cost of a single API
-func (c *GoogleAPIChecker) checkSingleAPI(apiName string) APIResult {
+// keepsState reports whether a check with the status is in the state selected with --state.
+// Errors and undetermined states are kept, since the service may be in either state.
+func (c *GoogleAPIChecker) keepsState(status string) bool {
+	if c.options.State == "" || status == statusError || statusUndetermined(status) {
+		return true
+	}
+	return statusServing(status) == (c.options.State == statusEnabled)
+}
+
+// skipOtherStates drops the APIs whose prefetched state is not the selected one, so they are
+// neither checked nor priced. Project listings are already filtered by Service Usage; this
+// covers profiles.
+func (c *GoogleAPIChecker) skipOtherStates(apis []string) []string {
+	if c.options.State == "" {
+		return apis
+	}
+	kept := make([]string, 0, len(apis))
+	for _, api := range apis {
+		if state, ok := c.serviceStates[api]; !ok || c.keepsState(state) {
+			kept = append(kept, api)
+		}
+	}
+	if skipped := len(apis) - len(kept); skipped > 0 && c.options.Verbosity > 0 {
+		c.status("⏭️  Skipping %d APIs that are not %s", skipped, c.options.State)
+	}
+	return kept
+}
+
+// status reports a scan stage to the observer
+func (c *GoogleAPIChecker) status(format string, args ...interface{}) {
+	c.observer.OnStatus(c.projectID, fmt.Sprintf(format, args...))
+}
+
+// projectError reports a failed project-wide lookup to the observer
+func (c *GoogleAPIChecker) projectError(err error) {
+	c.observer.OnError(APIEvent{ProjectID: c.projectID}, err)
+}
+
+// checkSingleAPI checks the status of a single API; priceResult adds its cost
+func (c *GoogleAPIChecker) checkSingleAPI(ctx context.Context, apiName string) APIResult {
 	result := APIResult{
+		ProjectID: c.projectID,
 		Name:      apiName,
 		CheckedAt: time.Now(),
 	}
 
 	// Check if API is enabled
-	enabled, err := c.isAPIEnabled(apiName)
-	if err != nil {
-		result.Error = err.Error()
-		result.Status = "ERROR"
+	state, err := c.checkServiceState(ctx, apiName)
+	c.attachRaw(&result)
+	switch {
+	case errors.Is(err, errUndetermined):
+		// The API exists, but whether it is enabled is not known
+		result.Status = statusUnknown
+		c.trace(ctx, "%s: %v, status %s", apiName, err, result.Status)
+	case err != nil && c.options.APIOverrides.lookup(apiName).Warn:
+		// The config file downgrades this API's failures to warnings
+		result.Warning = redactSecrets(err.Error())
+		result.Status = statusUnknown
+		c.trace(ctx, "%s: status %s, warning: %v", apiName, result.Status, err)
+	case err != nil:
+		result.Error = redactSecrets(err.Error())
+		result.Status = statusError
+		result.Throttled = errors.Is(err, errThrottled)
+		c.trace(ctx, "%s: status %s: %v", apiName, result.Status, err)
+		if result.Throttled && c.throttles.Add(1) >= quotaExhaustedThrottles && !c.quotaExhausted.Swap(true) {
+			c.trace(ctx, "quota exhausted after %d throttled checks in a row, skipping the rest", quotaExhaustedThrottles)
+		}
 		return result
+	default:
+		result.Status = statusForState(state)
+		result.Enabled = statusServing(result.Status)
+		c.trace(ctx, "%s: state %q, status %s", apiName, state, result.Status)
 	}
 
-	result.Enabled = enabled
-	if enabled {
-		result.Status = "ENABLED"
-	} else {
-		result.Status = "DISABLED"
-	}
+	c.throttles.Store(0)
 
 	// Get API display name
 	result.DisplayName = c.getAPIDisplayName(apiName)
+	if service, ok := lookupCatalog(apiName); ok {
+		result.DocsURL = service.DocsURL
+	}
+	if api, ok := lookupDiscoveryAPI(apiName); ok {
+		result.APIVersion = api.Version
+		if result.DocsURL == "" {
+			result.DocsURL = api.DocumentationLink
+		}
+		if result.DisplayName == apiName && api.Title != "" {
+			result.DisplayName = api.Title
+		}
+		// Deprecation matters more than a known state; Enabled still tells whether it is in use
+		result.Deprecated = api.deprecated()
+		if result.Deprecated && result.Status != statusUnknown {
+			result.Status = statusDeprecated
+			c.trace(ctx, "%s: deprecated in the Discovery directory, status %s", apiName, result.Status)
+		}
+	}
 
-	// Check cost information
-	costInfo, err := c.getCostInfo(apiName)
-	if err != nil {
+	// Cost information is looked up by the pricing pool
+	if c.options.SkipCost {
 		result.CostInfo = CostInfo{
-			HasPricing: false,
+			Currency:       "USD",
+			PricingDetails: "Cost lookup skipped",
 		}
-	} else {
-		result.CostInfo = costInfo
 	}
 
 	return result
 }
 
+// doRequest sends a request, waiting for the shared rate limiter first and tracing it with -vv
+func (c *GoogleAPIChecker) doRequest(req *http.Request) (*http.Response, error) {
+	// Requests are counted under the API's own host, whatever endpoint they go to
+	host := req.URL.Hostname()
+	// The Host header follows the rewritten URL
+	endpoints.rewrite(req.URL)
+	req.Host = ""
+	if !c.tracing() {
+		c.options.RateLimiter.Wait()
+		resp, err := c.client.Do(req)
+		c.calls.record(host, resp)
+		return resp, err
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+	c.options.RateLimiter.Wait()
+	if waited := time.Since(start); waited >= time.Millisecond {
+		c.trace(ctx, "waited %dms for the rate limiter", waited.Milliseconds())
+	}
+	c.trace(ctx, "→ %s %s", req.Method, req.URL)
+
+	start = time.Now()
+	resp, err := c.client.Do(req)
+	c.calls.record(host, resp)
+	if err != nil {
+		c.trace(ctx, "✗ %s %s after %dms: %v", req.Method, req.URL.Path, time.Since(start).Milliseconds(), err)
+		return nil, err
+	}
+	c.trace(ctx, "← %d %s %s (%dms)", resp.StatusCode, req.Method, req.URL.Path, time.Since(start).Milliseconds())
+	return resp, nil
+}
+
 // getAvailableAPIs returns a list of all available Google APIs
 func (c *GoogleAPIChecker) getAvailableAPIs() ([]string, error) {
+	// Keyless scans cover what the public Discovery directory lists
+	if c.options.Keyless {
+		return c.getDiscoveryAPIs()
+	}
+
 	// If we have real API access, try to get the actual list
 	if c.useRealAPI {
 		return c.getAvailableAPIsReal()
@@ -172,229 +665,188 @@ func (c *GoogleAPIChecker) getAvailableAPIs() ([]string, error) {
 
 // getAvailableAPIsReal gets the actual list of APIs from Google Cloud
 func (c *GoogleAPIChecker) getAvailableAPIsReal() ([]string, error) {
-	var url string
-
 	if c.projectID != "" {
-		// Use Service Usage API with project ID
-		url = fmt.Sprintf("https://serviceusage.googleapis.com/v1/projects/%s/services", c.projectID)
-	} else {
-		// Use Discovery API to get all available APIs
-		url = "https://www.googleapis.com/discovery/v1/apis"
+		return c.listProjectServices()
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Add("X-Goog-Api-Key", c.token)
-	req.Header.Add("Content-Type", "application/json")
+	return c.getDiscoveryAPIs()
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get API list: %v", err)
+// listProjectServices pages through the project's services in Service Usage, up to maxServicePages pages
+func (c *GoogleAPIChecker) listProjectServices() ([]string, error) {
+	pageSize := c.options.PageSize
+	if pageSize <= 0 || pageSize > maxServicePageSize {
+		pageSize = maxServicePageSize
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get API list, status: %d", resp.StatusCode)
+	usage, err := c.serviceUsage()
+	if err != nil {
+		return nil, err
 	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse API list response: %v", err)
+	filter := ""
+	if c.options.State != "" {
+		filter = "state:" + c.options.State
 	}
 
 	var apis []string
+	pageToken := ""
+	pages := 0
+	for {
+		result, err := usage.ListServices(context.Background(), "projects/"+c.projectID, filter, int64(pageSize), pageToken)
+		if err != nil {
+			return nil, clientError("API list", err)
+		}
+		pages++
 
-	if c.projectID != "" {
-		// Parse Service Usage API response
-		if services, ok := result["services"].([]interface{}); ok {
-			for _, service := range services {
-				if serviceMap, ok := service.(map[string]interface{}); ok {
-					if name, ok := serviceMap["name"].(string); ok {
-						apis = append(apis, name)
-					}
-				}
+		for _, service := range result.Services {
+			name := serviceName(service)
+			apis = append(apis, name)
+			if service.State != "" {
+				c.serviceStates[name] = service.State
 			}
+			c.recordRawJSON(name, rawServiceUsage, service)
 		}
-	} else {
-		// Parse Discovery API response
-		if items, ok := result["items"].([]interface{}); ok {
-			for _, item := range items {
-				if itemMap, ok := item.(map[string]interface{}); ok {
-					if name, ok := itemMap["name"].(string); ok {
-						apis = append(apis, name+".googleapis.com")
-					}
-				}
-			}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		if pages >= maxServicePages {
+			c.projectError(fmt.Errorf("stopped listing services after %d pages (%d services); the list may be incomplete", pages, len(apis)))
+			break
 		}
+		pageToken = result.NextPageToken
 	}
 
+	if c.options.Verbosity > 0 {
+		c.status("📄 Fetched %d services in %d page(s) of up to %d", len(apis), pages, pageSize)
+	}
 	return apis, nil
 }
 
 // getAvailableAPIsStatic returns a static list of common Google APIs
 func (c *GoogleAPIChecker) getAvailableAPIsStatic() ([]string, error) {
-	apis := []string{
-		"compute.googleapis.com",
-		"storage.googleapis.com",
-		"bigquery.googleapis.com",
-		"pubsub.googleapis.com",
-		"cloudfunctions.googleapis.com",
-		"cloudrun.googleapis.com",
-		"container.googleapis.com",
-		"datastore.googleapis.com",
-		"firestore.googleapis.com",
-		"cloudsql.googleapis.com",
-		"cloudbuild.googleapis.com",
-		"cloudtasks.googleapis.com",
-		"cloudscheduler.googleapis.com",
-		"cloudkms.googleapis.com",
-		"cloudiot.googleapis.com",
-		"cloudtrace.googleapis.com",
-		"clouddebugger.googleapis.com",
-		"cloudprofiler.googleapis.com",
-		"cloudmonitoring.googleapis.com",
-		"cloudlogging.googleapis.com",
-		"translate.googleapis.com",
-		"vision.googleapis.com",
-		"speech.googleapis.com",
-		"language.googleapis.com",
-		"ml.googleapis.com",
-		"automl.googleapis.com",
-		"dataflow.googleapis.com",
-		"dataproc.googleapis.com",
-		"dataprep.googleapis.com",
-		"datalab.googleapis.com",
-		"datacatalog.googleapis.com",
-		"datastudio.googleapis.com",
-		"analytics.googleapis.com",
-		"analyticsadmin.googleapis.com",
-		"searchconsole.googleapis.com",
-		"webmasters.googleapis.com",
-		"indexing.googleapis.com",
-		"customsearch.googleapis.com",
-		"pagespeedonline.googleapis.com",
-		"siteverification.googleapis.com",
-		"websecurityscanner.googleapis.com",
-		"clouderrorreporting.googleapis.com",
-		"cloudresourcemanager.googleapis.com",
-		"iam.googleapis.com",
-		"serviceusage.googleapis.com",
-		"cloudbilling.googleapis.com",
-		"billingbudgets.googleapis.com",
-		"recommender.googleapis.com",
-		"recommendationengine.googleapis.com",
-		"retail.googleapis.com",
-		"documentai.googleapis.com",
-		"videointelligence.googleapis.com",
-		"gameservices.googleapis.com",
-		"playablelocations.googleapis.com",
-		"places.googleapis.com",
-		"geocoding.googleapis.com",
-		"geolocation.googleapis.com",
-		"maps.googleapis.com",
-		"directions.googleapis.com",
-		"distancematrix.googleapis.com",
-		"elevation.googleapis.com",
-		"timezone.googleapis.com",
-		"staticmap.googleapis.com",
-		"streetview.googleapis.com",
-		"roads.googleapis.com",
-		"fcm.googleapis.com",
-		"firebase.googleapis.com",
-		"firebaseappcheck.googleapis.com",
-		"firebaseauth.googleapis.com",
-		"firebasehosting.googleapis.com",
-		"firebaseml.googleapis.com",
-		"firebaserules.googleapis.com",
-		"firebasestorage.googleapis.com",
-		"identitytoolkit.googleapis.com",
-		"securetoken.googleapis.com",
-		"appengine.googleapis.com",
-		"cloudapis.googleapis.com",
-	}
-
-	return apis, nil
+	return catalogServiceNames(), nil
 }
 
-// isAPIEnabled checks if a specific API is enabled using Google Cloud Service Usage API
-func (c *GoogleAPIChecker) isAPIEnabled(apiName string) (bool, error) {
+// errUndetermined marks APIs whose enabled state cannot be known, e.g. without a project
+var errUndetermined = errors.New("enabled state cannot be determined without a project")
+
+// getServiceState returns a specific API's Service Usage state, e.g. ENABLED or DISABLING
+func (c *GoogleAPIChecker) getServiceState(ctx context.Context, apiName string) (string, error) {
+	if c.options.Keyless {
+		return c.keylessServiceState(apiName)
+	}
+
 	// If we have a real API token, use real API calls
 	if c.useRealAPI {
-		return c.checkAPIEnabledReal(apiName)
+		return c.getServiceStateReal(ctx, apiName)
 	}
 
 	// Fallback to simulation for testing
-	return c.checkAPIEnabledSimulated(apiName)
+	return c.getServiceStateSimulated(apiName)
 }
 
-// checkAPIEnabledReal checks API status using real Google Cloud Service Usage API
-func (c *GoogleAPIChecker) checkAPIEnabledReal(apiName string) (bool, error) {
-	var url string
+// getServiceStateReal checks API status using real Google Cloud Service Usage API
+func (c *GoogleAPIChecker) getServiceStateReal(ctx context.Context, apiName string) (string, error) {
+	if state, ok := c.serviceStates[apiName]; ok {
+		c.trace(ctx, "%s: state %q from the service listing", apiName, state)
+		return state, nil
+	}
 
-	if c.projectID != "" {
-		// Use Service Usage API with project ID
-		url = fmt.Sprintf("https://serviceusage.googleapis.com/v1/projects/%s/services/%s", c.projectID, apiName)
-	} else {
+	if c.projectID == "" {
 		// Use Discovery API to check if API exists
-		url = fmt.Sprintf("https://www.googleapis.com/discovery/v1/apis/%s/v1", strings.TrimSuffix(apiName, ".googleapis.com"))
+		return c.checkDiscoveryAPI(ctx, apiName)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	// Use Service Usage API with project ID
+	usage, err := c.serviceUsage()
 	if err != nil {
-		return false, fmt.Errorf("failed to create request: %v", err)
+		return "", err
 	}
-
-	// Add API key to request (Google Cloud API uses API key, not Bearer token)
-	req.Header.Add("X-Goog-Api-Key", c.token)
-	req.Header.Add("Content-Type", "application/json")
-
-	// Make the actual HTTP request
-	resp, err := c.client.Do(req)
+	service, err := usage.GetService(ctx, fmt.Sprintf("projects/%s/services/%s", c.projectID, apiName))
 	if err != nil {
-		return false, fmt.Errorf("failed to make API request: %v", err)
+		var apiErr *googleapi.Error
+		if !errors.As(err, &apiErr) {
+			return "", fmt.Errorf("failed to make API request: %v", err)
+		}
+		c.recordRaw(apiName, rawServiceUsage, []byte(apiErr.Body))
+		if apiErr.Code == http.StatusNotFound {
+			// Service not found, consider it disabled
+			c.trace(ctx, "%s: service not found, assuming %s", apiName, statusDisabled)
+			return statusDisabled, nil
+		}
+		return "", apiStatusError(apiErr.Code)
 	}
-	defer resp.Body.Close()
+	c.recordRawJSON(apiName, rawServiceUsage, service)
 
-	if c.projectID != "" {
-		// Check if API is enabled based on response
-		if resp.StatusCode == 200 {
-			// Parse response body to check if service is enabled
-			var result map[string]interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-				return false, fmt.Errorf("failed to parse response: %v", err)
-			}
+	if service.State != "" {
+		return service.State, nil
+	}
+	c.trace(ctx, "%s: no state in the response, assuming %s", apiName, statusEnabled)
+	return statusEnabled, nil // Default to enabled if state not found
+}
 
-			// Check if the service is enabled
-			if state, ok := result["state"].(string); ok {
-				return state == "ENABLED", nil
-			}
-			return true, nil // Default to enabled if state not found
-		} else if resp.StatusCode == 404 {
-			// Service not found, consider it disabled
-			return false, nil
-		} else {
-			// Other error status codes
-			return false, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+// prefetchServiceStates looks up the states the listing did not return with services.batchGet,
+// batchGetSize services per request. APIs a batch fails for fall back to one GET each
+func (c *GoogleAPIChecker) prefetchServiceStates(apis []string) {
+	var unknown []string
+	for _, api := range apis {
+		if _, ok := c.serviceStates[api]; !ok {
+			unknown = append(unknown, api)
 		}
-	} else {
-		// Without project ID, check if API is available (not necessarily enabled)
-		if resp.StatusCode == 200 {
-			// API exists and is available, but we can't determine if it's enabled without project ID
-			// For now, we'll consider it as "available" but not necessarily "enabled"
-			return false, nil // Consider as disabled since we can't verify actual enable status
-		} else if resp.StatusCode == 404 {
-			return false, nil // API not found
-		} else {
-			return false, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+	if len(unknown) == 0 {
+		return
+	}
+
+	requests, failed := 0, 0
+	for start := 0; start < len(unknown) && c.ctx.Err() == nil; start += batchGetSize {
+		batch := unknown[start:min(start+batchGetSize, len(unknown))]
+		requests++
+		states, err := c.batchGetServiceStates(batch)
+		if err != nil {
+			failed++
+			continue
+		}
+		for name, state := range states {
+			c.serviceStates[name] = state
 		}
 	}
+
+	if failed > 0 {
+		c.projectError(fmt.Errorf("%d of %d batch status lookups failed, checking those APIs one by one", failed, requests))
+	}
+	if c.options.Verbosity > 0 {
+		c.status("📦 Fetched %d service states in %d batch request(s)", len(unknown), requests)
+	}
+}
+
+// batchGetServiceStates returns the Service Usage states of up to batchGetSize services
+func (c *GoogleAPIChecker) batchGetServiceStates(apis []string) (map[string]string, error) {
+	usage, err := c.serviceUsage()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(apis))
+	for i, api := range apis {
+		names[i] = fmt.Sprintf("projects/%s/services/%s", c.projectID, api)
+	}
+
+	result, err := usage.BatchGetServices(context.Background(), "projects/"+c.projectID, names)
+	if err != nil {
+		return nil, clientError("service states", err)
+	}
+
+	states := make(map[string]string, len(result.Services))
+	for _, service := range result.Services {
+		states[serviceName(service)] = service.State
+		c.recordRawJSON(serviceName(service), rawServiceUsage, service)
+	}
+	return states, nil
 }
 
-// checkAPIEnabledSimulated provides simulated API status for testing
-func (c *GoogleAPIChecker) checkAPIEnabledSimulated(apiName string) (bool, error) {
+// getServiceStateSimulated provides simulated API status for testing
+func (c *GoogleAPIChecker) getServiceStateSimulated(apiName string) (string, error) {
 	time.Sleep(100 * time.Millisecond) // Simulate API call
 
 	// Simulate some APIs being enabled and others disabled
@@ -411,54 +863,62 @@ func (c *GoogleAPIChecker) checkAPIEnabledSimulated(apiName string) (bool, error
 		"cloudsql.googleapis.com":       true,
 	}
 
-	if enabled, exists := enabledAPIs[apiName]; exists {
-		return enabled, nil
+	if enabled, exists := enabledAPIs[apiName]; exists && !enabled {
+		return statusDisabled, nil
 	}
 
 	// Default to enabled for unknown APIs
-	return true, nil
+	return statusEnabled, nil
 }
 
 // getAPIDisplayName returns the display name for an API
 func (c *GoogleAPIChecker) getAPIDisplayName(apiName string) string {
-	displayNames := map[string]string{
-		"compute.googleapis.com":        "Compute Engine API",
-		"storage.googleapis.com":        "Cloud Storage API",
-		"bigquery.googleapis.com":       "BigQuery API",
-		"pubsub.googleapis.com":         "Cloud Pub/Sub API",
-		"cloudfunctions.googleapis.com": "Cloud Functions API",
-		"cloudrun.googleapis.com":       "Cloud Run API",
-		"container.googleapis.com":      "Kubernetes Engine API",
-		"datastore.googleapis.com":      "Cloud Datastore API",
-		"firestore.googleapis.com":      "Cloud Firestore API",
-		"cloudsql.googleapis.com":       "Cloud SQL API",
-		"cloudbuild.googleapis.com":     "Cloud Build API",
-		"cloudtasks.googleapis.com":     "Cloud Tasks API",
-		"cloudscheduler.googleapis.com": "Cloud Scheduler API",
-		"cloudkms.googleapis.com":       "Cloud KMS API",
-		"cloudiot.googleapis.com":       "Cloud IoT API",
-		"translate.googleapis.com":      "Cloud Translation API",
-		"vision.googleapis.com":         "Cloud Vision API",
-		"speech.googleapis.com":         "Cloud Speech API",
-		"language.googleapis.com":       "Natural Language API",
-		"ml.googleapis.com":             "Machine Learning API",
-		"automl.googleapis.com":         "AutoML API",
-		"dataflow.googleapis.com":       "Dataflow API",
-		"dataproc.googleapis.com":       "Dataproc API",
-		"analytics.googleapis.com":      "Google Analytics API",
-		"maps.googleapis.com":           "Maps JavaScript API",
-		"firebase.googleapis.com":       "Firebase API",
-		"appengine.googleapis.com":      "App Engine API",
-	}
-
-	if displayName, exists := displayNames[apiName]; exists {
-		return displayName
+	if service, ok := lookupCatalog(apiName); ok && service.DisplayName != "" {
+		return service.DisplayName
 	}
 
 	// Return a formatted version of the API name if no display name is found
 	return apiName
 }
 
+// apiCategories maps service name prefixes to a product category, for services missing from the catalog
+var apiCategories = []struct {
+	Category string
+	Prefixes []string
+}{
+	{"Firebase", []string{"firebase", "fcm", "identitytoolkit", "securetoken"}},
+	{"Maps & Location", []string{"maps", "places", "geocoding", "geolocation", "directions", "distancematrix", "elevation", "timezone", "staticmap", "streetview", "roads", "playablelocations"}},
+	{"AI & Machine Learning", []string{"ml", "automl", "vision", "speech", "translate", "language", "documentai", "videointelligence", "recommendationengine", "retail", "aiplatform", "dialogflow"}},
+	{"Data & Analytics", []string{"bigquery", "dataflow", "dataproc", "dataprep", "datalab", "datacatalog", "datastudio", "pubsub", "analytics", "analyticsadmin"}},
+	{"Storage & Databases", []string{"storage", "datastore", "firestore", "cloudsql", "sqladmin", "spanner", "bigtable"}},
+	{"Compute", []string{"compute", "container", "cloudfunctions", "cloudrun", "run", "appengine", "gameservices"}},
+	{"Security & Identity", []string{"iam", "cloudkms", "websecurityscanner", "secretmanager"}},
+	{"Management & Monitoring", []string{"cloudresourcemanager", "serviceusage", "cloudbilling", "billingbudgets", "recommender", "cloudtrace", "clouddebugger", "cloudprofiler", "cloudmonitoring", "cloudlogging", "clouderrorreporting", "monitoring", "logging"}},
+	{"Developer Tools", []string{"cloudbuild", "cloudtasks", "cloudscheduler", "cloudiot", "cloudapis"}},
+	{"Web & Search", []string{"searchconsole", "webmasters", "indexing", "customsearch", "pagespeedonline", "siteverification"}},
+}
+
+// getAPICategory returns the product category for an API
+func getAPICategory(apiName string) string {
+	if service, ok := lookupCatalog(apiName); ok && service.Category != "" {
+		return service.Category
+	}
+	return prefixCategory(apiName)
+}
+
+// prefixCategory guesses the category of a service missing from the catalog from its name
+func prefixCategory(apiName string) string {
+	prefix := strings.TrimSuffix(apiName, ".googleapis.com")
+	for _, category := range apiCategories {
+		for _, p := range category.Prefixes {
+			if prefix == p {
+				return category.Category
+			}
+		}
+	}
+	return "Other"
+}
+
 // getCostInfo retrieves cost information for an API
 func (c *GoogleAPIChecker) getCostInfo(apiName string) (CostInfo, error) {
 	// In a real implementation, you would query the Cloud Billing API
@@ -625,20 +1085,172 @@ func (c *GoogleAPIChecker) getCostInfo(apiName string) (CostInfo, error) {
 	}, nil
 }
 
-// SaveResults saves the results to a JSON file
-func (c *GoogleAPIChecker) SaveResults(results []APIResult, filename string) error {
-	file, err := os.Create(filename)
+// getQuotaInfo retrieves the consumer quota limits for an API
+func (c *GoogleAPIChecker) getQuotaInfo(apiName string) ([]QuotaInfo, error) {
+	if c.useRealAPI && c.projectID != "" {
+		return c.getQuotaInfoReal(apiName)
+	}
+
+	return c.getQuotaInfoSimulated(apiName)
+}
+
+// getQuotaInfoReal queries the Service Usage consumer quota metrics for an API
+func (c *GoogleAPIChecker) getQuotaInfoReal(apiName string) ([]QuotaInfo, error) {
+	url := fmt.Sprintf("https://serviceusage.googleapis.com/v1beta1/projects/%s/services/%s/consumerQuotaMetrics", c.projectID, apiName)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Add("X-Goog-Api-Key", c.token)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return nil, fmt.Errorf("failed to get quota metrics: %v", err)
 	}
-	defer file.Close()
+	defer resp.Body.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get quota metrics, status: %d", resp.StatusCode)
+	}
 
-	if err := encoder.Encode(results); err != nil {
-		return fmt.Errorf("failed to encode results: %v", err)
+	var result struct {
+		Metrics []struct {
+			Metric      string `json:"metric"`
+			DisplayName string `json:"displayName"`
+			Unit        string `json:"unit"`
+			Limits      []struct {
+				Buckets []struct {
+					EffectiveLimit string `json:"effectiveLimit"`
+				} `json:"quotaBuckets"`
+			} `json:"consumerQuotaLimits"`
+		} `json:"metrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse quota metrics response: %v", err)
 	}
 
+	var quotas []QuotaInfo
+	for _, metric := range result.Metrics {
+		for _, limit := range metric.Limits {
+			for _, bucket := range limit.Buckets {
+				value, err := strconv.ParseInt(bucket.EffectiveLimit, 10, 64)
+				if err != nil {
+					continue
+				}
+				quotas = append(quotas, QuotaInfo{
+					Metric:      metric.Metric,
+					DisplayName: metric.DisplayName,
+					Limit:       value,
+					Unit:        metric.Unit,
+				})
+			}
+		}
+	}
+
+	return quotas, nil
+}
+
+// getQuotaInfoSimulated provides simulated quota limits for testing
+func (c *GoogleAPIChecker) getQuotaInfoSimulated(apiName string) ([]QuotaInfo, error) {
+	quotaData := map[string][]QuotaInfo{
+		"compute.googleapis.com": {
+			{Metric: "compute.googleapis.com/cpus", DisplayName: "CPUs", Limit: 24, Unit: "1/{project}/{region}"},
+			{Metric: "compute.googleapis.com/read_requests", DisplayName: "Read requests", Limit: 1500, Unit: "1/min/{project}"},
+		},
+		"bigquery.googleapis.com": {
+			{Metric: "bigquery.googleapis.com/quota/query/usage", DisplayName: "Query usage per day", Limit: -1, Unit: "MiBy/d/{project}"},
+		},
+		"maps.googleapis.com": {
+			{Metric: "maps.googleapis.com/map_loads", DisplayName: "Map loads per minute", Limit: 30000, Unit: "1/min/{project}"},
+		},
+	}
+
+	return quotaData[apiName], nil
+}
+
+// ResultsFile is the on-disk format of the results JSON file
+type ResultsFile struct {
+	SchemaVersion int               `json:"schema_version"`
+	ToolVersion   string            `json:"tool_version"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Results       []APIResult       `json:"results"`
+}
+
+// SaveResults saves the results and the scan's tags to a JSON file
+func SaveResults(results []APIResult, filename string, tags map[string]string) error {
+	data, err := encodeResults(results, tags)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
 	return nil
 }
+
+// encodeResults encodes the results in the results file format
+func encodeResults(results []APIResult, tags map[string]string) ([]byte, error) {
+	resultsFile := ResultsFile{
+		SchemaVersion: OutputSchemaVersion,
+		ToolVersion:   toolVersion(),
+		GeneratedAt:   time.Now(),
+		Tags:          tags,
+		Results:       results,
+	}
+
+	data, err := json.MarshalIndent(resultsFile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %v", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ProjectInfo is the subset of the Cloud Resource Manager project we use
+type ProjectInfo struct {
+	ProjectNumber string            `json:"projectNumber"`
+	Labels        map[string]string `json:"labels"`
+}
+
+// getProjectLabels reads the project's labels from Cloud Resource Manager
+func (c *GoogleAPIChecker) getProjectLabels() (map[string]string, error) {
+	project, err := c.getProject()
+	if err != nil {
+		return nil, err
+	}
+	return project.Labels, nil
+}
+
+// getProject reads the project from Cloud Resource Manager
+func (c *GoogleAPIChecker) getProject() (*ProjectInfo, error) {
+	url := fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v1/projects/%s", c.projectID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Add("X-Goog-Api-Key", c.token)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get project, status: %d", resp.StatusCode)
+	}
+
+	var project ProjectInfo
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return nil, fmt.Errorf("failed to parse project response: %v", err)
+	}
+
+	return &project, nil
+}
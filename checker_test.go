@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingPricing counts the pricing lookups a scan makes
+type countingPricing struct {
+	PricingProvider
+	calls atomic.Int32
+}
+
+func (p *countingPricing) CostInfo(ctx context.Context, apiName string) (CostInfo, error) {
+	p.calls.Add(1)
+	return p.PricingProvider.CostInfo(ctx, apiName)
+}
+
+// testBackend answers for one API per status path
+func testBackend() *StaticBackend {
+	return &StaticBackend{
+		Services: []string{
+			"compute.googleapis.com",
+			"vision.googleapis.com",
+			"bigquery.googleapis.com",
+			"pubsub.googleapis.com",
+			"storage.googleapis.com",
+		},
+		States: map[string]string{
+			"compute.googleapis.com":  statusEnabled,
+			"vision.googleapis.com":   statusEnabled,
+			"bigquery.googleapis.com": statusDisabled,
+			"pubsub.googleapis.com":   "SOMETHING_NEW",
+		},
+		Errors: map[string]error{
+			"storage.googleapis.com": errors.New("permission denied"),
+		},
+		Costs: map[string]CostInfo{
+			"compute.googleapis.com": {HasPricing: true, EstimatedCost: 120, Currency: "USD"},
+			"vision.googleapis.com":  {HasPricing: true, EstimatedCost: 30, Currency: "USD", UnlimitedCost: true},
+		},
+	}
+}
+
+// scanStatic runs a scan of test-project against the backend and indexes the results by API
+func scanStatic(t *testing.T, backend *StaticBackend, pricing PricingProvider, threads int, skipCost bool) map[string]APIResult {
+	t.Helper()
+	options := CheckerOptions{
+		NoProgress: true,
+		SkipCost:   skipCost,
+		Services:   backend,
+		Status:     backend,
+		Pricing:    pricing,
+	}
+	results, err := NewGoogleAPIChecker("", "test-project", threads, options).CheckAllAPIs()
+	if err != nil {
+		t.Fatalf("CheckAllAPIs: %v", err)
+	}
+	if len(results) != len(backend.Services) {
+		t.Fatalf("got %d results, want %d", len(results), len(backend.Services))
+	}
+	byName := make(map[string]APIResult, len(results))
+	for _, result := range results {
+		if result.ProjectID != "test-project" {
+			t.Errorf("%s: project %q, want test-project", result.Name, result.ProjectID)
+		}
+		byName[result.Name] = result
+	}
+	return byName
+}
+
+func TestCheckAllAPIsStatuses(t *testing.T) {
+	tests := []struct {
+		api     string
+		status  string
+		enabled bool
+		error   bool
+		cost    float64
+		priced  bool
+	}{
+		{"compute.googleapis.com", statusEnabled, true, false, 120, true},
+		{"vision.googleapis.com", statusEnabled, true, false, 30, true},
+		{"bigquery.googleapis.com", statusDisabled, false, false, 0, false},
+		{"pubsub.googleapis.com", statusStateUnspecified, false, false, 0, false},
+		{"storage.googleapis.com", statusError, false, true, 0, false},
+	}
+
+	// One worker and many workers go through the same status and pricing pools
+	for _, threads := range []int{1, 8} {
+		backend := testBackend()
+		results := scanStatic(t, backend, backend, threads, false)
+		for _, test := range tests {
+			result := results[test.api]
+			if result.Status != test.status || result.Enabled != test.enabled {
+				t.Errorf("threads %d: %s: status %s enabled %v, want %s %v", threads, test.api, result.Status, result.Enabled, test.status, test.enabled)
+			}
+			if (result.Error != "") != test.error {
+				t.Errorf("threads %d: %s: error %q", threads, test.api, result.Error)
+			}
+			if result.CostInfo.HasPricing != test.priced || result.CostInfo.EstimatedCost != test.cost {
+				t.Errorf("threads %d: %s: cost %+v, want %v priced %v", threads, test.api, result.CostInfo, test.cost, test.priced)
+			}
+		}
+		if !results["vision.googleapis.com"].CostInfo.UnlimitedCost {
+			t.Errorf("threads %d: vision.googleapis.com is not flagged with unlimited cost", threads)
+		}
+	}
+}
+
+func TestCheckAllAPIsUnknownState(t *testing.T) {
+	backend := &StaticBackend{
+		Services: []string{"compute.googleapis.com"},
+		Errors:   map[string]error{"compute.googleapis.com": errUndetermined},
+	}
+	results := scanStatic(t, backend, backend, 2, false)
+	result := results["compute.googleapis.com"]
+	if result.Status != statusUnknown || result.Enabled || result.Error != "" {
+		t.Errorf("got status %s enabled %v error %q, want %s without an error", result.Status, result.Enabled, result.Error, statusUnknown)
+	}
+}
+
+func TestCheckAllAPIsSkipCost(t *testing.T) {
+	backend := testBackend()
+	pricing := &countingPricing{PricingProvider: backend}
+	results := scanStatic(t, backend, pricing, 4, true)
+
+	if calls := pricing.calls.Load(); calls != 0 {
+		t.Errorf("pricing was looked up %d times with SkipCost", calls)
+	}
+	for name, result := range results {
+		if result.CostInfo.HasPricing || result.CostInfo.EstimatedCost != 0 {
+			t.Errorf("%s: cost %+v with SkipCost", name, result.CostInfo)
+		}
+	}
+	if details := results["compute.googleapis.com"].CostInfo.PricingDetails; details != "Cost lookup skipped" {
+		t.Errorf("pricing details %q, want Cost lookup skipped", details)
+	}
+}
+
+func TestCheckAllAPIsPricingLookups(t *testing.T) {
+	backend := testBackend()
+	pricing := &countingPricing{PricingProvider: backend}
+	scanStatic(t, backend, pricing, 4, false)
+
+	// Every check but the failed one is priced, once
+	if calls, want := pricing.calls.Load(), int32(len(backend.Services)-1); calls != want {
+		t.Errorf("pricing was looked up %d times, want %d", calls, want)
+	}
+}
+
+func TestStaticScanReport(t *testing.T) {
+	backend := testBackend()
+	results := scanStatic(t, backend, backend, 4, false)
+	list := make([]APIResult, 0, len(results))
+	for _, result := range results {
+		list = append(list, result)
+	}
+
+	report := GenerateReport(list)
+	summary := report.Summary
+	if summary.TotalAPIs != 5 || summary.EnabledCount != 2 || summary.DisabledCount != 1 ||
+		summary.UnknownCount != 1 || summary.ErrorCount != 1 {
+		t.Errorf("summary %+v, want 5 APIs: 2 enabled, 1 disabled, 1 unknown, 1 error", summary)
+	}
+	if summary.TotalCost != 150 {
+		t.Errorf("total cost %v, want 150", summary.TotalCost)
+	}
+	if len(report.CostAnalysis.UnlimitedCostAPIs) != 1 || report.CostAnalysis.UnlimitedCostAPIs[0].Name != "vision.googleapis.com" {
+		t.Errorf("unlimited cost APIs %+v, want vision.googleapis.com", report.CostAnalysis.UnlimitedCostAPIs)
+	}
+	if len(report.CostAnalysis.HighCostAPIs) != 1 || report.CostAnalysis.HighCostAPIs[0].Name != "compute.googleapis.com" {
+		t.Errorf("high cost APIs %+v, want compute.googleapis.com", report.CostAnalysis.HighCostAPIs)
+	}
+}
+
+// resultNames lists the APIs of the results, sorted
+func resultNames(results []APIResult) []string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCheckAllAPIsStateFilter(t *testing.T) {
+	backend := testBackend()
+	collector := NewResultCollector(filepath.Join(t.TempDir(), "results.json.partial"), 1, nil)
+	options := CheckerOptions{NoProgress: true, State: statusDisabled, Collector: collector, Services: backend, Status: backend, Pricing: backend}
+	results, err := NewGoogleAPIChecker("", "test-project", 4, options).CheckAllAPIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabled APIs are dropped before they reach the partial results; errors and unknown states stay
+	want := "bigquery.googleapis.com pubsub.googleapis.com storage.googleapis.com"
+	if got := strings.Join(resultNames(results), " "); got != want {
+		t.Errorf("results %s, want %s", got, want)
+	}
+	if got := strings.Join(resultNames(collector.results), " "); got != want {
+		t.Errorf("partial results %s, want %s", got, want)
+	}
+}
+
+func TestSkipOtherStates(t *testing.T) {
+	apis := []string{"compute.googleapis.com", "bigquery.googleapis.com", "vision.googleapis.com", "pubsub.googleapis.com"}
+	checker := NewGoogleAPIChecker("", "test-project", 1, CheckerOptions{NoProgress: true, State: statusEnabled})
+	checker.serviceStates = map[string]string{
+		"compute.googleapis.com":  statusEnabled,
+		"bigquery.googleapis.com": statusDisabled,
+		"pubsub.googleapis.com":   statusStateUnspecified,
+	}
+
+	// APIs without a prefetched state are still checked
+	kept := checker.skipOtherStates(apis)
+	if got, want := strings.Join(kept, " "), "compute.googleapis.com vision.googleapis.com pubsub.googleapis.com"; got != want {
+		t.Errorf("kept %s, want %s", got, want)
+	}
+	if apis[1] != "bigquery.googleapis.com" {
+		t.Errorf("the API list was changed: %v", apis)
+	}
+}
+
+// cancellingStatus cancels the scan at its after-th status check
+type cancellingStatus struct {
+	StatusChecker
+	after  int32
+	calls  atomic.Int32
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStatus) ServiceState(ctx context.Context, apiName string) (string, error) {
+	if s.calls.Add(1) == s.after {
+		s.cancel()
+	}
+	return s.StatusChecker.ServiceState(ctx, apiName)
+}
+
+func TestCheckAllAPIsCancelledKeepsResults(t *testing.T) {
+	backend := &StaticBackend{DefaultState: statusEnabled}
+	for i := 0; i < 100; i++ {
+		backend.Services = append(backend.Services, fmt.Sprintf("api%d.googleapis.com", i))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	status := &cancellingStatus{StatusChecker: backend, after: 5, cancel: cancel}
+
+	options := CheckerOptions{NoProgress: true, Context: ctx, Services: backend, Status: status, Pricing: backend}
+	output, err := ScanProjects("", []string{"test-project"}, 1, 1, options)
+	if err == nil || !strings.Contains(err.Error(), "scan cancelled") {
+		t.Fatalf("got error %v, want the cancellation", err)
+	}
+	if len(output.Results) < 5 || len(output.Results) >= len(backend.Services) {
+		t.Fatalf("got %d results, want the checks finished before the cancellation", len(output.Results))
+	}
+	for _, result := range output.Results {
+		if result.Status != statusEnabled {
+			t.Errorf("%s: status %s, want %s", result.Name, result.Status, statusEnabled)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// checkModule is a group of optional checks that --checks turns on by name
+type checkModule struct {
+	Name        string
+	Description string
+	Flags       string // The individual flags the module stands for
+	enable      func(options *CheckerOptions)
+	// Permissions and Roles are the IAM access the module's lookups need, for required-permissions
+	Permissions []string
+	Roles       []string
+}
+
+// checkModules are the modules selectable with --checks. Enablement is always checked.
+var checkModules = []checkModule{
+	{"cost", "Estimated monthly cost per API from the pricing table", "(on unless --skip-cost)",
+		func(o *CheckerOptions) { o.SkipCost = false }, nil, nil},
+	{"billing", "Billing account link, billing export and billed cost per API", "--billing-check, --reconcile",
+		func(o *CheckerOptions) { o.BillingCheck, o.Reconcile = true, true },
+		[]string{"resourcemanager.projects.get", "billing.accounts.get", "bigquery.datasets.get", "bigquery.tables.list", "bigquery.tables.getData", "bigquery.jobs.create"},
+		[]string{"roles/browser", "roles/billing.viewer", "roles/bigquery.dataViewer", "roles/bigquery.jobUser"}},
+	{"security", "API key abuse risk and who enabled each API", "--risk-score, --audit-logs",
+		func(o *CheckerOptions) { o.RiskScoring, o.AuditLogs = true, true },
+		[]string{"apikeys.keys.list", "resourcemanager.projects.get", "billing.budgets.list", "logging.logEntries.list"},
+		[]string{"roles/serviceusage.apiKeysViewer", "roles/browser", "roles/billing.viewer", "roles/logging.viewer"}},
+	{"usage", "90-day request counts and resources behind each API", "--usage-metrics, --asset-inventory",
+		func(o *CheckerOptions) { o.UsageMetrics, o.AssetCounts = true, true },
+		[]string{"monitoring.timeSeries.list", "cloudasset.assets.searchAllResources"},
+		[]string{"roles/monitoring.viewer", "roles/cloudasset.viewer"}},
+	{"incidents", "Ongoing Google Cloud status dashboard incidents", "--incidents",
+		func(o *CheckerOptions) { o.Incidents = true }, nil, nil},
+	{"contacts", "Project owners and Essential Contacts", "--contacts",
+		func(o *CheckerOptions) { o.Contacts = true },
+		[]string{"resourcemanager.projects.getIamPolicy", "essentialcontacts.contacts.list"},
+		[]string{"roles/iam.securityReviewer", "roles/essentialcontacts.viewer"}},
+}
+
+// checkModuleNames lists the module names for help and error messages
+func checkModuleNames() []string {
+	names := make([]string, len(checkModules))
+	for i, module := range checkModules {
+		names[i] = module.Name
+	}
+	return names
+}
+
+// applyChecks runs only the named modules: their checks are turned on and cost lookups are skipped
+// unless cost is named. Checks turned on by their own flags stay on. No names leaves options as is.
+func applyChecks(options *CheckerOptions, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, module := range checkModules {
+			found = found || module.Name == name
+		}
+		if !found {
+			return fmt.Errorf("unknown check module %q (use %s)", name, strings.Join(checkModuleNames(), ", "))
+		}
+		selected[name] = true
+	}
+
+	options.SkipCost = true
+	for _, module := range checkModules {
+		if selected[module.Name] {
+			module.enable(options)
+		}
+	}
+	return nil
+}
+
+// newChecksCmd creates the subcommand group describing the --checks modules
+func newChecksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checks",
+		Short: "Describe the check modules selectable with --checks",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the check modules and the flags they stand for",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			modules := append([]checkModule(nil), checkModules...)
+			sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MODULE\tDESCRIPTION\tFLAGS")
+			for _, module := range modules {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", module.Name, module.Description, module.Flags)
+			}
+			w.Flush()
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// qualityIssue is a scan finding in the shape CI code-quality reports expect
+type qualityIssue struct {
+	Check    string // Kind of finding, e.g. unlimited-cost
+	Severity string // high, medium, low or info, as for Finding
+	Message  string
+	Key      string // Identifies the finding across scans, e.g. project and API
+}
+
+// Fingerprint identifies the issue across scans so CI platforms can tell new findings from old ones
+func (i qualityIssue) Fingerprint() string {
+	sum := sha256.Sum256([]byte(i.Check + "\x00" + i.Key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// qualityIssues lists the report's findings, policy violations and cost findings, most severe first
+func qualityIssues(report *Report) []qualityIssue {
+	var issues []qualityIssue
+
+	for _, violation := range report.PolicyViolations {
+		issues = append(issues, qualityIssue{
+			Check:    "policy/" + violation.Rule,
+			Severity: "high",
+			Message:  violationText(violation),
+			Key:      violation.ProjectID + "/" + violation.API + "/" + violation.Message,
+		})
+	}
+	for _, drift := range report.Drift {
+		issues = append(issues, qualityIssue{
+			Check:    "drift/" + drift.Kind,
+			Severity: "high",
+			Message:  drift.String(),
+			Key:      drift.ProjectID + "/" + drift.API,
+		})
+	}
+	for _, finding := range report.Compliance {
+		issues = append(issues, qualityIssue{
+			Check:    "cis/" + finding.Control,
+			Severity: "medium",
+			Message:  finding.String(),
+			Key:      finding.ProjectID + "/" + finding.Evidence,
+		})
+	}
+	for _, finding := range report.Findings {
+		issues = append(issues, qualityIssue{
+			Check:    "finding/" + finding.Category,
+			Severity: finding.Severity,
+			Message:  finding.String(),
+			Key:      finding.ProjectID + "/" + finding.Message,
+		})
+	}
+
+	apiIssues := []struct {
+		check    string
+		severity string
+		apis     []APIResult
+		message  func(APIResult) string
+	}{
+		{"unlimited-cost", "high", report.CostAnalysis.UnlimitedCostAPIs, func(api APIResult) string {
+			return fmt.Sprintf("%s has unlimited cost potential; set a quota or budget alert. %s", incidentAPILabel(api), api.CostInfo.PricingDetails)
+		}},
+		{"high-cost", "medium", report.CostAnalysis.HighCostAPIs, func(api APIResult) string {
+			return fmt.Sprintf("%s is estimated at $%.2f/month", incidentAPILabel(api), api.CostInfo.EstimatedCost)
+		}},
+		{"empty-api", "low", report.EmptyAPIs, func(api APIResult) string {
+			return fmt.Sprintf("%s is enabled but has no resources; consider disabling it", incidentAPILabel(api))
+		}},
+		{"unused-api", "low", report.UnusedAPIs, func(api APIResult) string {
+			return fmt.Sprintf("%s had no requests in the last 90 days; consider disabling it", incidentAPILabel(api))
+		}},
+	}
+	for _, group := range apiIssues {
+		for _, api := range group.apis {
+			issues = append(issues, qualityIssue{
+				Check:    group.check,
+				Severity: group.severity,
+				Message:  group.message(api),
+				Key:      resultKey(api.ProjectID, api.Name),
+			})
+		}
+	}
+	return issues
+}
+
+// gitLabSeverities maps finding severities to GitLab Code Quality severities
+var gitLabSeverities = map[string]string{"high": "critical", "medium": "major", "low": "minor", "info": "info"}
+
+// gitLabIssue is an entry of a GitLab Code Quality report
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+// gitLabLocation points a GitLab issue at a file; scan findings point at the config file
+type gitLabLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// exportToGitLab writes a GitLab Code Quality report (artifacts:reports:codequality)
+func exportToGitLab(report *Report, options ExportOptions) error {
+	filename := options.Artifacts.Path("codequality", "json")
+
+	issues := []gitLabIssue{}
+	for _, issue := range qualityIssues(report) {
+		entry := gitLabIssue{
+			Description: issue.Message,
+			CheckName:   "googleapichecker/" + issue.Check,
+			Fingerprint: issue.Fingerprint(),
+			Severity:    gitLabSeverities[issue.Severity],
+		}
+		entry.Location.Path = options.SourcePath
+		entry.Location.Lines.Begin = 1
+		issues = append(issues, entry)
+	}
+
+	if err := writeJSONFile(filename, issues); err != nil {
+		return err
+	}
+	fmt.Printf("✅ GitLab Code Quality report exported to: %s\n", filename)
+	return nil
+}
+
+// bitbucketSeverities maps finding severities to Bitbucket Code Insights severities
+var bitbucketSeverities = map[string]string{"high": "HIGH", "medium": "MEDIUM", "low": "LOW", "info": "LOW"}
+
+// bitbucketReport is a Bitbucket Code Insights report, PUT to the commit's reports endpoint
+type bitbucketReport struct {
+	Title      string          `json:"title"`
+	Details    string          `json:"details"`
+	Reporter   string          `json:"reporter"`
+	ReportType string          `json:"report_type"`
+	Result     string          `json:"result"`
+	Data       []bitbucketData `json:"data"`
+}
+
+// bitbucketData is a summary value shown on a Bitbucket report
+type bitbucketData struct {
+	Title string      `json:"title"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// bitbucketAnnotation is a Bitbucket Code Insights annotation, POSTed to the report's annotations endpoint
+type bitbucketAnnotation struct {
+	ExternalID     string `json:"external_id"`
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Severity       string `json:"severity"`
+	Path           string `json:"path,omitempty"`
+	Line           int    `json:"line,omitempty"`
+}
+
+// bitbucketSummaryLimit is the longest annotation summary Bitbucket accepts
+const bitbucketSummaryLimit = 450
+
+// exportToBitbucket writes a Bitbucket Code Insights report and its annotations as two files
+func exportToBitbucket(report *Report, options ExportOptions) error {
+	issues := qualityIssues(report)
+
+	result := "PASSED"
+	annotations := []bitbucketAnnotation{}
+	for _, issue := range issues {
+		if issue.Severity == "high" {
+			result = "FAILED"
+		}
+		annotations = append(annotations, bitbucketAnnotation{
+			ExternalID:     issue.Fingerprint(),
+			AnnotationType: "CODE_SMELL",
+			Summary:        truncate(issue.Message, bitbucketSummaryLimit),
+			Severity:       bitbucketSeverities[issue.Severity],
+			Path:           options.SourcePath,
+			Line:           1,
+		})
+	}
+
+	insights := bitbucketReport{
+		Title:      "Google API Checker",
+		Details:    fmt.Sprintf("%d findings across %d checked APIs", len(issues), report.Summary.TotalAPIs),
+		Reporter:   "googleapichecker " + report.ToolVersion,
+		ReportType: "BUG",
+		Result:     result,
+		Data: []bitbucketData{
+			{Title: "Enabled APIs", Type: "NUMBER", Value: report.Summary.EnabledCount},
+			{Title: "Policy violations", Type: "NUMBER", Value: len(report.PolicyViolations)},
+			{Title: "Unlimited cost APIs", Type: "NUMBER", Value: len(report.CostAnalysis.UnlimitedCostAPIs)},
+			{Title: "Estimated monthly cost ($)", Type: "NUMBER", Value: report.Summary.TotalCost},
+		},
+	}
+
+	reportFile := options.Artifacts.Path("bitbucket-report", "json")
+	if err := writeJSONFile(reportFile, insights); err != nil {
+		return err
+	}
+	annotationsFile := options.Artifacts.Path("bitbucket-annotations", "json")
+	if err := writeJSONFile(annotationsFile, annotations); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Bitbucket Code Insights report exported to: %s (annotations: %s)\n", reportFile, annotationsFile)
+	return nil
+}
+
+// writeJSONFile writes v as indented JSON
+func writeJSONFile(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", filename, err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// partialSuffix is appended to the results file name for the partial results of a running scan
+const partialSuffix = ".partial"
+
+// ResultCollector gathers results from concurrent checks and writes them to a partial results file
+// every few checks, so a crashed scan can be resumed instead of started over
+type ResultCollector struct {
+	filename string
+	every    int
+
+	mu       sync.Mutex
+	results  []APIResult
+	pending  int
+	previous map[string]APIResult
+}
+
+// NewResultCollector flushes to filename after every checks; previous are results of an
+// interrupted scan that do not need to be checked again
+func NewResultCollector(filename string, every int, previous []APIResult) *ResultCollector {
+	collector := &ResultCollector{
+		filename: filename,
+		every:    every,
+		previous: make(map[string]APIResult),
+	}
+	for _, result := range previous {
+		// Failed and skipped checks are retried
+		if result.Error == "" && result.Status != statusSkipped {
+			collector.previous[resultKey(result.ProjectID, result.Name)] = result
+			collector.results = append(collector.results, result)
+		}
+	}
+	return collector
+}
+
+// resultKey identifies a check across projects
+func resultKey(projectID, apiName string) string {
+	return projectID + "/" + apiName
+}
+
+// Resumed splits the APIs of a project into the results already collected by an earlier run and the APIs left to check
+func (c *ResultCollector) Resumed(projectID string, apis []string) ([]APIResult, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var done []APIResult
+	var remaining []string
+	for _, api := range apis {
+		if result, ok := c.previous[resultKey(projectID, api)]; ok {
+			done = append(done, result)
+		} else {
+			remaining = append(remaining, api)
+		}
+	}
+	return done, remaining
+}
+
+// Add records a finished check, flushing when enough checks have finished since the last flush
+func (c *ResultCollector) Add(result APIResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = append(c.results, result)
+	c.pending++
+	if c.every <= 0 || c.pending < c.every {
+		return nil
+	}
+	return c.flush()
+}
+
+// Flush writes the results collected so far
+func (c *ResultCollector) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flush()
+}
+
+// flush writes the partial file through a temporary file so a crash mid-write keeps the previous flush; callers must hold the lock
+func (c *ResultCollector) flush() error {
+	data, err := encodeResults(c.results, nil)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(c.filename), filepath.Base(c.filename)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write partial results: %v", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write partial results: %v", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write partial results: %v", err)
+	}
+	if err := os.Rename(temp.Name(), c.filename); err != nil {
+		return fmt.Errorf("failed to write partial results: %v", err)
+	}
+
+	c.pending = 0
+	return nil
+}
+
+// Remove deletes the partial file once the complete results are saved
+func (c *ResultCollector) Remove() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Remove(c.filename); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove partial results: %v", err)
+	}
+	return nil
+}
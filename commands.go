@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newCheckCmd creates the subcommand that checks only the named services
+func newCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "check SERVICE [SERVICE...]",
+		Short:   "Check status, quota and cost of specific services",
+		Example: "  googleapichecker check compute.googleapis.com storage.googleapis.com --token YOUR_TOKEN --project my-project",
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: requireToken,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects := projectIDs
+			if len(projects) == 0 {
+				projects = []string{""}
+			}
+
+			services := make([]string, len(args))
+			for i, arg := range args {
+				services[i] = normalizeServiceName(arg)
+			}
+
+			for _, projectID := range projects {
+				checker := NewGoogleAPIChecker(apiToken, projectID, threads, CheckerOptions{NoProgress: noProgress, Verbosity: verbosity, RateLimiter: NewRateLimiter(qps)})
+				if err := checker.preflight(); err != nil {
+					return fmt.Errorf("project %s: %v", projectID, err)
+				}
+				for _, result := range checker.CheckAPIs(services) {
+					printServiceDetail(checker, result)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// normalizeServiceName accepts both "compute" and "compute.googleapis.com"
+func normalizeServiceName(name string) string {
+	name = strings.TrimSpace(name)
+	if !strings.Contains(name, ".") {
+		return name + ".googleapis.com"
+	}
+	return name
+}
+
+// printServiceDetail prints status, quota and cost detail for a single service
+func printServiceDetail(checker *GoogleAPIChecker, result APIResult) {
+	fmt.Println()
+	title := result.Name
+	if result.DisplayName != "" && result.DisplayName != result.Name {
+		title = fmt.Sprintf("%s (%s)", result.Name, result.DisplayName)
+	}
+	if result.ProjectID != "" {
+		title = fmt.Sprintf("%s [%s]", title, result.ProjectID)
+	}
+	fmt.Printf("🔎 %s\n", title)
+	fmt.Fprintf(console, "   Status: %s\n", coloredStatus(result.Status))
+
+	if result.Error != "" {
+		fmt.Printf("   Error: %s\n", result.Error)
+		return
+	}
+
+	if result.CostInfo.HasPricing {
+		fmt.Printf("   Estimated cost: $%.2f %s%s\n", projection.amount(result.CostInfo.EstimatedCost), result.CostInfo.Currency, projection.Suffix)
+	}
+	fmt.Printf("   Pricing: %s\n", result.CostInfo.PricingDetails)
+	if result.CostInfo.UnlimitedCost {
+		fmt.Println("   ⚠️  Unlimited cost potential")
+	}
+	if result.CostInfo.UnlimitedReason != "" {
+		fmt.Printf("   Unlimited cost check: %s\n", result.CostInfo.UnlimitedReason)
+	}
+
+	quotas, err := checker.getQuotaInfo(result.Name)
+	if err != nil {
+		fmt.Printf("   Quotas: unavailable (%v)\n", err)
+		return
+	}
+	if len(quotas) == 0 {
+		fmt.Println("   Quotas: none reported")
+		return
+	}
+
+	fmt.Println("   Quotas:")
+	for _, quota := range quotas {
+		limit := fmt.Sprintf("%d", quota.Limit)
+		if quota.Limit < 0 {
+			limit = "unlimited"
+		}
+		fmt.Printf("     • %s: %s (%s)\n", quota.DisplayName, limit, quota.Unit)
+	}
+}
+
+// newListCmd creates the subcommand that lists discoverable services
+func newListCmd() *cobra.Command {
+	var filter string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all discoverable Google services",
+		Long: `List all discoverable Google services with their display name and category.
+Without a token the built-in service list is used.`,
+		Example: "  googleapichecker list --filter maps",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID := ""
+			if len(projectIDs) > 0 {
+				projectID = projectIDs[0]
+			}
+
+			checker := NewGoogleAPIChecker(apiToken, projectID, threads, CheckerOptions{})
+			apis, err := checker.services.ListServices(checker.ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list services: %v", err)
+			}
+
+			filter = strings.ToLower(filter)
+			table := newConsoleTable(
+				tableColumn{Header: "NAME", Flexible: true, MinWidth: 20},
+				tableColumn{Header: "DISPLAY NAME", Flexible: true, MinWidth: 12},
+				tableColumn{Header: "CATEGORY", Drop: 1},
+			)
+			table.Indent = ""
+
+			count := 0
+			for _, api := range apis {
+				displayName := checker.getAPIDisplayName(api)
+				category := getAPICategory(api)
+				if filter != "" &&
+					!strings.Contains(strings.ToLower(api), filter) &&
+					!strings.Contains(strings.ToLower(displayName), filter) &&
+					!strings.Contains(strings.ToLower(category), filter) {
+					continue
+				}
+				table.AddRow(api, displayName, category)
+				count++
+			}
+			table.Render(os.Stdout, consoleWidth())
+
+			fmt.Printf("\n📋 %d services listed\n", count)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filter, "filter", "f", "", "Only list services whose name, display name or category contains this text")
+	return cmd
+}
+
+// newReportCmd creates the subcommand that rebuilds the report, HTML and exports from a saved results file
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report RESULTS_FILE",
+		Short: "Regenerate the report, HTML and exports from a results file without re-scanning",
+		Long: `Read a results file written by an earlier scan (of any schema version) and
+regenerate the console report, report JSON, HTML report and exports from it,
+using the current config file's thresholds and policy. Project findings,
+contacts and cost anomalies are only available during a scan and are left out.`,
+		Example: "  googleapichecker report my-project_20250101_results.json --export both --output-dir ./reports",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutputFlags(); err != nil {
+				return err
+			}
+
+			resultsFile, err := LoadResultsFile(args[0])
+			if err != nil {
+				return err
+			}
+			results := resultsFile.Results
+			if len(results) == 0 {
+				return fmt.Errorf("%s has no results", args[0])
+			}
+
+			artifacts, err := NewArtifactNamer(outputDir, filenameTemplate, resultProjects(results), lastCheckedAt(results))
+			if err != nil {
+				return err
+			}
+			if err := artifacts.EnsureDir(); err != nil {
+				return err
+			}
+
+			report := GenerateReportWithPolicy(results, PolicyFromConfig(config))
+			report.Tags = resultsFile.Tags
+			compareWithBaseline(report, resultsFile, args[0])
+			if expectedFile != "" {
+				if expectedManifest, err = LoadExpectedManifest(expectedFile); err != nil {
+					return err
+				}
+				report.SetDrift(expectedManifest.Drift(results), expectedManifest.Path)
+			}
+			printReport(report)
+
+			reportFile, err := writeReportFiles(report, results, artifacts, []string{args[0]})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📊 Report saved to: %s\n", reportFile)
+			// Drift is a finding, not a usage mistake
+			cmd.SilenceUsage = true
+			return driftError(report)
+		},
+	}
+
+	addOutputFlags(cmd)
+	addExpectedFlags(cmd)
+	return cmd
+}
+
+// resultProjects lists the projects in the results in order of first appearance
+func resultProjects(results []APIResult) []string {
+	var projects []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if !seen[result.ProjectID] {
+			seen[result.ProjectID] = true
+			projects = append(projects, result.ProjectID)
+		}
+	}
+	return projects
+}
+
+// lastCheckedAt is when the scan behind the results finished, so regenerated files keep the scan's date
+func lastCheckedAt(results []APIResult) time.Time {
+	var last time.Time
+	for _, result := range results {
+		if result.CheckedAt.After(last) {
+			last = result.CheckedAt
+		}
+	}
+	if last.IsZero() {
+		return time.Now()
+	}
+	return last
+}
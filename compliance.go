@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cisBenchmark names the benchmark the compliance controls come from
+const cisBenchmark = "CIS Google Cloud Platform Foundation Benchmark v2.0.0"
+
+// ComplianceFinding is scan evidence that a project fails a benchmark control. Controls a scan
+// cannot see failing are not listed, so the absence of a control is not evidence that it passes.
+type ComplianceFinding struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Benchmark string `json:"benchmark"`
+	Control   string `json:"control"`
+	Title     string `json:"title"`
+	Evidence  string `json:"evidence"`
+}
+
+// String formats the finding as its control, project and evidence
+func (f ComplianceFinding) String() string {
+	if f.ProjectID == "" {
+		return fmt.Sprintf("CIS %s %s: %s", f.Control, f.Title, f.Evidence)
+	}
+	return fmt.Sprintf("CIS %s %s [%s]: %s", f.Control, f.Title, f.ProjectID, f.Evidence)
+}
+
+// cisServiceControls are the CIS controls that fail when a service is disabled
+var cisServiceControls = map[string]struct {
+	control string
+	title   string
+	reason  string
+}{
+	"logging.googleapis.com": {"2.1", "Ensure that Cloud Audit Logging is configured properly",
+		"Cloud Logging is disabled, so Data Access audit logs cannot be collected or exported"},
+	"cloudasset.googleapis.com": {"2.13", "Ensure Cloud Asset Inventory is enabled",
+		"the Cloud Asset Inventory API is disabled"},
+}
+
+// cisUnrestrictedKeyControl is the control failed by API keys usable from any application
+const (
+	cisUnrestrictedKeyControl = "1.13"
+	cisUnrestrictedKeyTitle   = "Ensure API keys are restricted to use by only specified hosts and apps"
+)
+
+// complianceFindings maps disabled logging and asset APIs and unrestricted API keys to CIS controls
+func complianceFindings(results []APIResult) []ComplianceFinding {
+	var findings []ComplianceFinding
+	keyTargets := make(map[string][]string)
+	keyProjects := make(map[string]string)
+
+	for _, result := range results {
+		if control, ok := cisServiceControls[result.Name]; ok && result.Status == statusDisabled {
+			findings = append(findings, ComplianceFinding{
+				ProjectID: result.ProjectID,
+				Benchmark: cisBenchmark,
+				Control:   control.control,
+				Title:     control.title,
+				Evidence:  control.reason,
+			})
+		}
+		for _, key := range result.UnrestrictedKeys {
+			keyTargets[key] = append(keyTargets[key], result.Name)
+			keyProjects[key] = result.ProjectID
+		}
+	}
+
+	for key, services := range keyTargets {
+		findings = append(findings, ComplianceFinding{
+			ProjectID: keyProjects[key],
+			Benchmark: cisBenchmark,
+			Control:   cisUnrestrictedKeyControl,
+			Title:     cisUnrestrictedKeyTitle,
+			Evidence:  fmt.Sprintf("API key %s has no application restrictions and can call %s", key, strings.Join(services, ", ")),
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Control != findings[j].Control {
+			return findings[i].Control < findings[j].Control
+		}
+		if findings[i].ProjectID != findings[j].ProjectID {
+			return findings[i].ProjectID < findings[j].ProjectID
+		}
+		return findings[i].Evidence < findings[j].Evidence
+	})
+	return findings
+}
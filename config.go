@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is loaded from the working directory when --config is not given
+const defaultConfigFile = "googleapichecker.yaml"
+
+// Config is the optional YAML configuration file
+type Config struct {
+	// EnvironmentLabel is the project label that names the environment, e.g. env=prod
+	EnvironmentLabel string `yaml:"environment_label"`
+	// Thresholds apply to projects without a matching environment entry
+	Thresholds Thresholds `yaml:"thresholds"`
+	// Environments override thresholds and add policy rules per environment label value
+	Environments map[string]EnvironmentPolicy `yaml:"environments"`
+	// BillingExport locates the BigQuery billing export when it lives outside the scanned project
+	BillingExport BillingExportConfig `yaml:"billing_export"`
+	// CostAllocationLabels are resource label keys, e.g. team, that billed costs are broken down by
+	CostAllocationLabels []string `yaml:"cost_allocation_labels"`
+	// Notify configures where alerts are sent
+	Notify NotifyConfig `yaml:"notify"`
+	// ServiceNow configures the records --servicenow creates for policy violations
+	ServiceNow ServiceNowConfig `yaml:"servicenow"`
+	// Schedules are the cron-style scans run with --daemon
+	Schedules []ScheduleEntry `yaml:"schedules"`
+	// Tenants are the teams sharing one serve instance, each with its own client token and credentials
+	Tenants []TenantConfig `yaml:"tenants"`
+	// Access grants roles on the scan API of a serve instance without tenants
+	Access []AccessConfig `yaml:"access"`
+	// OIDCAudience lets clients sign in to the scan API with Google OIDC ID tokens issued for it
+	OIDCAudience string `yaml:"oidc_audience"`
+	// Contexts are named sets of credentials, projects and thresholds selected with --context
+	Contexts map[string]ContextConfig `yaml:"contexts"`
+	// Recommendations adds rules that turn conditions on APIs or report totals into recommendations
+	Recommendations RecommendationsConfig `yaml:"recommendations"`
+	// Endpoints sends requests to another universe domain or to regional or private endpoints
+	Endpoints EndpointConfig `yaml:"endpoints"`
+	// Network forces an IP family or fixed addresses for connections to Google APIs
+	Network NetworkConfig `yaml:"network"`
+	// APIs change the timeout, retries and error handling of single services
+	APIs APIOverrides `yaml:"apis"`
+	// UnlimitedCost configures which APIs are flagged with unlimited cost potential
+	UnlimitedCost UnlimitedCostPolicy `yaml:"unlimited_cost"`
+
+	// recommendationRules are the compiled Recommendations, nil until the file is loaded
+	recommendationRules []*compiledRule
+}
+
+// TenantConfig is a team using the scan API. Tokens are read from env:VAR, file:path or
+// secretmanager:projects/P/secrets/S so that no secret is stored in the config file.
+type TenantConfig struct {
+	Name string `yaml:"name"`
+	// AuthTokenFrom is where the bearer token the tenant's clients send is read from
+	AuthTokenFrom string `yaml:"auth_token_from"`
+	// TokenFrom is where the Google API token for the tenant's scans is read from, defaults to the server's token
+	TokenFrom string `yaml:"token_from"`
+	// Projects are the projects the tenant may scan, empty allows any
+	Projects []string `yaml:"projects"`
+	// HistoryDir or StateBackend (gs://bucket/prefix) keep the tenant's scan results; neither keeps them in memory only
+	HistoryDir   string `yaml:"history_dir"`
+	StateBackend string `yaml:"state_backend"`
+	// MaxScans caps the tenant's queued and running scans, so one team cannot fill the queue; 0 is no cap
+	MaxScans int `yaml:"max_scans"`
+	// Access grants roles to further client tokens and Google identities; the auth_token_from token is an admin
+	Access []AccessConfig `yaml:"access"`
+}
+
+// ContextConfig is a named working context, e.g. one customer, so that switching between them
+// changes the token, projects, thresholds and history together. Flags still override each value.
+type ContextConfig struct {
+	// TokenFrom is where the context's Google API token is read from, as for --token-from
+	TokenFrom  string     `yaml:"token_from"`
+	Projects   []string   `yaml:"projects"`
+	Thresholds Thresholds `yaml:"thresholds"`
+	// HistoryDir keeps the context's scan history apart from other contexts
+	HistoryDir string `yaml:"history_dir"`
+}
+
+// ScheduleEntry is a scan run by the daemon on a cron schedule. It is written either as a
+// line such as "0 6 * * 1 scan prod-project" or as a mapping with its own notification routing.
+type ScheduleEntry struct {
+	Cron     string   `yaml:"cron"`
+	Projects []string `yaml:"projects"`
+	// Notify overrides the top-level notify settings for this entry's alerts
+	Notify *NotifyConfig `yaml:"notify"`
+}
+
+// UnmarshalYAML accepts both the one-line and the mapping form of a schedule entry
+func (e *ScheduleEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		type plain ScheduleEntry
+		return node.Decode((*plain)(e))
+	}
+
+	fields := strings.Fields(node.Value)
+	cronFields := 5
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		cronFields = 1
+	}
+	if len(fields) < cronFields+2 || fields[cronFields] != "scan" {
+		return fmt.Errorf("line %d: schedule %q must look like \"0 6 * * 1 scan PROJECT[,PROJECT...]\"", node.Line, node.Value)
+	}
+
+	e.Cron = strings.Join(fields[:cronFields], " ")
+	for _, field := range fields[cronFields+1:] {
+		for _, project := range strings.Split(field, ",") {
+			if project != "" {
+				e.Projects = append(e.Projects, project)
+			}
+		}
+	}
+	return nil
+}
+
+// NotifyConfig holds notifier destinations
+type NotifyConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// Rules send alerts only for changes since the previous scan, each to its own webhook
+	Rules []NotifyRule `yaml:"rules"`
+}
+
+// LoadConfig reads the configuration file. An empty path loads defaultConfigFile
+// when it exists and otherwise returns an empty configuration.
+func LoadConfig(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if err := config.APIs.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if err := config.ServiceNow.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if err := config.Notify.validate("notify"); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	for i, entry := range config.Schedules {
+		if entry.Notify == nil {
+			continue
+		}
+		if err := entry.Notify.validate(fmt.Sprintf("schedules[%d].notify", i)); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+		}
+	}
+
+	rules, err := compileRecommendationRules(config.Recommendations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	config.recommendationRules = rules
+
+	return &config, nil
+}
+
+// contextNames lists the contexts defined in the config file, sorted
+func (c *Config) contextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,82 @@
+package main
+
+// Cost tiers recorded in APIResult.CostTier, cheapest first
+const (
+	costTierFree     = "FREE"
+	costTierLow      = "LOW"
+	costTierMedium   = "MEDIUM"
+	costTierHigh     = "HIGH"
+	costTierCritical = "CRITICAL"
+)
+
+// costTierOrder lists the tiers from the most expensive down, the order reports show them in
+var costTierOrder = []string{costTierCritical, costTierHigh, costTierMedium, costTierLow, costTierFree}
+
+// Tier thresholds derived from high_cost when the config file leaves them unset
+const (
+	defaultMediumCostShare    = 0.2 // medium_cost is a fifth of high_cost
+	defaultCriticalCostFactor = 4   // critical_cost is four times high_cost
+)
+
+// costTierStyle is how a tier is colored in the console and PDF; the HTML report has matching classes
+type costTierStyle struct {
+	ANSI string
+	RGB  [3]int
+}
+
+// costTierStyles maps each tier to its colors
+var costTierStyles = map[string]costTierStyle{
+	costTierFree:     {"\033[32m", [3]int{21, 128, 61}},
+	costTierLow:      {"\033[36m", [3]int{14, 116, 144}},
+	costTierMedium:   {"\033[33m", [3]int{161, 98, 7}},
+	costTierHigh:     {"\033[35m", [3]int{190, 24, 93}},
+	costTierCritical: {"\033[31m", [3]int{185, 28, 28}},
+}
+
+// tierThresholds returns the medium and critical thresholds, derived from high_cost when unset
+func (t Thresholds) tierThresholds() (medium, critical float64) {
+	medium, critical = t.MediumCost, t.CriticalCost
+	if medium == 0 {
+		medium = t.HighCost * defaultMediumCostShare
+	}
+	if critical == 0 {
+		critical = t.HighCost * defaultCriticalCostFactor
+	}
+	return medium, critical
+}
+
+// costTier buckets an enabled API's monthly estimate by the thresholds; failed checks and APIs
+// that are not serving have no tier. Unlimited cost potential is always critical.
+func costTier(result APIResult, t Thresholds) string {
+	if result.Error != "" || !result.Enabled {
+		return ""
+	}
+	medium, critical := t.tierThresholds()
+	cost := result.CostInfo.EstimatedCost
+	switch {
+	case result.CostInfo.UnlimitedCost || cost > critical:
+		return costTierCritical
+	case cost > t.HighCost:
+		return costTierHigh
+	case cost > medium:
+		return costTierMedium
+	case result.CostInfo.HasPricing && cost > 0:
+		return costTierLow
+	}
+	return costTierFree
+}
+
+// assignCostTiers sets the cost tier of every result from its environment's thresholds
+func (p *Policy) assignCostTiers(results []APIResult) {
+	for i := range results {
+		results[i].CostTier = costTier(results[i], p.ThresholdsFor(results[i].Environment))
+	}
+}
+
+// coloredCostTier returns the text in the tier's console color
+func coloredCostTier(tier, text string) string {
+	if style, ok := costTierStyles[tier]; ok {
+		return style.ANSI + text + "\033[0m"
+	}
+	return text
+}
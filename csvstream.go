@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// csvStreamBuffer is how many finished checks may wait for the CSV writer before workers block
+const csvStreamBuffer = 256
+
+// CSVStream writes the results CSV row by row while the scan runs, so very large scans
+// do not hold every row until the export. Rows are written in the order checks finish.
+type CSVStream struct {
+	filename string
+	columns  []csvColumn
+	file     *os.File
+	writer   *csv.Writer
+
+	rows chan APIResult
+	done chan struct{}
+	once sync.Once
+
+	// Set by the writer goroutine, read after done is closed
+	count int
+	err   error
+}
+
+// NewCSVStream creates the CSV file, writes its header and starts the goroutine writing rows
+func NewCSVStream(filename string, columns []csvColumn, delimiter rune) (*CSVStream, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CSV file: %v", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Header
+	}
+	if err := writer.Write(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write CSV header: %v", err)
+	}
+
+	stream := &CSVStream{
+		filename: filename,
+		columns:  columns,
+		file:     file,
+		writer:   writer,
+		rows:     make(chan APIResult, csvStreamBuffer),
+		done:     make(chan struct{}),
+	}
+	go stream.run()
+	return stream, nil
+}
+
+// run writes rows until the stream is closed; after the first error the remaining rows are drained
+func (s *CSVStream) run() {
+	defer close(s.done)
+	for result := range s.rows {
+		if s.err != nil {
+			continue
+		}
+		row := make([]string, len(s.columns))
+		for i, column := range s.columns {
+			row[i] = column.Value(result)
+		}
+		if err := s.writer.Write(row); err != nil {
+			s.err = fmt.Errorf("failed to write CSV row: %v", err)
+			continue
+		}
+		s.count++
+	}
+}
+
+// Add queues a finished check for the CSV file
+func (s *CSVStream) Add(result APIResult) {
+	s.rows <- result
+}
+
+// Close waits for the queued rows to be written and closes the file
+func (s *CSVStream) Close() error {
+	s.once.Do(func() { close(s.rows) })
+	<-s.done
+
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil && s.err == nil {
+		s.err = fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	fmt.Printf("✅ CSV streamed to: %s (%d rows)\n", s.filename, s.count)
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// scheduledScan is a config schedule entry ready to run
+type scheduledScan struct {
+	entry    ScheduleEntry
+	schedule *cronSchedule
+	next     time.Time
+}
+
+// runDaemon runs the config file's scheduled scans until interrupted
+func runDaemon(options CheckerOptions, history StateStore) error {
+	if len(config.Schedules) == 0 {
+		return fmt.Errorf("--daemon needs at least one entry under schedules in the config file")
+	}
+
+	now := time.Now()
+	scans := make([]*scheduledScan, 0, len(config.Schedules))
+	for i, entry := range config.Schedules {
+		schedule, err := parseCron(entry.Cron)
+		if err != nil {
+			return fmt.Errorf("schedule %d: %v", i+1, err)
+		}
+		if len(entry.Projects) == 0 {
+			// Entries without projects scan the --project projects
+			entry.Projects = projectIDs
+		}
+		if len(entry.Projects) == 0 {
+			return fmt.Errorf("schedule %d (%s): no projects given", i+1, entry.Cron)
+		}
+		scans = append(scans, &scheduledScan{entry: entry, schedule: schedule, next: schedule.Next(now)})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Stopping the daemon also stops a scan in progress
+	options.Context = ctx
+
+	fmt.Printf("🕒 Daemon started with %d schedule(s)\n", len(scans))
+	for {
+		// A schedule without a next run would otherwise fire at once, over and over
+		active := scans[:0]
+		for _, scan := range scans {
+			if scan.next.IsZero() {
+				log.Printf("Warning: schedule %s has no next run and is stopped", scan.entry.Cron)
+				continue
+			}
+			active = append(active, scan)
+		}
+		scans = active
+		if len(scans) == 0 {
+			return fmt.Errorf("no schedule has a next run")
+		}
+
+		next := scans[0]
+		for _, scan := range scans[1:] {
+			if scan.next.Before(next.next) {
+				next = scan
+			}
+		}
+		fmt.Printf("🕒 Next scan of %s at %s\n", strings.Join(next.entry.Projects, ", "), next.next.Format("2006-01-02 15:04 MST"))
+
+		timer := time.NewTimer(time.Until(next.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			fmt.Println("👋 Daemon stopped")
+			return nil
+		case <-timer.C:
+		}
+
+		for _, scan := range scans {
+			if scan.next.After(time.Now()) {
+				continue
+			}
+
+			fmt.Printf("\n⏰ Scheduled scan (%s) of %s\n", scan.entry.Cron, strings.Join(scan.entry.Projects, ", "))
+			// An entry's own notify settings take precedence over --notify-webhook
+			notify := notifySettings()
+			if scan.entry.Notify != nil {
+				notify = *scan.entry.Notify
+			}
+			if err := runScan(scan.entry.Projects, options, notify, history); err != nil {
+				log.Printf("Warning: scheduled scan failed: %v", err)
+			}
+
+			// Runs missed while scanning are skipped rather than queued
+			scan.next = scan.schedule.Next(time.Now())
+		}
+	}
+}
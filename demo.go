@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// demoEnvironments name the generated projects; more projects repeat them with a number
+var demoEnvironments = []string{"prod", "staging", "dev", "sandbox"}
+
+// demoEnableRates is the share of APIs enabled per environment, so production looks busier
+var demoEnableRates = map[string]float64{"prod": 0.45, "staging": 0.35, "dev": 0.3, "sandbox": 0.25}
+
+// demoErrors are the failures sprinkled over the dataset, with whether they were throttled
+var demoErrors = []struct {
+	message   string
+	throttled bool
+}{
+	{"API request failed with status: 429 (throttled)", true},
+	{"API request failed with status: 403", false},
+	{"failed to make API request: context deadline exceeded", false},
+}
+
+// demoUsers enabled the generated APIs
+var demoUsers = []string{"alice@example.com", "bob@example.com", "ci-deployer@demo-prod.iam.gserviceaccount.com", "terraform@demo-ops.iam.gserviceaccount.com"}
+
+// newDemoCmd creates the subcommand that runs the report pipeline on generated data
+func newDemoCmd() *cobra.Command {
+	var (
+		projects int
+		seed     int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Generate a randomized dataset and run the full report and export pipeline on it, without credentials",
+		Long: `Generate a realistic randomized scan of several projects (every catalog
+service per project, with varied costs, usage, risk scores and errors) and
+produce the console report, results and report JSON, HTML report and the
+exports from it, so the output formats can be tried without any Google
+credentials. Nothing is sent to Google and the scan history is not touched.`,
+		Example: "  googleapichecker demo --export both --output-dir ./demo\n  googleapichecker demo --projects 8 --seed 42",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutputFlags(); err != nil {
+				return err
+			}
+			if projects < 1 {
+				return fmt.Errorf("--projects must be at least 1")
+			}
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+
+			now := time.Now()
+			results, findings := generateDemoScan(rand.New(rand.NewSource(seed)), projects, now)
+			fmt.Printf("🎲 Generated %d demo results for %d projects (seed %d)\n", len(results), projects, seed)
+
+			artifacts, err := NewArtifactNamer(outputDir, filenameTemplate, resultProjects(results), now)
+			if err != nil {
+				return err
+			}
+			if err := artifacts.EnsureDir(); err != nil {
+				return err
+			}
+
+			tags := map[string]string{"demo": "true"}
+			resultsFile := artifacts.Path("results", "json")
+			// The report assigns the cost tiers the results file keeps
+			report := GenerateReportWithPolicy(results, PolicyFromConfig(config))
+			if err := SaveResults(results, resultsFile, tags); err != nil {
+				return fmt.Errorf("error saving results: %v", err)
+			}
+
+			report.SetFindings(findings)
+			report.Tags = tags
+			compareWithBaseline(report, &ResultsFile{GeneratedAt: now, Tags: tags, Results: results}, resultsFile)
+			printReport(report)
+
+			reportFile, err := writeReportFiles(report, results, artifacts, []string{resultsFile})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("💾 Results saved to: %s\n", resultsFile)
+			fmt.Printf("📊 Report saved to: %s\n", reportFile)
+			return nil
+		},
+	}
+
+	addOutputFlags(cmd)
+	cmd.Flags().IntVar(&projects, "projects", len(demoEnvironments), "Number of projects to generate")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Random seed for a reproducible dataset (default: random)")
+	return cmd
+}
+
+// generateDemoScan builds the results and project findings of a made-up scan of the catalog services
+func generateDemoScan(rng *rand.Rand, projects int, now time.Time) ([]APIResult, []Finding) {
+	var results []APIResult
+	var findings []Finding
+
+	for i := 0; i < projects; i++ {
+		environment := demoEnvironments[i%len(demoEnvironments)]
+		projectID := "demo-" + environment
+		if i >= len(demoEnvironments) {
+			projectID = fmt.Sprintf("%s-%d", projectID, i/len(demoEnvironments)+1)
+		}
+
+		// Prices come from expected usage, so estimates and free-tier coverage vary like real ones
+		usage := make(map[string]float64)
+		for name, price := range unitPrices {
+			usage[name] = math.Round((price.FreeTier + price.Per) * math.Pow(10, rng.Float64()*2.5-1))
+		}
+		checker := NewGoogleAPIChecker("", projectID, 1, CheckerOptions{ExpectedUsage: usage, NoProgress: true})
+		hasBudget := rng.Float64() < 0.5
+
+		for _, service := range serviceCatalog {
+			results = append(results, generateDemoResult(rng, checker, service, environment, hasBudget, now))
+		}
+
+		if rng.Float64() < 0.5 {
+			findings = append(findings, Finding{ProjectID: projectID, Severity: "medium", Category: "billing",
+				Message: "No billing export to BigQuery found; enable it so cost recommendations can be based on actual spend"})
+		}
+	}
+	return results, findings
+}
+
+// generateDemoResult makes up the check of one service in one project
+func generateDemoResult(rng *rand.Rand, checker *GoogleAPIChecker, service CatalogService, environment string, hasBudget bool, now time.Time) APIResult {
+	result := APIResult{
+		ProjectID:   checker.projectID,
+		Environment: environment,
+		Name:        service.Name,
+		DisplayName: service.DisplayName,
+		DocsURL:     service.DocsURL,
+		CheckedAt:   now.Add(-time.Duration(rng.Intn(120)) * time.Second),
+		DurationMs:  40 + int64(rng.ExpFloat64()*120),
+	}
+
+	if rng.Float64() < 0.03 {
+		failure := demoErrors[rng.Intn(len(demoErrors))]
+		result.Status = statusError
+		result.Error = failure.message
+		result.Throttled = failure.throttled
+		return result
+	}
+
+	result.Enabled = rng.Float64() < demoEnableRates[environment]
+	switch {
+	case result.Enabled && rng.Float64() < 0.05:
+		result.Status = statusDisabling
+	case result.Enabled:
+		result.Status = statusEnabled
+	case rng.Float64() < 0.03:
+		result.Status = statusEnabling
+	default:
+		result.Status = statusDisabled
+	}
+
+	result.CostInfo = checker.applyExpectedUsage(service.Name, CostInfo{Currency: "USD", PricingDetails: "No pricing information available"})
+	if result.CostInfo.HasPricing {
+		result.CostInfo.UnlimitedCost = rng.Float64() < 0.15
+		result.CostInfo.SKUs = skuBreakdown(service.Name, result.CostInfo.EstimatedCost)
+	}
+	if !result.Enabled {
+		return result
+	}
+
+	requests := int64(0)
+	if rng.Float64() >= 0.2 {
+		requests = int64(math.Pow(10, 1+rng.Float64()*6))
+	}
+	result.RequestCount90d = &requests
+	resources := 0
+	if rng.Float64() >= 0.15 {
+		resources = 1 + rng.Intn(200)
+	}
+	result.ResourceCount = &resources
+
+	enabledAt := now.AddDate(0, 0, -rng.Intn(900))
+	result.EnabledAt = &enabledAt
+	result.EnabledBy = demoUsers[rng.Intn(len(demoUsers))]
+
+	// Maps Platform APIs are scored like --risk-score does
+	if service.Category == riskScoredCategory {
+		score := riskWeightEnabled
+		factors := []string{"API enabled"}
+		if rng.Float64() < 0.4 {
+			key := fmt.Sprintf("projects/%d/locations/global/keys/demo-browser-key", 100000+rng.Intn(900000))
+			result.UnrestrictedKeys = []string{key}
+			score += riskWeightUnrestrictedKey
+			factors = append(factors, "1 unrestricted API key(s): Browser key (auto created)")
+		}
+		if !hasBudget {
+			score += riskWeightNoBudgetAlert
+			factors = append(factors, noBudgetFactor)
+		}
+		result.RiskScore = &score
+		result.RiskFactors = factors
+	}
+	return result
+}
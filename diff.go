@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Kinds of change between two scans
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// statusNotListed is the status of an API that one of the scans did not list
+const statusNotListed = "NOT_LISTED"
+
+// The scan reports are compared with, set from --baseline
+var (
+	baselineFile string
+	baseline     *ResultsFile
+)
+
+// ScanRef identifies one side of a diff
+type ScanRef struct {
+	Source      string            `json:"source"`
+	GeneratedAt time.Time         `json:"generated_at,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Enabled     int               `json:"enabled"`
+	TotalCost   float64           `json:"total_cost"`
+}
+
+// DiffEntry is an API that was enabled, disabled or changed between two scans. Costs are the
+// monthly estimates of enabled, priced APIs, 0 otherwise.
+type DiffEntry struct {
+	// Kind is added (enabled since), removed (no longer enabled) or changed (status or cost)
+	Kind           string  `json:"kind"`
+	ProjectID      string  `json:"project_id,omitempty"`
+	Environment    string  `json:"environment,omitempty"`
+	API            string  `json:"api"`
+	DisplayName    string  `json:"display_name,omitempty"`
+	PreviousStatus string  `json:"previous_status"`
+	CurrentStatus  string  `json:"current_status"`
+	PreviousCost   float64 `json:"previous_cost"`
+	CurrentCost    float64 `json:"current_cost"`
+	CostDelta      float64 `json:"cost_delta"`
+}
+
+// ScanDiff is the change between two scans, as printed by the diff subcommand
+type ScanDiff struct {
+	Previous  ScanRef     `json:"previous"`
+	Current   ScanRef     `json:"current"`
+	Added     []DiffEntry `json:"added"`
+	Removed   []DiffEntry `json:"removed"`
+	Changed   []DiffEntry `json:"changed"`
+	CostDelta float64     `json:"cost_delta"`
+}
+
+// scanRef summarizes one side of a diff
+func scanRef(source string, resultsFile *ResultsFile) ScanRef {
+	ref := ScanRef{Source: source, GeneratedAt: resultsFile.GeneratedAt, Tags: resultsFile.Tags}
+	for _, result := range resultsFile.Results {
+		if result.Enabled {
+			ref.Enabled++
+			ref.TotalCost += enabledCost(result)
+		}
+	}
+	return ref
+}
+
+// DiffScans compares two scans per project and API. APIs whose current state could not be
+// determined are left out, so a failed check is not reported as a disabled API.
+func DiffScans(previous, current *ResultsFile, previousSource, currentSource string) ScanDiff {
+	diff := ScanDiff{
+		Previous: scanRef(previousSource, previous),
+		Current:  scanRef(currentSource, current),
+		Added:    []DiffEntry{},
+		Removed:  []DiffEntry{},
+		Changed:  []DiffEntry{},
+	}
+	diff.CostDelta = diff.Current.TotalCost - diff.Previous.TotalCost
+
+	before := make(map[string]APIResult)
+	for _, result := range previous.Results {
+		before[result.ProjectID+"/"+result.Name] = result
+	}
+	seen := make(map[string]bool)
+
+	for _, result := range current.Results {
+		key := result.ProjectID + "/" + result.Name
+		seen[key] = true
+		if result.Error != "" || statusUndetermined(result.Status) {
+			continue
+		}
+
+		old, ok := before[key]
+		if ok && (old.Error != "" || statusUndetermined(old.Status)) {
+			continue
+		}
+		entry := DiffEntry{ProjectID: result.ProjectID, Environment: result.Environment, API: result.Name,
+			DisplayName: result.DisplayName, PreviousStatus: statusNotListed, CurrentStatus: result.Status,
+			CurrentCost: enabledCost(result)}
+		if ok {
+			entry.PreviousStatus, entry.PreviousCost = old.Status, enabledCost(old)
+		}
+		entry.CostDelta = entry.CurrentCost - entry.PreviousCost
+
+		switch {
+		case result.Enabled && !old.Enabled:
+			entry.Kind = diffAdded
+			diff.Added = append(diff.Added, entry)
+		case !result.Enabled && old.Enabled:
+			entry.Kind = diffRemoved
+			diff.Removed = append(diff.Removed, entry)
+		case ok && (entry.PreviousStatus != entry.CurrentStatus || math.Abs(entry.CostDelta) >= 0.005):
+			entry.Kind = diffChanged
+			diff.Changed = append(diff.Changed, entry)
+		}
+	}
+
+	// APIs enabled before that the current scan did not list at all, e.g. a project left out
+	for _, result := range previous.Results {
+		if seen[result.ProjectID+"/"+result.Name] || !result.Enabled {
+			continue
+		}
+		diff.Removed = append(diff.Removed, DiffEntry{Kind: diffRemoved, ProjectID: result.ProjectID,
+			Environment: result.Environment, API: result.Name, DisplayName: result.DisplayName,
+			PreviousStatus: result.Status, CurrentStatus: statusNotListed,
+			PreviousCost: enabledCost(result), CostDelta: -enabledCost(result)})
+	}
+
+	for _, entries := range [][]DiffEntry{diff.Added, diff.Removed, diff.Changed} {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if math.Abs(entries[i].CostDelta) != math.Abs(entries[j].CostDelta) {
+				return math.Abs(entries[i].CostDelta) > math.Abs(entries[j].CostDelta)
+			}
+			if entries[i].ProjectID != entries[j].ProjectID {
+				return entries[i].ProjectID < entries[j].ProjectID
+			}
+			return entries[i].API < entries[j].API
+		})
+	}
+	return diff
+}
+
+// compareWithBaseline records the changes since the --baseline scan on the report
+func compareWithBaseline(report *Report, current *ResultsFile, source string) {
+	if baseline == nil {
+		return
+	}
+	diff := DiffScans(baseline, current, baselineFile, source)
+	report.Changes = &diff
+}
+
+// latestHistoryScans loads the two most recent scans in the history, oldest first
+func latestHistoryScans(store StateStore) ([]*ResultsFile, []string, error) {
+	files, err := historyFiles(store)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) < 2 {
+		return nil, nil, fmt.Errorf("the scan history has %d scans; diff needs two, or two results files as arguments", len(files))
+	}
+
+	names := files[len(files)-2:]
+	var scans []*ResultsFile
+	for _, name := range names {
+		data, err := store.Load(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		resultsFile, err := decodeResultsFile(data, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		scans = append(scans, resultsFile)
+	}
+	return scans, names, nil
+}
+
+// printDiff prints the diff for people
+func printDiff(diff ScanDiff) {
+	fmt.Printf("🔀 %s → %s\n", diff.Previous.Source, diff.Current.Source)
+	fmt.Printf("   Enabled APIs: %d → %d, estimated cost: $%.2f → $%.2f (%+.2f)\n",
+		diff.Previous.Enabled, diff.Current.Enabled, diff.Previous.TotalCost, diff.Current.TotalCost, diff.CostDelta)
+
+	sections := []struct {
+		title   string
+		entries []DiffEntry
+	}{
+		{"🆕 Enabled since", diff.Added},
+		{"🗑️  No longer enabled", diff.Removed},
+		{"🔄 Changed", diff.Changed},
+	}
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s (%d):\n", section.title, len(section.entries))
+		table := newConsoleTable(
+			tableColumn{Header: "PROJECT", Flexible: true, MinWidth: 8, Drop: 1},
+			tableColumn{Header: "API", Flexible: true, MinWidth: 12},
+			tableColumn{Header: "STATUS", Drop: 2},
+			tableColumn{Header: "COST", Right: true},
+		)
+		for _, entry := range section.entries {
+			name := entry.DisplayName
+			if name == "" {
+				name = entry.API
+			}
+			table.AddRow(entry.ProjectID, name, entry.PreviousStatus+" → "+entry.CurrentStatus, fmt.Sprintf("%+.2f", entry.CostDelta))
+		}
+		table.Render(os.Stdout, consoleWidth())
+	}
+
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		fmt.Println("\n✅ No changes")
+	}
+}
+
+// newDiffCmd creates the subcommand that compares two scans
+func newDiffCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "diff [PREVIOUS_RESULTS CURRENT_RESULTS]",
+		Short: "Show the APIs enabled, disabled or changed in cost between two scans",
+		Long: `Compare two results files, or without arguments the two most recent scans in
+the scan history (--history-dir or --state-backend), and list the APIs that were
+enabled (added), are no longer enabled (removed) or changed status or estimated
+cost, with the monthly cost delta of each. APIs whose state could not be checked
+in either scan are left out. --format json prints the same as JSON, so bots can
+post e.g. "3 new APIs were enabled in prod since yesterday".`,
+		Example: "  googleapichecker diff\n  googleapichecker diff old_results.json new_results.json --format json",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("diff takes two results files, or none to compare the latest two scans in the history")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			if format != "text" && format != "json" {
+				return fmt.Errorf("invalid --format %q (use text or json)", format)
+			}
+
+			var scans []*ResultsFile
+			sources := args
+			if len(args) == 2 {
+				for _, file := range args {
+					resultsFile, err := LoadResultsFile(file)
+					if err != nil {
+						return err
+					}
+					scans = append(scans, resultsFile)
+				}
+			} else {
+				store, err := OpenStateStore(stateBackend, historyDir, "history")
+				if err != nil {
+					return err
+				}
+				if scans, sources, err = latestHistoryScans(store); err != nil {
+					return err
+				}
+			}
+
+			diff := DiffScans(scans[0], scans[1], sources[0], sources[1])
+			if format == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			printDiff(diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or json")
+	cmd.Flags().StringVar(&historyDir, "history-dir", defaultHistoryDir, "Directory where the scan history is kept")
+	cmd.Flags().StringVar(&stateBackend, "state-backend", "", "Read the scan history from Cloud Storage (gs://bucket/prefix) instead of --history-dir")
+	return cmd
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// discoveryDirectoryURL lists every API known to the Discovery service
+const discoveryDirectoryURL = "https://www.googleapis.com/discovery/v1/apis"
+
+// discoveryCacheTTL is how long a fetched directory is reused, e.g. across daemon runs
+const discoveryCacheTTL = time.Hour
+
+// DiscoveryAPI is one API in the Discovery directory, at its preferred version
+type DiscoveryAPI struct {
+	Name             string `json:"name"`
+	Version          string `json:"version"`
+	Title            string `json:"title"`
+	DiscoveryRestURL string `json:"discoveryRestUrl"`
+	Preferred        bool   `json:"preferred"`
+	// DocumentationLink is the API's documentation page
+	DocumentationLink string `json:"documentationLink,omitempty"`
+	// Labels are e.g. "deprecated" or "limited_availability"
+	Labels []string `json:"labels,omitempty"`
+}
+
+// deprecated reports whether the directory labels the API as deprecated
+func (a DiscoveryAPI) deprecated() bool {
+	for _, label := range a.Labels {
+		if label == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+// discoveryCache shares the directory between the checkers of a process
+var discoveryCache struct {
+	mu        sync.Mutex
+	apis      map[string]DiscoveryAPI
+	fetchedAt time.Time
+}
+
+// discoveryDirectory returns the Discovery directory by service name (e.g. compute.googleapis.com),
+// fetching it at most once per discoveryCacheTTL
+func (c *GoogleAPIChecker) discoveryDirectory() (map[string]DiscoveryAPI, error) {
+	discoveryCache.mu.Lock()
+	defer discoveryCache.mu.Unlock()
+
+	if discoveryCache.apis != nil && time.Since(discoveryCache.fetchedAt) < discoveryCacheTTL {
+		return discoveryCache.apis, nil
+	}
+
+	var directory struct {
+		Items []DiscoveryAPI `json:"items"`
+	}
+	if err := c.getJSON(discoveryDirectoryURL, "API list", &directory); err != nil {
+		return nil, err
+	}
+
+	discoveryCache.apis = preferredVersions(directory.Items)
+	discoveryCache.fetchedAt = time.Now()
+	return discoveryCache.apis, nil
+}
+
+// preferredVersions keeps one version per API: the preferred one, or the last listed when none is
+func preferredVersions(items []DiscoveryAPI) map[string]DiscoveryAPI {
+	apis := make(map[string]DiscoveryAPI)
+	for _, item := range items {
+		name := item.Name + ".googleapis.com"
+		if current, ok := apis[name]; ok && current.Preferred && !item.Preferred {
+			continue
+		}
+		apis[name] = item
+	}
+	return apis
+}
+
+// getDiscoveryAPIs lists the services in the Discovery directory
+func (c *GoogleAPIChecker) getDiscoveryAPIs() ([]string, error) {
+	directory, err := c.discoveryDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	apis := make([]string, 0, len(directory))
+	for name := range directory {
+		apis = append(apis, name)
+	}
+	sort.Strings(apis)
+	return apis, nil
+}
+
+// checkDiscoveryAPI checks that the API's preferred version has a discovery document.
+// Without a project that only shows the API exists, so existing APIs return errUndetermined
+func (c *GoogleAPIChecker) checkDiscoveryAPI(ctx context.Context, apiName string) (string, error) {
+	directory, err := c.discoveryDirectory()
+	if err != nil {
+		return "", err
+	}
+	api, ok := directory[apiName]
+	if !ok || api.DiscoveryRestURL == "" {
+		c.trace(ctx, "%s: not in the Discovery directory, assuming %s", apiName, statusDisabled)
+		return statusDisabled, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", api.DiscoveryRestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("X-Goog-Api-Key", c.token)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make API request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case 200:
+		return "", errUndetermined
+	case 404:
+		return statusDisabled, nil // API not found
+	}
+	return "", apiStatusError(resp.StatusCode)
+}
+
+// keylessServiceState answers from the public Discovery directory alone: an API in the directory
+// exists, but without a credential and project whether it is enabled is not known
+func (c *GoogleAPIChecker) keylessServiceState(apiName string) (string, error) {
+	directory, err := c.discoveryDirectory()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := directory[apiName]; !ok {
+		return "", fmt.Errorf("%s is not in the Discovery directory", apiName)
+	}
+	return "", errUndetermined
+}
+
+// lookupDiscoveryAPI returns the API's directory entry when the directory has been fetched
+func lookupDiscoveryAPI(apiName string) (DiscoveryAPI, bool) {
+	discoveryCache.mu.Lock()
+	defer discoveryCache.mu.Unlock()
+	api, ok := discoveryCache.apis[apiName]
+	return api, ok
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// defaultUniverseDomain is the domain Google APIs are served from outside sovereign clouds
+const defaultUniverseDomain = "googleapis.com"
+
+// endpoints is where Google API requests are sent, from the config file, --universe-domain and --endpoint
+var endpoints EndpointConfig
+
+// Endpoint flags
+var (
+	universeDomain string
+	endpointFlags  []string
+)
+
+// EndpointConfig points Google API requests at another universe domain, or at regional or private
+// endpoints, for customers who cannot call the global googleapis.com endpoints
+type EndpointConfig struct {
+	// UniverseDomain replaces googleapis.com in every API host, e.g. for a sovereign cloud
+	UniverseDomain string `yaml:"universe_domain"`
+	// Overrides maps a service, e.g. serviceusage, or * for every service, to the base URL its
+	// requests go to instead. {service} in the URL is replaced with the service name, e.g.
+	// https://{service}.eu.rep.googleapis.com or https://{service}-myendpoint.p.googleapis.com
+	Overrides map[string]string `yaml:"overrides"`
+}
+
+// parseEndpoints merges the --universe-domain and --endpoint service=URL flags over the config
+// file's endpoints and validates the result
+func parseEndpoints(configured EndpointConfig, domain string, flags []string) (EndpointConfig, error) {
+	merged := EndpointConfig{UniverseDomain: configured.UniverseDomain, Overrides: make(map[string]string)}
+	for service, base := range configured.Overrides {
+		merged.Overrides[service] = base
+	}
+	if domain != "" {
+		merged.UniverseDomain = domain
+	}
+	for _, flag := range flags {
+		service, base, ok := strings.Cut(flag, "=")
+		if !ok || service == "" || base == "" {
+			return EndpointConfig{}, fmt.Errorf("invalid --endpoint %q (use service=URL, e.g. serviceusage=https://serviceusage.eu.rep.googleapis.com)", flag)
+		}
+		merged.Overrides[service] = base
+	}
+
+	merged.UniverseDomain = strings.Trim(strings.ToLower(merged.UniverseDomain), ".")
+	if strings.ContainsAny(merged.UniverseDomain, "/:") {
+		return EndpointConfig{}, fmt.Errorf("invalid universe domain %q: use a domain such as %s", merged.UniverseDomain, defaultUniverseDomain)
+	}
+	for service, base := range merged.Overrides {
+		u, err := url.Parse(strings.ReplaceAll(base, "{service}", "service"))
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return EndpointConfig{}, fmt.Errorf("invalid endpoint for %s: %q is not an http(s) URL", service, base)
+		}
+	}
+	return merged, nil
+}
+
+// rewrite points a request URL for a Google API at the configured endpoint. URLs outside
+// googleapis.com, e.g. the metadata server or webhooks, are left alone.
+func (e EndpointConfig) rewrite(u *url.URL) {
+	service, ok := strings.CutSuffix(u.Hostname(), "."+defaultUniverseDomain)
+	if !ok {
+		return
+	}
+
+	base, ok := e.Overrides[service]
+	if !ok {
+		base, ok = e.Overrides["*"]
+	}
+	if ok {
+		target, err := url.Parse(strings.ReplaceAll(base, "{service}", service))
+		if err != nil {
+			return
+		}
+		u.Scheme, u.Host = target.Scheme, target.Host
+		u.Path = strings.TrimSuffix(target.Path, "/") + u.Path
+		if u.RawPath != "" {
+			u.RawPath = strings.TrimSuffix(target.Path, "/") + u.RawPath
+		}
+		return
+	}
+
+	if e.UniverseDomain != "" && e.UniverseDomain != defaultUniverseDomain {
+		u.Host = service + "." + e.UniverseDomain
+	}
+}
+
+// resolve returns the URL a Google API request should be sent to
+func (e EndpointConfig) resolve(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	e.rewrite(u)
+	return u.String()
+}
+
+// String describes the endpoints that differ from the global ones, empty when none do
+func (e EndpointConfig) String() string {
+	var parts []string
+	if e.UniverseDomain != "" && e.UniverseDomain != defaultUniverseDomain {
+		parts = append(parts, "universe "+e.UniverseDomain)
+	}
+	services := make([]string, 0, len(e.Overrides))
+	for service := range e.Overrides {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		parts = append(parts, service+" → "+e.Overrides[service])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// expectedManifest is the manifest loaded from --expected, nil without it
+var expectedManifest *ExpectedManifest
+
+// Kinds of drift from the expected state
+const (
+	driftUnexpected = "unexpected"
+	driftMissing    = "missing"
+)
+
+// ExpectedState lists the APIs a project should have enabled. Names may use glob patterns,
+// e.g. "firebase*", and the .googleapis.com suffix may be left out.
+type ExpectedState struct {
+	// Required APIs must be enabled
+	Required []string `yaml:"required"`
+	// Optional APIs may be enabled; any other enabled API is unexpected
+	Optional []string `yaml:"optional"`
+}
+
+// ExpectedManifest is the desired API enablement loaded with --expected. A project is checked
+// against its entry under projects, else its environment's, else default.
+type ExpectedManifest struct {
+	Path         string                   `yaml:"-"`
+	Default      *ExpectedState           `yaml:"default"`
+	Environments map[string]ExpectedState `yaml:"environments"`
+	Projects     map[string]ExpectedState `yaml:"projects"`
+}
+
+// Drift is an API whose enablement differs from the expected manifest
+type Drift struct {
+	ProjectID   string `json:"project_id,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	API         string `json:"api"`
+	DisplayName string `json:"display_name,omitempty"`
+	// Kind is unexpected (enabled but not listed) or missing (required but not enabled)
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+}
+
+// String describes the drift with its project
+func (d Drift) String() string {
+	name := d.API
+	if d.DisplayName != "" && d.DisplayName != d.API {
+		name = fmt.Sprintf("%s (%s)", d.DisplayName, d.API)
+	}
+	if d.ProjectID != "" {
+		name = fmt.Sprintf("[%s] %s", d.ProjectID, name)
+	}
+	if d.Kind == driftMissing {
+		return fmt.Sprintf("%s is required but %s", name, d.Status)
+	}
+	return fmt.Sprintf("%s is enabled but not expected", name)
+}
+
+// addExpectedFlags registers the expected-state flags shared by scans and the report subcommand
+func addExpectedFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&expectedFile, "expected", "", "YAML manifest of the APIs that should be enabled per environment or project; differences are reported as drift")
+	cmd.Flags().BoolVar(&failOnDrift, "fail-on-drift", false, "Exit with an error, once the report is written, when the scan drifted from the --expected manifest")
+}
+
+// driftError fails the run with --fail-on-drift when the report has drift
+func driftError(report *Report) error {
+	if !failOnDrift || len(report.Drift) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d APIs drifted from the expected state in %s", len(report.Drift), expectedManifest.Path)
+}
+
+// LoadExpectedManifest reads and validates an expected-state manifest
+func LoadExpectedManifest(file string) (*ExpectedManifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expected manifest: %v", err)
+	}
+
+	manifest := &ExpectedManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse expected manifest %s: %v", file, err)
+	}
+	manifest.Path = file
+	if manifest.Default == nil && len(manifest.Environments) == 0 && len(manifest.Projects) == 0 {
+		return nil, fmt.Errorf("expected manifest %s has no default, environments or projects", file)
+	}
+
+	states := []*ExpectedState{manifest.Default}
+	for name := range manifest.Environments {
+		state := manifest.Environments[name]
+		states = append(states, &state)
+	}
+	for name := range manifest.Projects {
+		state := manifest.Projects[name]
+		states = append(states, &state)
+	}
+	for _, state := range states {
+		if state == nil {
+			continue
+		}
+		for _, pattern := range append(state.Required, state.Optional...) {
+			if _, err := path.Match(normalizeServiceName(pattern), ""); err != nil {
+				return nil, fmt.Errorf("expected manifest %s: invalid pattern %q", file, pattern)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// stateFor returns the expected state of a project, nil when the manifest does not cover it
+func (m *ExpectedManifest) stateFor(projectID, environment string) *ExpectedState {
+	if state, ok := m.Projects[projectID]; ok {
+		return &state
+	}
+	if state, ok := m.Environments[environment]; ok && environment != "" {
+		return &state
+	}
+	return m.Default
+}
+
+// matchesAny reports whether the API matches one of the patterns
+func matchesAny(api string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(normalizeServiceName(pattern), api); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Drift compares the results with the manifest: enabled APIs it does not list are unexpected and
+// required APIs that are not enabled are missing. APIs whose state could not be checked are left out.
+func (m *ExpectedManifest) Drift(results []APIResult) []Drift {
+	type project struct {
+		environment string
+		results     []APIResult
+	}
+	projects := make(map[string]*project)
+	var order []string
+	for _, result := range results {
+		p, ok := projects[result.ProjectID]
+		if !ok {
+			p = &project{}
+			projects[result.ProjectID] = p
+			order = append(order, result.ProjectID)
+		}
+		if result.Environment != "" {
+			p.environment = result.Environment
+		}
+		p.results = append(p.results, result)
+	}
+
+	var drift []Drift
+	for _, projectID := range order {
+		p := projects[projectID]
+		state := m.stateFor(projectID, p.environment)
+		if state == nil {
+			continue
+		}
+
+		listed := make(map[string]bool)
+		for _, result := range p.results {
+			listed[result.Name] = true
+			if result.Error != "" || statusUndetermined(result.Status) {
+				continue
+			}
+			required := matchesAny(result.Name, state.Required)
+			if result.Enabled && !required && !matchesAny(result.Name, state.Optional) {
+				drift = append(drift, Drift{ProjectID: projectID, Environment: p.environment, API: result.Name,
+					DisplayName: result.DisplayName, Kind: driftUnexpected, Status: result.Status})
+			} else if !result.Enabled && required {
+				drift = append(drift, Drift{ProjectID: projectID, Environment: p.environment, API: result.Name,
+					DisplayName: result.DisplayName, Kind: driftMissing, Status: result.Status})
+			}
+		}
+
+		// Required APIs the scan did not list, e.g. with --state ENABLED, are reported too
+		for _, required := range state.Required {
+			name := normalizeServiceName(required)
+			if listed[name] || strings.ContainsAny(name, "*?[") {
+				continue
+			}
+			drift = append(drift, Drift{ProjectID: projectID, Environment: p.environment, API: name,
+				Kind: driftMissing, Status: "not listed by the scan"})
+			if service, ok := lookupCatalog(name); ok {
+				drift[len(drift)-1].DisplayName = service.DisplayName
+			}
+		}
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].ProjectID != drift[j].ProjectID {
+			return drift[i].ProjectID < drift[j].ProjectID
+		}
+		if drift[i].Kind != drift[j].Kind {
+			return drift[i].Kind < drift[j].Kind
+		}
+		return drift[i].API < drift[j].API
+	})
+	return drift
+}
+
+// SetDrift records the drift from the manifest on the report and puts it first in the recommendations
+func (r *Report) SetDrift(drift []Drift, manifestPath string) {
+	r.Drift = drift
+	if len(drift) == 0 {
+		return
+	}
+
+	recommendations := []string{
+		fmt.Sprintf("🧭 DRIFT: %d APIs differ from the expected state in %s; enable the missing ones and disable the unexpected ones or add them to the manifest:", len(drift), manifestPath),
+	}
+	for _, d := range drift {
+		recommendations = append(recommendations, fmt.Sprintf("   - %s", d))
+	}
+	r.Recommendations = append(recommendations, r.Recommendations...)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultUsageFile is loaded from the working directory when --usage-file is not given
+const defaultUsageFile = "usage.yaml"
+
+// LoadExpectedUsage reads expected monthly usage per service, e.g. "maps: 2M requests/month".
+// Quantities are returned in each service's billing unit. An empty path loads defaultUsageFile
+// when it exists and otherwise returns nil.
+func LoadExpectedUsage(path string) (map[string]float64, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultUsageFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read usage file: %v", err)
+	}
+
+	var raw map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file %s: %v", path, err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usage := make(map[string]float64, len(raw))
+	for _, name := range names {
+		service := normalizeServiceName(name)
+		price, ok := unitPrices[service]
+		if !ok {
+			return nil, fmt.Errorf("usage file %s: no unit price known for %s", path, service)
+		}
+
+		quantity, err := parseUsageQuantity(raw[name], price.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("usage file %s: %s: %v", path, name, err)
+		}
+		usage[service] = quantity
+	}
+
+	return usage, nil
+}
+
+// applyExpectedUsage replaces the estimate with one priced from the expected usage, when given
+func (c *GoogleAPIChecker) applyExpectedUsage(apiName string, costInfo CostInfo) CostInfo {
+	quantity, ok := c.options.ExpectedUsage[apiName]
+	if !ok {
+		return costInfo
+	}
+	price := unitPrices[apiName]
+
+	costInfo.HasPricing = true
+	costInfo.Currency = "USD"
+	costInfo.EstimatedCost = price.estimateCost(quantity)
+	costInfo.ExpectedUsage = quantity
+	costInfo.UsageUnit = price.Unit
+	costInfo.PricingDetails = fmt.Sprintf("Estimated from expected usage of %s %s/month at %s",
+		formatQuantity(quantity), price.Unit, price)
+	if price.FreeTier > 0 {
+		costInfo.FreeTierCovered = quantity <= price.FreeTier
+		costInfo.PricingDetails += fmt.Sprintf(" after the free tier of %s %s", formatQuantity(price.FreeTier), price.Unit)
+		if costInfo.FreeTierCovered {
+			costInfo.PricingDetails += " (fully covered)"
+		}
+	}
+
+	return costInfo
+}
@@ -6,20 +6,41 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"time"
+	"strings"
+	"text/template"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
 // ExportOptions contains export configuration
 type ExportOptions struct {
-	Format     string // "csv", "pdf", "both"
-	OutputDir  string
-	IncludeRaw bool
+	Format     string        // "csv", "pdf", "both", "gitlab", "bitbucket"
+	Artifacts  ArtifactNamer // Output directory and filename template
+	IncludeRaw bool          // Write each API's raw Service Usage and billing export responses to raw/
+	Landscape  bool          // Landscape detailed table with pricing details and check time
+	SourcePath string        // File the GitLab and Bitbucket findings point at, e.g. the config file
+
+	CSVColumns   []string // Column keys to include, defaults to defaultCSVColumns
+	CSVDelimiter rune     // Field delimiter, defaults to comma
+	CSVPerStatus bool     // Write one CSV per status (enabled/disabled/errors)
+	CSVStreamed  bool     // The results CSV was already written during the scan by --csv-stream
+	// SummaryTemplate lays out the summary export, nil for the default layout
+	SummaryTemplate *template.Template
 }
 
 // ExportResults exports the results in various formats
 func ExportResults(report *Report, results []APIResult, options ExportOptions) error {
+	if options.IncludeRaw {
+		dir := filepath.Join(options.Artifacts.Dir, "raw")
+		written, err := writeRawResponses(dir, results)
+		if err != nil {
+			return err
+		}
+		if written > 0 {
+			fmt.Printf("✅ Raw responses of %d APIs exported to: %s\n", written, dir)
+		}
+	}
+
 	switch options.Format {
 	case "csv":
 		return exportToCSV(report, results, options)
@@ -33,15 +54,207 @@ func ExportResults(report *Report, results []APIResult, options ExportOptions) e
 			return fmt.Errorf("PDF export failed: %v", err)
 		}
 		return nil
+	case "gitlab":
+		return exportToGitLab(report, options)
+	case "bitbucket":
+		return exportToBitbucket(report, options)
 	default:
 		return fmt.Errorf("unsupported export format: %s", options.Format)
 	}
 }
 
+// csvColumn describes a selectable CSV column
+type csvColumn struct {
+	Key    string
+	Header string
+	Value  func(result APIResult) string
+}
+
+// availableCSVColumns lists every available CSV column in default output order
+var availableCSVColumns = []csvColumn{
+	{"project", "Project", func(r APIResult) string { return r.ProjectID }},
+	{"environment", "Environment", func(r APIResult) string { return r.Environment }},
+	{"name", "API Name", func(r APIResult) string { return r.Name }},
+	{"display_name", "Display Name", func(r APIResult) string { return r.DisplayName }},
+	{"status", "Status", func(r APIResult) string { return r.Status }},
+	{"enabled", "Enabled", func(r APIResult) string { return strconv.FormatBool(r.Enabled) }},
+	{"has_pricing", "Has Pricing", func(r APIResult) string { return strconv.FormatBool(r.CostInfo.HasPricing) }},
+	{"unlimited_cost", "Unlimited Cost", func(r APIResult) string { return strconv.FormatBool(r.CostInfo.UnlimitedCost) }},
+	{"unlimited_reason", "Unlimited Reason", func(r APIResult) string { return r.CostInfo.UnlimitedReason }},
+	{"cost_tier", "Cost Tier", func(r APIResult) string { return r.CostTier }},
+	{"actual_cost", "Actual Cost", func(r APIResult) string {
+		if r.CostInfo.ActualCost == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*r.CostInfo.ActualCost, 'f', 2, 64)
+	}},
+	{"free_tier_covered", "Free Tier Covered", func(r APIResult) string { return strconv.FormatBool(r.CostInfo.FreeTierCovered) }},
+	{"estimated_cost", "Estimated Cost (USD)", func(r APIResult) string { return fmt.Sprintf("%.2f", projection.amount(r.CostInfo.EstimatedCost)) }},
+	{"currency", "Currency", func(r APIResult) string { return r.CostInfo.Currency }},
+	{"pricing_details", "Pricing Details", func(r APIResult) string { return r.CostInfo.PricingDetails }},
+	{"checked_at", "Checked At", func(r APIResult) string { return r.CheckedAt.Format("2006-01-02 15:04:05") }},
+	{"request_count_90d", "Requests (90d)", func(r APIResult) string {
+		if r.RequestCount90d == nil {
+			return ""
+		}
+		return strconv.FormatInt(*r.RequestCount90d, 10)
+	}},
+	{"incidents", "Active Incidents", func(r APIResult) string {
+		ids := make([]string, len(r.Incidents))
+		for i, incident := range r.Incidents {
+			ids[i] = incident.ID
+		}
+		return strings.Join(ids, " ")
+	}},
+	{"resource_count", "Resources", func(r APIResult) string {
+		if r.ResourceCount == nil {
+			return ""
+		}
+		return strconv.Itoa(*r.ResourceCount)
+	}},
+	{"risk_note", "Risk Note", func(r APIResult) string { return r.RiskNote }},
+	{"risk_score", "Risk Score", func(r APIResult) string {
+		if r.RiskScore == nil {
+			return ""
+		}
+		return strconv.Itoa(*r.RiskScore)
+	}},
+	{"enabled_by", "Enabled By", func(r APIResult) string { return r.EnabledBy }},
+	{"enabled_at", "Enabled At", func(r APIResult) string {
+		if r.EnabledAt == nil {
+			return ""
+		}
+		return r.EnabledAt.Format("2006-01-02 15:04:05")
+	}},
+	{"api_version", "API Version", func(r APIResult) string { return r.APIVersion }},
+	{"docs_url", "Docs URL", func(r APIResult) string { return r.DocsURL }},
+	{"deprecated", "Deprecated", func(r APIResult) string { return strconv.FormatBool(r.Deprecated) }},
+	{"duration_ms", "Duration (ms)", func(r APIResult) string {
+		if r.DurationMs == 0 {
+			return ""
+		}
+		return strconv.FormatInt(r.DurationMs, 10)
+	}},
+	{"throttled", "Throttled", func(r APIResult) string { return strconv.FormatBool(r.Throttled) }},
+	{"warning", "Warning", func(r APIResult) string { return r.Warning }},
+	{"error", "Error", func(r APIResult) string { return r.Error }},
+}
+
+// defaultCSVColumns are written when no column selection is given
+var defaultCSVColumns = []string{
+	"name", "display_name", "status", "enabled", "has_pricing", "unlimited_cost",
+	"estimated_cost", "cost_tier", "currency", "pricing_details", "checked_at", "error",
+}
+
+// ParseCSVDelimiter converts a delimiter name (comma, semicolon, tab) or single character to a rune
+func ParseCSVDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "", "comma", ",":
+		return ',', nil
+	case "semicolon", ";":
+		return ';', nil
+	case "tab", "\\t":
+		return '\t', nil
+	}
+
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("unsupported CSV delimiter: %s (use comma, semicolon, tab or a single character)", value)
+	}
+	return runes[0], nil
+}
+
+// resolveCSVColumns maps column keys to their definitions
+func resolveCSVColumns(keys []string) ([]csvColumn, error) {
+	if len(keys) == 0 {
+		keys = defaultCSVColumns
+	}
+
+	var columns []csvColumn
+	for _, key := range keys {
+		key = strings.TrimSpace(strings.ToLower(key))
+		found := false
+		for _, column := range availableCSVColumns {
+			if column.Key == key {
+				// Other periods than the monthly estimate are named in the header
+				if column.Key == "estimated_cost" && projection.Factor != 1 {
+					column.Header = "Estimated Cost (USD" + projection.Suffix + ")"
+				}
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var available []string
+			for _, column := range availableCSVColumns {
+				available = append(available, column.Key)
+			}
+			return nil, fmt.Errorf("unknown CSV column: %s (available: %s)", key, strings.Join(available, ", "))
+		}
+	}
+
+	return columns, nil
+}
+
 // exportToCSV exports results to CSV format
 func exportToCSV(report *Report, results []APIResult, options ExportOptions) error {
-	filename := filepath.Join(options.OutputDir, fmt.Sprintf("google_api_checker_%s.csv", time.Now().Format("20060102_150405")))
+	columns, err := resolveCSVColumns(options.CSVColumns)
+	if err != nil {
+		return err
+	}
 
+	if len(report.CostAnalysis.CostAllocation) > 0 {
+		filename := options.Artifacts.Path("cost_allocation", "csv")
+		if err := writeCostAllocationCSV(filename, results, options.CSVDelimiter); err != nil {
+			return err
+		}
+	}
+
+	if hasSKUs(results) {
+		filename := options.Artifacts.Path("skus", "csv")
+		if err := writeSKUCSV(filename, results, options.CSVDelimiter); err != nil {
+			return err
+		}
+	}
+
+	if options.CSVStreamed {
+		return nil
+	}
+	if !options.CSVPerStatus {
+		filename := options.Artifacts.Path("results", "csv")
+		return writeCSVFile(filename, columns, results, options.CSVDelimiter)
+	}
+
+	// One file per status group
+	groups := []struct {
+		suffix string
+		match  func(result APIResult) bool
+	}{
+		{"enabled", func(r APIResult) bool { return r.Error == "" && r.Enabled }},
+		{"disabled", func(r APIResult) bool { return r.Error == "" && !r.Enabled }},
+		{"errors", func(r APIResult) bool { return r.Error != "" }},
+	}
+
+	for _, group := range groups {
+		var groupResults []APIResult
+		for _, result := range results {
+			if group.match(result) {
+				groupResults = append(groupResults, result)
+			}
+		}
+
+		filename := options.Artifacts.Path("results_"+group.suffix, "csv")
+		if err := writeCSVFile(filename, columns, groupResults, options.CSVDelimiter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSVFile writes the selected columns for the given results to a CSV file
+func writeCSVFile(filename string, columns []csvColumn, results []APIResult, delimiter rune) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create CSV file: %v", err)
@@ -49,21 +262,14 @@ func exportToCSV(report *Report, results []APIResult, options ExportOptions) err
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
 
 	// Write header
-	header := []string{
-		"API Name",
-		"Display Name",
-		"Status",
-		"Enabled",
-		"Has Pricing",
-		"Unlimited Cost",
-		"Estimated Cost (USD)",
-		"Currency",
-		"Pricing Details",
-		"Checked At",
-		"Error",
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Header
 	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %v", err)
@@ -71,66 +277,186 @@ func exportToCSV(report *Report, results []APIResult, options ExportOptions) err
 
 	// Write data rows
 	for _, result := range results {
-		row := []string{
-			result.Name,
-			result.DisplayName,
-			result.Status,
-			strconv.FormatBool(result.Enabled),
-			strconv.FormatBool(result.CostInfo.HasPricing),
-			strconv.FormatBool(result.CostInfo.UnlimitedCost),
-			fmt.Sprintf("%.2f", result.CostInfo.EstimatedCost),
-			result.CostInfo.Currency,
-			result.CostInfo.PricingDetails,
-			result.CheckedAt.Format("2006-01-02 15:04:05"),
-			result.Error,
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.Value(result)
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write CSV row: %v", err)
 		}
 	}
 
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %v", err)
+	}
+
 	fmt.Printf("✅ CSV exported to: %s\n", filename)
 	return nil
 }
 
 // exportToPDF exports results to PDF format
 func exportToPDF(report *Report, results []APIResult, options ExportOptions) error {
-	filename := filepath.Join(options.OutputDir, fmt.Sprintf("google_api_checker_%s.pdf", time.Now().Format("20060102_150405")))
+	filename := options.Artifacts.Path("report", "pdf")
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
+	pdf := newPDF("P")
+	var toc []pdfTOCEntry
 
-	// Title
-	pdf.SetFont("Arial", "B", 16)
+	// Cover page with summary
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "B", 16)
 	pdf.Cell(190, 10, "Google API Checker Report")
 	pdf.Ln(15)
 
-	// Summary section
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(190, 8, "Summary")
-	pdf.Ln(10)
+	toc = append(toc, addPDFSection(pdf, "Summary"))
 
-	pdf.SetFont("Arial", "", 10)
+	pdf.SetFont(pdfFont, "", 10)
 	pdf.Cell(95, 6, fmt.Sprintf("Total APIs checked: %d", report.Summary.TotalAPIs))
 	pdf.Cell(95, 6, fmt.Sprintf("Enabled APIs: %d", report.Summary.EnabledCount))
 	pdf.Ln(6)
 	pdf.Cell(95, 6, fmt.Sprintf("Disabled APIs: %d", report.Summary.DisabledCount))
 	pdf.Cell(95, 6, fmt.Sprintf("Errors: %d", report.Summary.ErrorCount))
 	pdf.Ln(6)
-	pdf.Cell(95, 6, fmt.Sprintf("Total estimated cost: $%.2f %s", report.Summary.TotalCost, report.Summary.Currency))
-	pdf.Ln(15)
+	if report.Summary.UnknownCount > 0 {
+		pdf.Cell(95, 6, fmt.Sprintf("Undetermined (no project): %d", report.Summary.UnknownCount))
+		pdf.Ln(6)
+	}
+	if report.Summary.SkippedCount > 0 {
+		pdf.Cell(95, 6, fmt.Sprintf("Skipped (quota exhausted): %d", report.Summary.SkippedCount))
+		pdf.Ln(6)
+	}
+	if len(report.Tags) > 0 {
+		pdf.Cell(190, 6, "Tags: "+formatTags(report.Tags))
+		pdf.Ln(6)
+	}
+	if report.Summary.Keyless {
+		pdf.Cell(190, 6, keylessNotice)
+		pdf.Ln(6)
+	}
+	if notice := skippedNotice(report); notice != "" {
+		pdf.Cell(190, 6, notice)
+		pdf.Ln(6)
+	}
+	pdf.Cell(95, 6, fmt.Sprintf("Total estimated cost: $%.2f %s%s", projection.amount(report.Summary.TotalCost), report.Summary.Currency, projection.Suffix))
+	if report.Summary.PotentialSavings > 0 {
+		pdf.Cell(95, 6, fmt.Sprintf("Potential savings: $%.2f %s%s", projection.amount(report.Summary.PotentialSavings), report.Summary.Currency, projection.Suffix))
+	}
+	pdf.Ln(12)
+
+	// Grades are shown large on the cover so they can be compared across scans
+	for _, score := range report.Scores {
+		pdf.SetFont(pdfFont, "B", 14)
+		pdf.Cell(190, 8, pdfText(fmt.Sprintf("%s: %s (%d/100)", score.Label(), score.Grade, score.Score)))
+		pdf.Ln(8)
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.Cell(190, 6, fmt.Sprintf("Security %d  ·  Cost hygiene %d  ·  Reliability %d", score.Security, score.CostHygiene, score.Reliability))
+		pdf.Ln(8)
+	}
+	pdf.Ln(7)
+
+	// Reserve a page for the table of contents, filled in once page numbers are known
+	pdf.AddPage()
+	tocPage := pdf.PageNo()
+
+	pdf.AddPage()
+
+	// Project findings section
+	if len(report.Findings) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Project Findings (%d)", len(report.Findings))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, finding := range report.Findings {
+			pdf.MultiCell(190, 6, pdfText(fmt.Sprintf("• [%s] %s", strings.ToUpper(finding.Severity), finding)), "", "", false)
+			if len(finding.Recipients) > 0 {
+				pdf.MultiCell(190, 6, "    → "+pdfText(strings.Join(finding.Recipients, ", ")), "", "", false)
+			}
+		}
+		pdf.Ln(10)
+	}
+
+	// Compliance section
+	if len(report.Compliance) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("CIS Benchmark Controls Failed (%d)", len(report.Compliance))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.MultiCell(190, 6, pdfText(cisBenchmark), "", "", false)
+		for _, finding := range report.Compliance {
+			pdf.MultiCell(190, 6, pdfText("• "+finding.String()), "", "", false)
+		}
+		pdf.Ln(10)
+	}
+
+	// Owners and contacts section
+	if len(report.Contacts) > 0 {
+		toc = append(toc, addPDFSection(pdf, "Owners & Contacts"))
+
+		for _, project := range report.Contacts {
+			pdf.SetFont(pdfFont, "B", 10)
+			pdf.Cell(190, 6, pdfText(project.ProjectID))
+			pdf.Ln(6)
+			pdf.SetFont(pdfFont, "", 10)
+			pdf.MultiCell(190, 6, "    "+pdfText(project.String()), "", "", false)
+			pdf.Ln(2)
+		}
+		pdf.Ln(10)
+	}
+
+	// Cost anomalies section
+	if len(report.CostAnomalies) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Cost Anomalies Since Previous Scan (%d)", len(report.CostAnomalies))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, anomaly := range report.CostAnomalies {
+			pdf.MultiCell(190, 6, pdfText("• "+anomaly.String()), "", "", false)
+		}
+		pdf.Ln(10)
+	}
+
+	// Abuse risk section
+	if len(report.RiskScoredAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Maps Platform Abuse Risk (%d)", len(report.RiskScoredAPIs))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, api := range report.RiskScoredAPIs {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s: %d/100 (%s)", api.DisplayName, *api.RiskScore, riskLevel(*api.RiskScore))))
+			pdf.Ln(6)
+			pdf.MultiCell(190, 6, "    "+pdfText(strings.Join(api.RiskFactors, ", ")), "", "", false)
+			pdf.Ln(2)
+		}
+		pdf.Ln(10)
+	}
+
+	// Policy violations section
+	if len(report.PolicyViolations) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Policy Violations (%d)", len(report.PolicyViolations))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, violation := range report.PolicyViolations {
+			pdf.MultiCell(190, 6, pdfText("• "+violationText(violation)), "", "", false)
+		}
+		pdf.Ln(10)
+	}
+
+	// Drift section
+	if len(report.Drift) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Drift from Expected State (%d)", len(report.Drift))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, drift := range report.Drift {
+			pdf.MultiCell(190, 6, pdfText("• "+drift.String()), "", "", false)
+		}
+		pdf.Ln(10)
+	}
 
 	// Unlimited cost APIs section
 	if len(report.CostAnalysis.UnlimitedCostAPIs) > 0 {
-		pdf.SetFont("Arial", "B", 12)
-		pdf.Cell(190, 8, fmt.Sprintf("⚠️ Unlimited Cost APIs (%d)", len(report.CostAnalysis.UnlimitedCostAPIs)))
-		pdf.Ln(10)
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("⚠ Unlimited Cost APIs (%d)", len(report.CostAnalysis.UnlimitedCostAPIs))))
 
-		pdf.SetFont("Arial", "", 10)
+		pdf.SetFont(pdfFont, "", 10)
 		for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
-			pdf.Cell(190, 6, fmt.Sprintf("• %s", api.DisplayName))
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s", api.DisplayName)))
 			pdf.Ln(6)
-			pdf.Cell(190, 6, fmt.Sprintf("  %s", api.CostInfo.PricingDetails))
+			pdf.Cell(190, 6, "    "+pdfText(api.CostInfo.PricingDetails))
 			pdf.Ln(8)
 		}
 		pdf.Ln(10)
@@ -138,93 +464,154 @@ func exportToPDF(report *Report, results []APIResult, options ExportOptions) err
 
 	// High cost APIs section
 	if len(report.CostAnalysis.HighCostAPIs) > 0 {
-		pdf.SetFont("Arial", "B", 12)
-		pdf.Cell(190, 8, fmt.Sprintf("💰 High Cost APIs (%d)", len(report.CostAnalysis.HighCostAPIs)))
-		pdf.Ln(10)
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("High Cost APIs (%d, >%s)", len(report.CostAnalysis.HighCostAPIs), formatCost(report.CostAnalysis.HighCostThreshold))))
 
-		pdf.SetFont("Arial", "", 10)
+		pdf.SetFont(pdfFont, "", 10)
 		for _, api := range report.CostAnalysis.HighCostAPIs {
-			pdf.Cell(190, 6, fmt.Sprintf("• %s: $%.2f/month", api.DisplayName, api.CostInfo.EstimatedCost))
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s: %s", api.DisplayName, formatCost(api.CostInfo.EstimatedCost))))
 			pdf.Ln(6)
 		}
 		pdf.Ln(10)
 	}
 
-	// Recommendations section
-	if len(report.Recommendations) > 0 {
-		pdf.SetFont("Arial", "B", 12)
-		pdf.Cell(190, 8, "💡 Recommendations")
-		pdf.Ln(10)
+	// Active incidents section
+	if len(report.IncidentAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Active Incidents (%d)", len(report.IncidentAPIs))))
 
-		pdf.SetFont("Arial", "", 10)
-		for _, rec := range report.Recommendations {
-			pdf.Cell(190, 6, fmt.Sprintf("• %s", rec))
+		pdf.SetFont(pdfFont, "", 10)
+		for _, api := range report.IncidentAPIs {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s", incidentAPILabel(api))))
 			pdf.Ln(6)
+			for _, incident := range api.Incidents {
+				pdf.MultiCell(190, 6, "    "+pdfText(incident.String()), "", "", false)
+			}
+			pdf.Ln(2)
 		}
 		pdf.Ln(10)
 	}
 
-	// Detailed results table
-	pdf.AddPage()
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(190, 8, "Detailed API Results")
-	pdf.Ln(10)
+	// Common abuse targets section
+	if len(report.HighRiskAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Common Abuse Targets (%d)", len(report.HighRiskAPIs))))
 
-	// Table header
-	pdf.SetFont("Arial", "B", 8)
-	headers := []string{"API Name", "Status", "Enabled", "Cost", "Unlimited"}
-	widths := []float64{60, 25, 20, 25, 25}
+		pdf.SetFont(pdfFont, "", 10)
+		for _, api := range report.HighRiskAPIs {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s", api.DisplayName)))
+			pdf.Ln(6)
+			pdf.MultiCell(190, 6, "    "+pdfText(api.RiskNote), "", "", false)
+			pdf.Ln(2)
+		}
+		pdf.Ln(10)
+	}
 
-	for i, header := range headers {
-		pdf.CellFormat(widths[i], 6, header, "1", 0, "", false, 0, "")
+	// Estimated vs actual section
+	if len(report.CostAnalysis.ReconciledAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, "Estimated vs Actual (Last Month)"))
+
+		pdf.SetFont(pdfFont, "B", 10)
+		pdf.CellFormat(100, 7, "API", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 7, "Estimated", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 7, "Actual", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 7, "Difference", "1", 1, "R", false, 0, "")
+
+		pdf.SetFont(pdfFont, "", 9)
+		for _, api := range report.CostAnalysis.ReconciledAPIs {
+			pdf.CellFormat(100, 6, pdfText(truncate(api.DisplayName, 55)), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", api.CostInfo.EstimatedCost), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", *api.CostInfo.ActualCost), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 6, fmt.Sprintf("%+.2f", reconciliationGap(api)), "1", 1, "R", false, 0, "")
+		}
+		pdf.Ln(10)
 	}
-	pdf.Ln(-1)
 
-	// Table data
-	pdf.SetFont("Arial", "", 8)
-	for _, result := range results {
-		if pdf.GetY() > 250 { // Check if we need a new page
-			pdf.AddPage()
-			// Repeat header
-			pdf.SetFont("Arial", "B", 8)
-			for i, header := range headers {
-				pdf.CellFormat(widths[i], 6, header, "1", 0, "", false, 0, "")
-			}
-			pdf.Ln(-1)
-			pdf.SetFont("Arial", "", 8)
+	// Cost allocation section
+	if len(report.CostAnalysis.CostAllocation) > 0 {
+		toc = append(toc, addPDFSection(pdf, "Billed Cost by Label (Last Month)"))
+
+		pdf.SetFont(pdfFont, "B", 10)
+		pdf.CellFormat(130, 7, "Label", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 7, "APIs", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 7, "Actual", "1", 1, "R", false, 0, "")
+
+		pdf.SetFont(pdfFont, "", 9)
+		for _, entry := range report.CostAnalysis.CostAllocation {
+			pdf.CellFormat(130, 6, pdfText(truncate(entry.Label+"="+entry.Value, 70)), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(30, 6, strconv.Itoa(len(entry.APIs)), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 6, fmt.Sprintf("$%.2f", entry.Cost), "1", 1, "R", false, 0, "")
 		}
+		pdf.Ln(10)
+	}
 
-		// Truncate long names
-		apiName := result.DisplayName
-		if len(apiName) > 25 {
-			apiName = apiName[:22] + "..."
+	// Free tier section
+	if len(report.CostAnalysis.FreeTierAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Covered by Free Tier (%d)", len(report.CostAnalysis.FreeTierAPIs))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, api := range report.CostAnalysis.FreeTierAPIs {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s: %s %s/month", api.DisplayName, formatQuantity(api.CostInfo.ExpectedUsage), api.CostInfo.UsageUnit)))
+			pdf.Ln(6)
 		}
+		pdf.Ln(10)
+	}
 
-		enabled := "No"
-		if result.Enabled {
-			enabled = "Yes"
+	// Enabled but unused APIs section
+	if len(report.UnusedAPIs) > 0 {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Enabled but Unused APIs (%d)", len(report.UnusedAPIs))))
+
+		pdf.SetFont(pdfFont, "", 10)
+		for _, api := range report.UnusedAPIs {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s (%s)", api.DisplayName, api.Name)))
+			pdf.Ln(6)
 		}
+		pdf.Ln(10)
+	}
 
-		unlimited := "No"
-		if result.CostInfo.UnlimitedCost {
-			unlimited = "Yes"
+	// Performance section
+	if perf := report.Performance; perf != nil {
+		toc = append(toc, addPDFSection(pdf, fmt.Sprintf("Performance (%d checks)", perf.Checks)))
+
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.Cell(190, 6, pdfText(fmt.Sprintf("p50 %dms, p95 %dms, max %dms, %d throttled (HTTP 429)", perf.P50Ms, perf.P95Ms, perf.MaxMs, perf.Throttled)))
+		pdf.Ln(6)
+		for _, api := range perf.Slowest {
+			pdf.Cell(190, 6, pdfText(fmt.Sprintf("• %s: %dms", api.Name, api.DurationMs)))
+			pdf.Ln(6)
 		}
+		pdf.Ln(10)
+	}
 
-		cost := fmt.Sprintf("$%.2f", result.CostInfo.EstimatedCost)
+	// Recommendations section
+	if len(report.Recommendations) > 0 {
+		toc = append(toc, addPDFSection(pdf, "Recommendations"))
 
-		row := []string{apiName, result.Status, enabled, cost, unlimited}
-		for i, cell := range row {
-			pdf.CellFormat(widths[i], 6, cell, "1", 0, "", false, 0, "")
+		pdf.SetFont(pdfFont, "", 10)
+		for _, rec := range report.Recommendations {
+			pdf.MultiCell(190, 6, pdfText(fmt.Sprintf("• %s", strings.TrimSpace(rec))), "", "", false)
 		}
-		pdf.Ln(-1)
+		pdf.Ln(10)
 	}
 
-	// Footer
-	pdf.SetY(-20)
-	pdf.SetFont("Arial", "I", 8)
-	pdf.Cell(190, 6, fmt.Sprintf("Report generated at: %s", report.GeneratedAt.Format("2006-01-02 15:04:05")))
-	pdf.Ln(6)
-	pdf.Cell(190, 6, "Generated by Google API Checker")
+	// Cost breakdown chart
+	if len(report.CostAnalysis.CostBreakdown) > 0 {
+		pdf.AddPage()
+		toc = append(toc, addPDFSection(pdf, "Cost Breakdown"))
+		drawCostChart(pdf, report.CostAnalysis.CostBreakdown)
+	}
+
+	// Detailed results table
+	if options.Landscape {
+		pdf.AddPageFormat("L", pdf.GetPageSizeStr("A4"))
+	} else {
+		pdf.AddPage()
+	}
+	toc = append(toc, addPDFSection(pdf, "Detailed API Results"))
+	writePDFResultsTable(pdf, results, options.Landscape)
+
+	// Fill in the table of contents now that section pages are known
+	lastPage := pdf.PageNo()
+	pdf.SetPage(tocPage)
+	writePDFTableOfContents(pdf, toc)
+	pdf.SetPage(lastPage)
 
 	if err := pdf.OutputFileAndClose(filename); err != nil {
 		return fmt.Errorf("failed to save PDF: %v", err)
@@ -234,50 +621,56 @@ func exportToPDF(report *Report, results []APIResult, options ExportOptions) err
 	return nil
 }
 
-// ExportSummary exports a summary report
-func ExportSummary(report *Report, options ExportOptions) error {
-	filename := filepath.Join(options.OutputDir, fmt.Sprintf("summary_%s.txt", time.Now().Format("20060102_150405")))
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create summary file: %v", err)
+// writePDFResultsTable renders the per-API table, adding the wider columns in landscape mode
+func writePDFResultsTable(pdf *gofpdf.Fpdf, results []APIResult, landscape bool) {
+	orientation := "P"
+	costHeader := "Cost" + projection.Suffix
+	headers := []string{"API Name", "Status", "Enabled", costHeader, "Tier", "Unlimited"}
+	widths := []float64{55, 22, 18, 25, 22, 20}
+	if landscape {
+		orientation = "L"
+		headers = []string{"API Name", "Status", "Enabled", costHeader, "Tier", "Unlimited", "Pricing Details", "Checked At"}
+		widths = []float64{55, 22, 16, 20, 18, 18, 93, 35}
 	}
-	defer file.Close()
 
-	// Write summary
-	fmt.Fprintf(file, "Google API Checker Summary Report\n")
-	fmt.Fprintf(file, "Generated: %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	writeHeader := func() {
+		pdf.SetFont(pdfFont, "B", 8)
+		writePDFTableRow(pdf, widths, headers, 5)
+		pdf.SetFont(pdfFont, "", 8)
+	}
+	writeHeader()
 
-	fmt.Fprintf(file, "SUMMARY:\n")
-	fmt.Fprintf(file, "  Total APIs: %d\n", report.Summary.TotalAPIs)
-	fmt.Fprintf(file, "  Enabled: %d\n", report.Summary.EnabledCount)
-	fmt.Fprintf(file, "  Disabled: %d\n", report.Summary.DisabledCount)
-	fmt.Fprintf(file, "  Errors: %d\n", report.Summary.ErrorCount)
-	fmt.Fprintf(file, "  Total Cost: $%.2f %s\n\n", report.Summary.TotalCost, report.Summary.Currency)
+	for _, result := range results {
+		enabled := "No"
+		if result.Enabled {
+			enabled = "Yes"
+		}
 
-	if len(report.CostAnalysis.UnlimitedCostAPIs) > 0 {
-		fmt.Fprintf(file, "UNLIMITED COST APIS (%d):\n", len(report.CostAnalysis.UnlimitedCostAPIs))
-		for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
-			fmt.Fprintf(file, "  • %s\n", api.DisplayName)
+		unlimited := "No"
+		if result.CostInfo.UnlimitedCost {
+			unlimited = "Yes"
 		}
-		fmt.Fprintf(file, "\n")
-	}
 
-	if len(report.CostAnalysis.HighCostAPIs) > 0 {
-		fmt.Fprintf(file, "HIGH COST APIS (%d):\n", len(report.CostAnalysis.HighCostAPIs))
-		for _, api := range report.CostAnalysis.HighCostAPIs {
-			fmt.Fprintf(file, "  • %s: $%.2f/month\n", api.DisplayName, api.CostInfo.EstimatedCost)
+		cost := fmt.Sprintf("$%.2f", projection.amount(result.CostInfo.EstimatedCost))
+
+		row := []string{pdfText(result.DisplayName), statusBadge(result.Status), enabled, cost, result.CostTier, unlimited}
+		// The cost and its tier are drawn in the tier's color, as in the console and HTML report
+		var colors map[int][3]int
+		if style, ok := costTierStyles[result.CostTier]; ok {
+			colors = map[int][3]int{3: style.RGB, 4: style.RGB}
+		}
+		if landscape {
+			details := result.CostInfo.PricingDetails
+			if result.Error != "" {
+				details = result.Error
+			}
+			row = append(row, pdfText(details), result.CheckedAt.Format("2006-01-02 15:04:05"))
 		}
-		fmt.Fprintf(file, "\n")
-	}
 
-	if len(report.Recommendations) > 0 {
-		fmt.Fprintf(file, "RECOMMENDATIONS:\n")
-		for _, rec := range report.Recommendations {
-			fmt.Fprintf(file, "  • %s\n", rec)
+		if !pdfRowFits(pdf, widths, row, 5) {
+			pdf.AddPageFormat(orientation, pdf.GetPageSizeStr("A4"))
+			writeHeader()
 		}
+		writePDFTableRowColored(pdf, widths, row, 5, colors)
 	}
-
-	fmt.Printf("✅ Summary exported to: %s\n", filename)
-	return nil
 }
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// exprTestVars are the variables the expression tests evaluate against
+var exprTestVars = map[string]interface{}{
+	"cost":     150.0,
+	"name":     "vision.googleapis.com",
+	"category": "AI",
+	"enabled":  true,
+	"requests": nil,
+}
+
+// exprTestKnown are the identifiers the expression tests may use
+var exprTestKnown = map[string]bool{"cost": true, "name": true, "category": true, "enabled": true, "requests": true}
+
+func TestExprEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		// Precedence and associativity
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"10 - 4 - 3", 3.0},
+		{"12 / 2 / 3", 2.0},
+		{"-2 * 3", -6.0},
+		{"--2", 2.0},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!false && true", true},
+		{"!(cost > 100)", false},
+		{"cost > 100 && category == \"AI\" || false", true},
+		{"1 + 1 == 2", true},
+
+		// Numbers
+		{"cost > 100", true},
+		{"cost >= 150", true},
+		{"cost < 150", false},
+		{"cost <= 150.0", true},
+		{"cost == 150", true},
+		{"cost != 150", false},
+		{".5 + .5", 1.0},
+
+		// Strings
+		{"category == 'AI'", true},
+		{"category != \"Maps\"", true},
+		{"\"abc\" < \"abd\"", true},
+		{"\"b\" > \"a\"", true},
+		{"\"it\\'s\" == \"it's\"", true},
+		{"\"vision\" + \".googleapis.com\" == name", true},
+
+		// Mixed types are unequal rather than an error
+		{"cost == \"150\"", false},
+		{"enabled == true", true},
+
+		// null
+		{"requests == null", true},
+		{"requests != null", false},
+		{"requests > 0", false},
+		{"requests < 0", false},
+		{"requests + 1", nil},
+
+		// in and =~
+		{"category in [\"AI\", \"Maps\"]", true},
+		{"category in [\"Maps\"]", false},
+		{"cost in [100, 150]", true},
+		{"category in []", false},
+		{"name =~ \"^vision\\\\.\"", true},
+		{"name =~ '^maps'", false},
+		{"cost =~ '150'", false},
+		{"requests =~ '.*'", false},
+		{"[1] == [1]", false},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			compiled, err := compileExpr(test.source, exprTestKnown)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			got, err := compiled.Eval(exprTestVars)
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("got %v (%s), want %v", got, exprTypeName(got), test.want)
+			}
+		})
+	}
+}
+
+func TestExprCompileErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"", "unexpected end of expression"},
+		{"cost >", "unexpected end of expression"},
+		{"(cost > 1", "missing )"},
+		{"cost > 1)", "unexpected \")\""},
+		{"[1, 2", "missing ] or ,"},
+		{"[1 2]", "missing ] or ,"},
+		{"\"open", "unterminated string"},
+		{"cost # 1", "unexpected character"},
+		{"1.2.3", "invalid number"},
+		{"name =~ cost", "=~ needs a quoted regular expression"},
+		{"name =~ \"(\"", "invalid regular expression"},
+		{"spend > 1", "unknown variable \"spend\""},
+		{"cost > 1 && owner == 'me'", "unknown variable \"owner\""},
+		{"cost cost", "unexpected \"cost\""},
+		{"* 2", "unexpected \"*\""},
+		{"in", "unknown variable \"in\""},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			_, err := compileExpr(test.source, exprTestKnown)
+			if err == nil {
+				t.Fatalf("compiled without an error")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestExprEvalErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"cost > 'a'", "cannot compare a number with a string"},
+		{"cost && true", "&& needs booleans, got a number"},
+		{"true || cost", ""},
+		{"false || cost", "|| needs booleans, got a number"},
+		{"!cost", "! needs a boolean, got a number"},
+		{"-name", "- needs a number, got a string"},
+		{"cost * 'x'", "* needs numbers, got a number and a string"},
+		{"cost / 0", "division by zero"},
+		{"category in 'AI'", "in needs a list, got a string"},
+		{"[cost / 0]", "division by zero"},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			compiled, err := compileExpr(test.source, exprTestKnown)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			_, err = compiled.Eval(exprTestVars)
+			switch {
+			case test.want == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+				t.Errorf("error %v, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestExprEvalBool(t *testing.T) {
+	compiled, err := compileExpr("cost + 1", exprTestKnown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compiled.EvalBool(exprTestVars); err == nil || !strings.Contains(err.Error(), "not a boolean") {
+		t.Errorf("EvalBool of a number: error %v", err)
+	}
+
+	// Without a list of known variables any identifier compiles and missing ones are null
+	compiled, err = compileExpr("anything == null", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := compiled.EvalBool(nil); err != nil || !ok {
+		t.Errorf("got %v, %v; want true", ok, err)
+	}
+}
+
+// TestExprMalformedNoPanic compiles and evaluates every prefix and mangled form of valid
+// expressions; malformed input must fail with an error, never a panic
+func TestExprMalformedNoPanic(t *testing.T) {
+	sources := []string{
+		"cost > 100 && category in ['AI', \"Maps\"] || !(name =~ '^maps') && -cost < 2 * (1 + 3) / 4",
+		"[[1, [2]], []] == [] || \"a\\\"b\" + 'c' != null",
+	}
+	junk := []string{"", "(", ")", "[", "]", ",", "!", "-", "=~", "in", "'", "\"", "\\", "&&", "||", ".", "1..2", "é", "\x00"}
+	for _, source := range sources {
+		runes := []rune(source)
+		for i := 0; i <= len(runes); i++ {
+			for _, insert := range junk {
+				for _, candidate := range []string{
+					string(runes[:i]),
+					string(runes[i:]),
+					string(runes[:i]) + insert + string(runes[i:]),
+				} {
+					func() {
+						defer func() {
+							if r := recover(); r != nil {
+								t.Fatalf("%q panicked: %v", candidate, r)
+							}
+						}()
+						compiled, err := compileExpr(candidate, nil)
+						if err != nil {
+							return
+						}
+						compiled.Eval(exprTestVars)
+					}()
+				}
+			}
+		}
+	}
+}
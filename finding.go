@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Finding is a project-level issue found during a scan, as opposed to a per-API result
+type Finding struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Severity  string `json:"severity"`
+	Category  string `json:"category"`
+	Message   string `json:"message"`
+	// Recipients are the project's contacts for the finding's category, set with --contacts
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// String formats the finding with its project
+func (f Finding) String() string {
+	if f.ProjectID == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("[%s] %s", f.ProjectID, f.Message)
+}
+
+// severityRank orders findings from most to least severe
+var severityRank = map[string]int{"high": 0, "medium": 1, "low": 2, "info": 3}
+
+// addFinding records a project-level finding; it is safe for concurrent use
+func (c *GoogleAPIChecker) addFinding(severity, category, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.findings = append(c.findings, Finding{
+		ProjectID: c.projectID,
+		Severity:  severity,
+		Category:  category,
+		Message:   message,
+	})
+}
+
+// Findings returns the project-level findings recorded so far
+func (c *GoogleAPIChecker) Findings() []Finding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Finding(nil), c.findings...)
+}
+
+// SetFindings records project-level findings on the report and recommends fixing the severe ones first
+func (r *Report) SetFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	r.Findings = findings
+
+	var recommendations []string
+	for _, finding := range findings {
+		if finding.Severity == "high" || finding.Severity == "medium" {
+			recommendations = append(recommendations, fmt.Sprintf("🧾 %s: %s", finding.Category, finding))
+		}
+	}
+	r.Recommendations = append(recommendations, r.Recommendations...)
+}
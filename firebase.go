@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// FirebaseProject is the subset of the Firebase Management API project we report on
+type FirebaseProject struct {
+	ProjectID   string `json:"projectId"`
+	DisplayName string `json:"displayName"`
+	State       string `json:"state"`
+	Resources   struct {
+		HostingSite              string `json:"hostingSite"`
+		RealtimeDatabaseInstance string `json:"realtimeDatabaseInstance"`
+		StorageBucket            string `json:"storageBucket"`
+		LocationID               string `json:"locationId"`
+	} `json:"resources"`
+}
+
+// FirebaseApp is a registered web, Android or iOS app
+type FirebaseApp struct {
+	AppID       string `json:"appId"`
+	DisplayName string `json:"displayName"`
+	Platform    string `json:"platform"`
+	State       string `json:"state"`
+}
+
+// inspectFirebaseProject prints the project's Firebase configuration and registered apps
+func (c *GoogleAPIChecker) inspectFirebaseProject() error {
+	if c.projectID == "" {
+		return fmt.Errorf("firebase inspection requires a project ID")
+	}
+
+	project, err := c.getFirebaseProject()
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		fmt.Printf("🔥 Firebase is not set up for project %s\n", c.projectID)
+		return nil
+	}
+
+	fmt.Printf("🔥 Firebase project: %s (%s)\n", project.DisplayName, project.State)
+	printFirebaseResource("Hosting site", project.Resources.HostingSite)
+	printFirebaseResource("Realtime Database", project.Resources.RealtimeDatabaseInstance)
+	printFirebaseResource("Storage bucket", project.Resources.StorageBucket)
+	printFirebaseResource("Default location", project.Resources.LocationID)
+
+	apps, err := c.getFirebaseApps()
+	if err != nil {
+		return err
+	}
+
+	platforms := make(map[string]int)
+	for _, app := range apps {
+		platforms[app.Platform]++
+	}
+	fmt.Printf("   Apps: %d (web %d, Android %d, iOS %d)\n", len(apps), platforms["WEB"], platforms["ANDROID"], platforms["IOS"])
+
+	return nil
+}
+
+// printFirebaseResource prints a default resource, noting when it is not provisioned
+func printFirebaseResource(label, value string) {
+	if value == "" {
+		value = "not provisioned"
+	}
+	fmt.Printf("   %s: %s\n", label, value)
+}
+
+// getFirebaseProject fetches the Firebase project; it returns nil when Firebase is not added to the project
+func (c *GoogleAPIChecker) getFirebaseProject() (*FirebaseProject, error) {
+	requestURL := fmt.Sprintf("https://firebase.googleapis.com/v1beta1/projects/%s", c.projectID)
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Add("X-Goog-Api-Key", c.token)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Firebase project: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get Firebase project, status: %d", resp.StatusCode)
+	}
+
+	var project FirebaseProject
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return nil, fmt.Errorf("failed to parse Firebase project response: %v", err)
+	}
+
+	return &project, nil
+}
+
+// getFirebaseApps lists all apps registered in the Firebase project
+func (c *GoogleAPIChecker) getFirebaseApps() ([]FirebaseApp, error) {
+	var apps []FirebaseApp
+	pageToken := ""
+	for {
+		requestURL := fmt.Sprintf("https://firebase.googleapis.com/v1beta1/projects/%s:searchApps", c.projectID)
+		if pageToken != "" {
+			requestURL += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		req, err := http.NewRequest("GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Add("X-Goog-Api-Key", c.token)
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Firebase apps: %v", err)
+		}
+
+		var result struct {
+			Apps          []FirebaseApp `json:"apps"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list Firebase apps, status: %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Firebase apps response: %v", err)
+		}
+
+		apps = append(apps, result.Apps...)
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return apps, nil
+}
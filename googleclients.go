@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	cloudbilling "google.golang.org/api/cloudbilling/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	serviceusage "google.golang.org/api/serviceusage/v1"
+)
+
+// billingAccountPageSize is how many projects to request per Cloud Billing page
+const billingAccountPageSize = 100
+
+// serviceUsageAPI is the part of the Service Usage API the checker calls; tests can stub it
+// through CheckerOptions.ServiceUsage
+type serviceUsageAPI interface {
+	ListServices(ctx context.Context, parent, filter string, pageSize int64, pageToken string) (*serviceusage.ListServicesResponse, error)
+	BatchGetServices(ctx context.Context, parent string, names []string) (*serviceusage.BatchGetServicesResponse, error)
+	GetService(ctx context.Context, name string) (*serviceusage.GoogleApiServiceusageV1Service, error)
+	BatchEnableServices(ctx context.Context, parent string, serviceIDs []string) (*serviceusage.Operation, error)
+}
+
+// cloudBillingAPI is the part of the Cloud Billing API the checker calls; tests can stub it
+// through CheckerOptions.CloudBilling
+type cloudBillingAPI interface {
+	GetBillingInfo(ctx context.Context, name string) (*cloudbilling.ProjectBillingInfo, error)
+	GetBillingAccount(ctx context.Context, name string) (*cloudbilling.BillingAccount, error)
+	ListAccountProjects(ctx context.Context, account, pageToken string) (*cloudbilling.ListProjectBillingInfoResponse, error)
+}
+
+// checkerTransport sends the typed clients' requests through the checker, so they get its
+// credential, rate limiter, endpoints, request counts and -vv tracing
+type checkerTransport struct {
+	checker *GoogleAPIChecker
+}
+
+// accessTokenKey carries an OAuth access token in a request context, for calls such as
+// services:batchEnable that do not accept API keys
+type accessTokenKey struct{}
+
+// withAccessToken makes the typed clients authorize calls made with the context by the OAuth
+// access token instead of the API key
+func withAccessToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, accessTokenKey{}, token)
+}
+
+func (t checkerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// doRequest rewrites the URL for --endpoint, which a RoundTripper must not do to its argument
+	req = req.Clone(req.Context())
+	if token, _ := req.Context().Value(accessTokenKey{}).(string); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if t.checker.token != "" {
+		req.Header.Set("X-Goog-Api-Key", t.checker.token)
+	}
+	return t.checker.doRequest(req)
+}
+
+// serviceUsageClient implements serviceUsageAPI with the generated Service Usage client
+type serviceUsageClient struct {
+	service *serviceusage.Service
+}
+
+func (s serviceUsageClient) ListServices(ctx context.Context, parent, filter string, pageSize int64, pageToken string) (*serviceusage.ListServicesResponse, error) {
+	call := s.service.Services.List(parent).PageSize(pageSize).Context(ctx)
+	if filter != "" {
+		call = call.Filter(filter)
+	}
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Do()
+}
+
+func (s serviceUsageClient) BatchGetServices(ctx context.Context, parent string, names []string) (*serviceusage.BatchGetServicesResponse, error) {
+	return s.service.Services.BatchGet(parent).Names(names...).Context(ctx).Do()
+}
+
+func (s serviceUsageClient) GetService(ctx context.Context, name string) (*serviceusage.GoogleApiServiceusageV1Service, error) {
+	return s.service.Services.Get(name).Context(ctx).Do()
+}
+
+func (s serviceUsageClient) BatchEnableServices(ctx context.Context, parent string, serviceIDs []string) (*serviceusage.Operation, error) {
+	request := &serviceusage.BatchEnableServicesRequest{ServiceIds: serviceIDs}
+	return s.service.Services.BatchEnable(parent, request).Context(ctx).Do()
+}
+
+// cloudBillingClient implements cloudBillingAPI with the generated Cloud Billing client
+type cloudBillingClient struct {
+	service *cloudbilling.APIService
+}
+
+func (b cloudBillingClient) GetBillingInfo(ctx context.Context, name string) (*cloudbilling.ProjectBillingInfo, error) {
+	return b.service.Projects.GetBillingInfo(name).Context(ctx).Do()
+}
+
+func (b cloudBillingClient) GetBillingAccount(ctx context.Context, name string) (*cloudbilling.BillingAccount, error) {
+	return b.service.BillingAccounts.Get(name).Context(ctx).Do()
+}
+
+func (b cloudBillingClient) ListAccountProjects(ctx context.Context, account, pageToken string) (*cloudbilling.ListProjectBillingInfoResponse, error) {
+	call := b.service.BillingAccounts.Projects.List(account).PageSize(billingAccountPageSize).Context(ctx)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Do()
+}
+
+// serviceUsage returns the Service Usage client, the stub in CheckerOptions.ServiceUsage when one is set
+func (c *GoogleAPIChecker) serviceUsage() (serviceUsageAPI, error) {
+	c.clientsOnce.Do(c.initClients)
+	return c.usageClient, c.clientsErr
+}
+
+// cloudBilling returns the Cloud Billing client, the stub in CheckerOptions.CloudBilling when one is set
+func (c *GoogleAPIChecker) cloudBilling() (cloudBillingAPI, error) {
+	c.clientsOnce.Do(c.initClients)
+	return c.billingClient, c.clientsErr
+}
+
+// initClients creates the typed clients on first use
+func (c *GoogleAPIChecker) initClients() {
+	c.usageClient, c.billingClient = c.options.ServiceUsage, c.options.CloudBilling
+	httpClient := option.WithHTTPClient(&http.Client{Transport: checkerTransport{c}})
+
+	if c.usageClient == nil {
+		service, err := serviceusage.NewService(context.Background(), httpClient)
+		if err != nil {
+			c.clientsErr = fmt.Errorf("failed to create the Service Usage client: %v", err)
+			return
+		}
+		c.usageClient = serviceUsageClient{service}
+	}
+	if c.billingClient == nil {
+		service, err := cloudbilling.NewService(context.Background(), httpClient)
+		if err != nil {
+			c.clientsErr = fmt.Errorf("failed to create the Cloud Billing client: %v", err)
+			return
+		}
+		c.billingClient = cloudBillingClient{service}
+	}
+}
+
+// clientError describes a failed typed client call the way sendJSON does
+func clientError(what string, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("failed to get %s, status: %d", what, apiErr.Code)
+	}
+	return fmt.Errorf("failed to get %s: %v", what, err)
+}
+
+// serviceName returns the service's API name, e.g. compute.googleapis.com
+func serviceName(service *serviceusage.GoogleApiServiceusageV1Service) string {
+	if service.Config != nil && service.Config.Name != "" {
+		return service.Config.Name
+	}
+	return path.Base(service.Name)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Console groupings for --group-by
+const (
+	groupByNone       = ""
+	groupByCategory   = "category"
+	groupByStatus     = "status"
+	groupByCostBucket = "cost-bucket"
+	groupByCostTier   = "cost-tier"
+)
+
+// ParseGroupBy validates a --group-by value
+func ParseGroupBy(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case groupByNone:
+		return groupByNone, nil
+	case groupByCategory:
+		return groupByCategory, nil
+	case groupByStatus:
+		return groupByStatus, nil
+	case groupByCostBucket:
+		return groupByCostBucket, nil
+	case groupByCostTier:
+		return groupByCostTier, nil
+	}
+	return "", fmt.Errorf("invalid --group-by %q (use category, status, cost-bucket or cost-tier)", value)
+}
+
+// apiGroup is a set of APIs printed together, with subtotals over its enabled APIs
+type apiGroup struct {
+	Name    string
+	APIs    []APIResult
+	Enabled int
+	Cost    float64
+	priced  bool // Whether an enabled API in the group has pricing, so Cost means something
+	rank    int  // Order of the group when not ordered by cost
+}
+
+// groupStatusOrder lists serving statuses first, then the rest
+var groupStatusOrder = []string{statusEnabled, statusDisabling, statusEnabling, statusDeprecated, statusDisabled, statusStateUnspecified, statusUnknown, statusSkipped}
+
+// costBuckets are the monthly cost ranges of --group-by cost-bucket, most expensive first
+var costBuckets = []float64{1000, 100, 10, 0}
+
+// costBucket returns the name and rank of the cost range an enabled API falls in
+func costBucket(api APIResult) (string, int) {
+	if !api.CostInfo.HasPricing {
+		return "No pricing", len(costBuckets) + 1
+	}
+	cost := api.CostInfo.EstimatedCost
+	if cost == 0 {
+		return "Free", len(costBuckets)
+	}
+	for i, floor := range costBuckets {
+		if cost < floor {
+			continue
+		}
+		switch {
+		case i == 0:
+			return fmt.Sprintf("$%.0f%s and more", projection.amount(floor), projection.Suffix), i
+		case floor == 0:
+			return fmt.Sprintf("Under $%.0f%s", projection.amount(costBuckets[i-1]), projection.Suffix), i
+		}
+		return fmt.Sprintf("$%.0f to $%.0f%s", projection.amount(floor), projection.amount(costBuckets[i-1]), projection.Suffix), i
+	}
+	return "Free", len(costBuckets)
+}
+
+// groupAPIs groups the report's APIs. Categories are ordered by cost, statuses and cost buckets by
+// their own order; cost buckets and tiers hold enabled APIs only. APIs are listed most expensive, then enabled, first.
+func groupAPIs(report *Report, by string) []apiGroup {
+	apis := report.EnabledAPIs
+	if by != groupByCostBucket && by != groupByCostTier {
+		apis = append(append(append(append([]APIResult(nil), report.EnabledAPIs...), report.DisabledAPIs...), report.UnknownAPIs...), report.SkippedAPIs...)
+	}
+
+	groups := make(map[string]*apiGroup)
+	for _, api := range apis {
+		name, rank := "", 0
+		switch by {
+		case groupByCategory:
+			name = getAPICategory(api.Name)
+		case groupByStatus:
+			name, rank = api.Status, len(groupStatusOrder)
+			for i, status := range groupStatusOrder {
+				if status == api.Status {
+					rank = i
+				}
+			}
+		case groupByCostBucket:
+			name, rank = costBucket(api)
+		case groupByCostTier:
+			name, rank = api.CostTier, len(costTierOrder)
+			for i, tier := range costTierOrder {
+				if tier == api.CostTier {
+					rank = i
+				}
+			}
+		}
+
+		group, ok := groups[name]
+		if !ok {
+			group = &apiGroup{Name: name, rank: rank}
+			groups[name] = group
+		}
+		group.APIs = append(group.APIs, api)
+		if api.Enabled {
+			group.Enabled++
+			if api.CostInfo.HasPricing {
+				group.Cost += api.CostInfo.EstimatedCost
+				group.priced = true
+			}
+		}
+	}
+
+	sorted := make([]apiGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.APIs, func(i, j int) bool {
+			if enabledCost(group.APIs[i]) != enabledCost(group.APIs[j]) {
+				return enabledCost(group.APIs[i]) > enabledCost(group.APIs[j])
+			}
+			if group.APIs[i].Enabled != group.APIs[j].Enabled {
+				return group.APIs[i].Enabled
+			}
+			return group.APIs[i].DisplayName < group.APIs[j].DisplayName
+		})
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if by == groupByCategory && sorted[i].Cost != sorted[j].Cost {
+			return sorted[i].Cost > sorted[j].Cost
+		}
+		if sorted[i].rank != sorted[j].rank {
+			return sorted[i].rank < sorted[j].rank
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// enabledCost is an API's estimated cost when it is enabled and priced, else 0
+func enabledCost(api APIResult) float64 {
+	if !api.Enabled || !api.CostInfo.HasPricing {
+		return 0
+	}
+	return api.CostInfo.EstimatedCost
+}
+
+// printAPIGroups prints the report's APIs grouped for --group-by, with a subtotal per group
+func printAPIGroups(report *Report, by string) {
+	const (
+		reset   = "\033[0m"
+		bold    = "\033[1m"
+		magenta = "\033[35m"
+	)
+
+	groups := groupAPIs(report, by)
+	if len(groups) == 0 {
+		return
+	}
+
+	projects := make(map[string]bool)
+	for _, group := range groups {
+		for _, api := range group.APIs {
+			projects[api.ProjectID] = true
+		}
+	}
+
+	title := map[string]string{groupByCategory: "CATEGORY", groupByStatus: "STATUS", groupByCostBucket: "COST (enabled APIs)", groupByCostTier: "COST TIER (enabled APIs)"}[by]
+	fmt.Fprintf(console, "\n"+bold+"🗂️  APIS BY %s (%d groups):"+reset+"\n", title, len(groups))
+	for _, group := range groups {
+		subtotal := ""
+		if group.priced {
+			subtotal = ", " + magenta + formatCost(group.Cost) + reset
+		}
+		fmt.Fprintf(console, "\n   "+bold+"%s"+reset+": %d APIs, %d enabled%s\n", group.Name, len(group.APIs), group.Enabled, subtotal)
+		// Members are listed without a header; the project column only shows for several projects
+		table := newConsoleTable(
+			tableColumn{},
+			tableColumn{Flexible: true, MinWidth: 12},
+			tableColumn{Flexible: true, MinWidth: 8, Drop: 1},
+			tableColumn{Right: true},
+		)
+		table.Indent = "     "
+		members, hidden := topAPIs(group.APIs)
+		for _, api := range members {
+			project, cost := "", ""
+			if len(projects) > 1 {
+				project = api.ProjectID
+			}
+			if api.Enabled && api.CostInfo.HasPricing {
+				cost = coloredCostTier(api.CostTier, formatCost(api.CostInfo.EstimatedCost))
+			}
+			table.AddRow(coloredStatus(api.Status), api.DisplayName, project, cost)
+		}
+		table.Render(console, consoleWidth())
+		printHidden("     ", hidden)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	scannerv1 "googleapichecker/proto/scanner/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// scanStates maps scan states to their gRPC enum values
+var scanStates = map[string]scannerv1.ScanState{
+	scanQueued:    scannerv1.ScanState_SCAN_STATE_QUEUED,
+	scanRunning:   scannerv1.ScanState_SCAN_STATE_RUNNING,
+	scanDone:      scannerv1.ScanState_SCAN_STATE_DONE,
+	scanFailed:    scannerv1.ScanState_SCAN_STATE_FAILED,
+	scanCancelled: scannerv1.ScanState_SCAN_STATE_CANCELLED,
+}
+
+// grpcScanService serves the ScanService gRPC API from the same scans as the REST API
+type grpcScanService struct {
+	scannerv1.UnimplementedScanServiceServer
+	server *ScanServer
+}
+
+// tenantKey is the context key for the tenant and role making a gRPC call
+type tenantKey struct{}
+
+// rpcClient is the tenant and role authenticated for a gRPC call
+type rpcClient struct {
+	tenant *serverTenant
+	role   role
+}
+
+// tenantStream passes the authenticated context to streaming handlers
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// NewGRPCServer creates a gRPC server for the scan server's scans, authenticated with the tenants' bearer tokens
+func NewGRPCServer(server *ScanServer) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			client, err := server.authenticateRPC(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(context.WithValue(ctx, tenantKey{}, client), req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			client, err := server.authenticateRPC(stream.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, tenantStream{stream, context.WithValue(stream.Context(), tenantKey{}, client)})
+		}),
+	)
+	scannerv1.RegisterScanServiceServer(grpcServer, &grpcScanService{server: server})
+	return grpcServer
+}
+
+// authenticateRPC finds the tenant and role from the bearer token in the call's authorization metadata
+func (s *ScanServer) authenticateRPC(ctx context.Context) (rpcClient, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			if tenant, granted := findAccess(ctx, s.tenants, token); tenant != nil {
+				return rpcClient{tenant, granted}, nil
+			}
+		}
+	}
+	return rpcClient{}, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+// rpcTenant returns the tenant authenticated for the call
+func rpcTenant(ctx context.Context) *serverTenant {
+	client, _ := ctx.Value(tenantKey{}).(rpcClient)
+	return client.tenant
+}
+
+// requireRPCRole fails the call when its role is below the required one
+func requireRPCRole(ctx context.Context, required role, action string) error {
+	client, _ := ctx.Value(tenantKey{}).(rpcClient)
+	if client.role < required {
+		return status.Errorf(codes.PermissionDenied, "role %s may not %s (needs %s)", client.role, action, required)
+	}
+	return nil
+}
+
+// StartScan queues a scan
+func (g *grpcScanService) StartScan(ctx context.Context, req *scannerv1.StartScanRequest) (*scannerv1.Scan, error) {
+	if err := requireRPCRole(ctx, roleOperator, "start scans"); err != nil {
+		return nil, err
+	}
+	options := req.GetOptions()
+	request := ScanRequest{
+		Projects:       req.Projects,
+		Profile:        req.Profile,
+		SkipCost:       options.GetSkipCost(),
+		AuditLogs:      options.GetAuditLogs(),
+		UsageMetrics:   options.GetUsageMetrics(),
+		RiskScore:      options.GetRiskScore(),
+		BillingCheck:   options.GetBillingCheck(),
+		Reconcile:      options.GetReconcile(),
+		Incidents:      options.GetIncidents(),
+		Contacts:       options.GetContacts(),
+		AssetInventory: options.GetAssetInventory(),
+		PublishMetrics: options.GetPublishMetrics(),
+		Checks:         req.Checks,
+		Tags:           req.Tags,
+	}
+	if err := request.validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tenant := rpcTenant(ctx)
+	if err := tenant.checkProjects(&request); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	scan, err := g.server.enqueue(tenant, request)
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	scanStatus, _ := scan.snapshot()
+	return scanToProto(scanStatus), nil
+}
+
+// StreamResults sends the scan's progress until it finishes, then its results and final state
+func (g *grpcScanService) StreamResults(req *scannerv1.StreamResultsRequest, stream scannerv1.ScanService_StreamResultsServer) error {
+	scan, ok := g.server.lookup(rpcTenant(stream.Context()), req.ScanId)
+	if !ok {
+		return status.Error(codes.NotFound, "scan not found")
+	}
+
+	for {
+		scanStatus, updated := scan.snapshot()
+		if scanStatus.finished() {
+			if scanStatus.Report != nil {
+				for _, results := range [][]APIResult{scanStatus.Report.EnabledAPIs, scanStatus.Report.DisabledAPIs, scanStatus.Report.UnknownAPIs} {
+					for _, result := range results {
+						if err := stream.Send(&scannerv1.ScanEvent{Event: &scannerv1.ScanEvent_Result{Result: apiResultToProto(result)}}); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return stream.Send(&scannerv1.ScanEvent{Event: &scannerv1.ScanEvent_Scan{Scan: scanToProto(scanStatus)}})
+		}
+
+		if err := stream.Send(&scannerv1.ScanEvent{Event: &scannerv1.ScanEvent_Scan{Scan: scanToProto(scanStatus)}}); err != nil {
+			return err
+		}
+		select {
+		case <-updated:
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// GetReport returns a finished scan's report
+func (g *grpcScanService) GetReport(ctx context.Context, req *scannerv1.GetReportRequest) (*scannerv1.GetReportResponse, error) {
+	scan, ok := g.server.lookup(rpcTenant(ctx), req.ScanId)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "scan not found")
+	}
+
+	scanStatus, _ := scan.snapshot()
+	if !scanStatus.finished() {
+		return nil, status.Errorf(codes.FailedPrecondition, "scan is %s", scanStatus.Status)
+	}
+
+	response := &scannerv1.GetReportResponse{Scan: scanToProto(scanStatus)}
+	report := scanStatus.Report
+	if report == nil {
+		return response, nil
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode report: %v", err)
+	}
+	response.ReportJson = reportJSON
+	response.Summary = &scannerv1.ReportSummary{
+		TotalApis:     int32(report.Summary.TotalAPIs),
+		EnabledCount:  int32(report.Summary.EnabledCount),
+		DisabledCount: int32(report.Summary.DisabledCount),
+		ErrorCount:    int32(report.Summary.ErrorCount),
+		TotalCost:     report.Summary.TotalCost,
+		Currency:      report.Summary.Currency,
+		CostSkipped:   report.Summary.CostSkipped,
+		ActualCost:    report.Summary.ActualCost,
+	}
+	for _, result := range report.EnabledAPIs {
+		response.EnabledApis = append(response.EnabledApis, apiResultToProto(result))
+	}
+	for _, result := range report.DisabledAPIs {
+		response.DisabledApis = append(response.DisabledApis, apiResultToProto(result))
+	}
+	response.Recommendations = report.Recommendations
+	return response, nil
+}
+
+// CancelScan cancels a queued scan or stops a running one
+func (g *grpcScanService) CancelScan(ctx context.Context, req *scannerv1.CancelScanRequest) (*scannerv1.Scan, error) {
+	if err := requireRPCRole(ctx, roleOperator, "cancel scans"); err != nil {
+		return nil, err
+	}
+	scan, ok := g.server.lookup(rpcTenant(ctx), req.ScanId)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "scan not found")
+	}
+	if err := scan.requestCancel(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	scanStatus, _ := scan.snapshot()
+	return scanToProto(scanStatus), nil
+}
+
+// scanToProto converts a scan's status to its gRPC message
+func scanToProto(scanStatus ScanStatus) *scannerv1.Scan {
+	scan := &scannerv1.Scan{
+		Id:         scanStatus.ID,
+		State:      scanStates[scanStatus.Status],
+		CreatedAt:  timestamppb.New(scanStatus.CreatedAt),
+		StartedAt:  timestampOrNil(scanStatus.StartedAt),
+		FinishedAt: timestampOrNil(scanStatus.FinishedAt),
+		Error:      scanStatus.Error,
+	}
+	for _, progress := range scanStatus.Progress {
+		scan.Progress = append(scan.Progress, &scannerv1.Progress{
+			ProjectId: progress.ProjectID,
+			Done:      int32(progress.Done),
+			Total:     int32(progress.Total),
+		})
+	}
+	return scan
+}
+
+// apiResultToProto converts an API result to its gRPC message
+func apiResultToProto(result APIResult) *scannerv1.APIResult {
+	message := &scannerv1.APIResult{
+		ProjectId:        result.ProjectID,
+		Environment:      result.Environment,
+		Name:             result.Name,
+		DisplayName:      result.DisplayName,
+		Status:           result.Status,
+		Enabled:          result.Enabled,
+		UnlimitedCost:    result.CostInfo.UnlimitedCost,
+		EstimatedCost:    result.CostInfo.EstimatedCost,
+		Currency:         result.CostInfo.Currency,
+		CheckedAt:        timestamppb.New(result.CheckedAt),
+		RiskFactors:      result.RiskFactors,
+		RequestCount_90D: result.RequestCount90d,
+		Error:            result.Error,
+	}
+	if result.RiskScore != nil {
+		score := int32(*result.RiskScore)
+		message.RiskScore = &score
+	}
+	if result.ResourceCount != nil {
+		count := int32(*result.ResourceCount)
+		message.ResourceCount = &count
+	}
+	return message
+}
+
+// timestampOrNil converts an optional time
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
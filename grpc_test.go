@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	scannerv1 "googleapichecker/proto/scanner/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStartScanValidatesLikeREST(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, rpcClient{tenant: &serverTenant{}, role: roleOperator})
+	service := &grpcScanService{}
+
+	tests := []struct {
+		name string
+		req  *scannerv1.StartScanRequest
+	}{
+		{"unknown profile", &scannerv1.StartScanRequest{Profile: "no-such-profile"}},
+		{"unknown check", &scannerv1.StartScanRequest{Checks: []string{"no-such-check"}}},
+		{"invalid tag key", &scannerv1.StartScanRequest{Tags: map[string]string{"bad key": "x"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rest := ScanRequest{Profile: test.req.Profile, Checks: test.req.Checks, Tags: test.req.Tags}
+			if err := rest.validate(); err == nil {
+				t.Fatalf("REST accepts the request")
+			}
+			_, err := service.StartScan(ctx, test.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("got %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
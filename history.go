@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultHistoryDir is where each scan's results are kept for trend detection
+const defaultHistoryDir = ".googleapichecker/history"
+
+// historyTimeFormat names history files so they sort chronologically
+const historyTimeFormat = "20060102_150405"
+
+// SaveHistory stores the scan's results and tags in the history store and returns the stored name
+func SaveHistory(store StateStore, results []APIResult, tags map[string]string) (string, error) {
+	data, err := encodeResults(results, tags)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("results_%s.json", time.Now().Format(historyTimeFormat))
+	if err := store.Save(name, data); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// historyFiles lists the stored results, oldest first
+func historyFiles(store StateStore) ([]string, error) {
+	names, err := store.List("results_")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %v", err)
+	}
+
+	files := names[:0]
+	for _, name := range names {
+		if strings.HasSuffix(name, ".json") {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// LoadPreviousScan returns the most recent stored results that cover any of the projects, or nil
+// when there are none. A history shared by scans of different projects, e.g. several daemon
+// schedules, is not compared across projects.
+func LoadPreviousScan(store StateStore, projects []string) ([]APIResult, error) {
+	files, err := historyFiles(store)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		wanted[project] = true
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := store.Load(files[i])
+		if err != nil {
+			return nil, err
+		}
+		results, err := decodeResults(data, files[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if wanted[result.ProjectID] {
+				return results, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// newHistoryCmd creates the subcommand that lists the scans kept in the history
+func newHistoryCmd() *cobra.Command {
+	var tagFilters []string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the scans kept in the scan history",
+		Long: `List the scans recorded in the scan history (--history-dir or --state-backend),
+oldest first, with their time, API counts and tags. Use --tag to only show scans
+tagged with every given key=value, e.g. to find the scan of a release.`,
+		Example: "  googleapichecker history --tag trigger=ci --tag release=v42",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := parseTags(tagFilters)
+			if err != nil {
+				return err
+			}
+
+			store, err := OpenStateStore(stateBackend, historyDir, "history")
+			if err != nil {
+				return err
+			}
+			files, err := historyFiles(store)
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tGENERATED\tAPIS\tENABLED\tTAGS")
+
+			count := 0
+			for _, name := range files {
+				data, err := store.Load(name)
+				if err != nil {
+					return err
+				}
+				resultsFile, err := decodeResultsFile(data, name)
+				if err != nil {
+					log.Printf("Warning: skipping %s: %v", name, err)
+					continue
+				}
+				if !matchTags(resultsFile.Tags, filter) {
+					continue
+				}
+
+				enabled := 0
+				for _, result := range resultsFile.Results {
+					if result.Enabled {
+						enabled++
+					}
+				}
+				generated := "-"
+				if !resultsFile.GeneratedAt.IsZero() {
+					generated = resultsFile.GeneratedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\n", name, generated, len(resultsFile.Results), enabled, formatTags(resultsFile.Tags))
+				count++
+			}
+			writer.Flush()
+
+			fmt.Printf("\n📚 %d scans\n", count)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&tagFilters, "tag", nil, "Only list scans with this key=value tag (repeatable)")
+	cmd.Flags().StringVar(&historyDir, "history-dir", defaultHistoryDir, "Directory where the scan history is kept")
+	cmd.Flags().StringVar(&stateBackend, "state-backend", "", "Read the scan history from Cloud Storage (gs://bucket/prefix) instead of --history-dir")
+	return cmd
+}
@@ -4,18 +4,89 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	apiToken  string
-	projectID string
-	threads   int
-	output    string
-	export    string
-	exportDir string
+	configPath string
+	config     *Config
+
+	apiToken   string
+	tokenFrom  string
+	projectIDs []string
+	threads    int
+	output     string
+	export     string
+	exportDir  string
+	landscape  bool
+
+	outputDir        string
+	filenameTemplate string
+
+	flushEvery int
+	pageSize   int
+	stateFlag  string
+	resumeFile string
+
+	bundle               bool
+	bundlePassphraseFrom string
+	bundleKey            string
+
+	csvColumns   []string
+	csvDelimiter string
+	csvPerStatus bool
+	csvStream    bool
+	// csvStreamed is set while the current scan's results CSV was written by --csv-stream
+	csvStreamed bool
+
+	skipCost    bool
+	summaryOnly bool
+	annotations string
+	groupBy     string
+	topN        int
+	showAll     bool
+	noProgress  bool
+	verbosity   int
+
+	parallelProjects int
+	pricingThreads   int
+	qps              float64
+	auditLogs        bool
+	usageMetrics     bool
+	publishMetrics   bool
+	keyless          bool
+	profileName      string
+	riskScoring      bool
+	incidents        bool
+	contacts         bool
+	assetCounts      bool
+	remediationFile  string
+
+	historyDir       string
+	noHistory        bool
+	anomalyThreshold float64
+	notifyWebhook    string
+	serviceNow       bool
+	usageFile        string
+	expectedFile     string
+	failOnDrift      bool
+	billingCheck     bool
+	reconcile        bool
+	allocateBy       []string
+	stateBackend     string
+	billingAccounts  []string
+	contextName      string
+	checkNames       []string
+	tagFlags         []string
+	scanTags         map[string]string
+	daemon           bool
+	noColor          bool
+	periodFlag       string
+	asciiOutput      bool
 )
 
 func main() {
@@ -24,66 +95,657 @@ func main() {
 		Short: "Google API Checker - Check all Google API products status and costs",
 		Long: `Google API Checker is a CLI tool that checks the status of all Google API products
 using multithreading and calculates potential costs based on pricing tables.`,
-		Run: runChecker,
+		Version:           toolVersion(),
+		PersistentPreRunE: initCommand,
+		PreRunE:           requireToken,
+		Run:               runChecker,
 	}
 
-	rootCmd.Flags().StringVarP(&apiToken, "token", "t", "", "Google API token (required)")
-	rootCmd.Flags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID (required for real API calls)")
-	rootCmd.Flags().IntVarP(&threads, "threads", "n", 10, "Number of concurrent threads")
-	rootCmd.Flags().StringVarP(&output, "output", "o", "results.json", "Output file path")
-	rootCmd.Flags().StringVarP(&export, "export", "e", "", "Export format: csv, pdf, both")
-	rootCmd.Flags().StringVarP(&exportDir, "export-dir", "d", ".", "Export directory")
-	rootCmd.MarkFlagRequired("token")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Config file with environment thresholds and policy (default: ./"+defaultConfigFile+" if present)")
+	rootCmd.PersistentFlags().StringVarP(&apiToken, "token", "t", "", "Google API token (prefer --token-from or $"+tokenEnvVar+" to keep it out of shell history)")
+	rootCmd.PersistentFlags().StringVar(&tokenFrom, "token-from", "", "Read the token from env:VAR, file:path or secretmanager:projects/P/secrets/S")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Named context from the config file's contexts, setting the token, projects, thresholds and history directory (or $"+contextEnvVar+")")
+	rootCmd.PersistentFlags().StringSliceVarP(&projectIDs, "project", "p", nil, "Google Cloud Project ID(s), repeatable or comma-separated (required for real API calls)")
+	rootCmd.PersistentFlags().BoolVar(&encryptState, "encrypt-state", false, "Encrypt the scan history with AES-GCM, using a key kept in the OS keychain")
+	rootCmd.PersistentFlags().StringVar(&statePassphraseFrom, "state-passphrase-from", "", "Encrypt the scan history with a passphrase read from env:VAR, file:path or secretmanager:projects/P/secrets/S instead of the keychain key (implies --encrypt-state)")
+	rootCmd.PersistentFlags().IntVarP(&threads, "threads", "n", 10, "Number of concurrent threads")
+	addOutputFlags(rootCmd)
+	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Results JSON path, overriding the filename template")
+	rootCmd.Flags().StringVar(&exportDir, "export-dir", "", "Export directory")
+	rootCmd.Flags().MarkDeprecated("export-dir", "use --output-dir")
+	rootCmd.Flags().StringSliceVar(&checkNames, "checks", nil, "Run only these check modules, e.g. cost,security (see \"checks list\"): "+strings.Join(checkModuleNames(), ", "))
+	rootCmd.Flags().BoolVar(&skipCost, "skip-cost", false, "Skip pricing lookups for a faster scan")
+	rootCmd.Flags().IntVar(&flushEvery, "flush-every", 100, "Save partial results to RESULTS.partial every N finished checks so a crashed scan can be resumed (0 = only at the end of each project)")
+	rootCmd.Flags().BoolVar(&keyless, "keyless", false, "Scan only the public Discovery directory (services, versions, docs links, deprecation) without any credential; enablement is not reported")
+	rootCmd.Flags().IntVar(&pageSize, "page-size", maxServicePageSize, "Services to request per Service Usage page when listing a project's APIs")
+	rootCmd.Flags().StringVar(&stateFlag, "state", "all", "Only check services in this state: enabled, disabled or all")
+	rootCmd.Flags().BoolVar(&csvStream, "csv-stream", false, "Write the results CSV row by row as checks finish instead of after the scan, for very large scans (needs --export csv or both)")
+	rootCmd.Flags().StringVar(&resumeFile, "resume", "", "Resume an interrupted scan from its .partial results file, checking only the APIs it had not finished")
+
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "Scan profile limiting the scan to a service group: "+strings.Join(profileNames(), ", "))
+	rootCmd.Flags().StringVar(&usageFile, "usage-file", "", "YAML file with expected monthly usage per API used to price estimates (default: ./"+defaultUsageFile+" if present)")
+	addExpectedFlags(rootCmd)
+	rootCmd.Flags().BoolVar(&applyDrift, "apply", false, "Enable the required APIs that --expected finds missing, after confirmation")
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Enable APIs with --apply without asking for confirmation")
+	rootCmd.Flags().StringVar(&historyDir, "history-dir", defaultHistoryDir, "Directory where each scan's results are kept for trend detection")
+	rootCmd.Flags().StringVar(&stateBackend, "state-backend", "", "Keep scan history in Cloud Storage (gs://bucket/prefix) instead of --history-dir, e.g. for stateless CronJobs")
+	rootCmd.Flags().StringArrayVar(&tagFlags, "tag", nil, "Tag the scan with key=value metadata kept in the report and history, e.g. trigger=ci (repeatable)")
+	rootCmd.Flags().BoolVar(&noHistory, "no-history", false, "Do not compare with or record to the scan history")
+	rootCmd.Flags().Float64Var(&anomalyThreshold, "anomaly-threshold", defaultAnomalyThreshold, "Flag APIs whose estimated cost grew by more than this percent since the previous scan")
+	rootCmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "Send high-priority alerts to this webhook URL (overrides notify.webhook_url in the config)")
+	rootCmd.Flags().BoolVar(&serviceNow, "servicenow", false, "Create a ServiceNow incident or change request for each policy violation without an open one (configured under servicenow in the config file)")
+	rootCmd.Flags().BoolVar(&daemon, "daemon", false, "Keep running and scan on the cron schedules in the config file")
+	rootCmd.Flags().IntVar(&parallelProjects, "parallel-projects", 1, "Number of projects to scan concurrently")
+	rootCmd.Flags().IntVar(&pricingThreads, "pricing-threads", 0, "Number of concurrent pricing lookups, run after each status check (default: --threads)")
+	rootCmd.Flags().BoolVar(&auditLogs, "audit-logs", false, "Look up who enabled each API and when in Cloud Audit Logs (requires --project)")
+	rootCmd.Flags().BoolVar(&assetCounts, "asset-inventory", false, "Count the resources behind each enabled API with Cloud Asset Inventory and recommend disabling APIs with none (requires --project)")
+	rootCmd.Flags().StringSliceVar(&billingAccounts, "billing-account", nil, "Also scan every project linked to these billing accounts, e.g. 012345-6789AB-CDEF01 (needs billing.resourceAssociations.list)")
+	rootCmd.Flags().BoolVar(&billingCheck, "billing-check", false, "Report projects without an active billing account or a BigQuery billing export (requires --project)")
+	rootCmd.Flags().BoolVar(&reconcile, "reconcile", false, "Show last month's billed cost per API from the BigQuery billing export next to the estimates (requires --project)")
+	rootCmd.Flags().StringSliceVar(&allocateBy, "allocate-by", nil, "Break reconciled costs down by these resource label keys, e.g. team,cost-center (implies --reconcile; overrides cost_allocation_labels in the config)")
+	rootCmd.Flags().BoolVar(&contacts, "contacts", false, "Include project owners and Essential Contacts in the report and address alerts to them (requires --project)")
+	rootCmd.Flags().BoolVar(&incidents, "incidents", false, "Flag enabled APIs affected by ongoing incidents on the Google Cloud status dashboard")
+	rootCmd.Flags().BoolVar(&riskScoring, "risk-score", false, "Score enabled Maps Platform APIs for abuse risk from API key restrictions and budget alerts (requires --project)")
+	rootCmd.Flags().BoolVar(&publishMetrics, "publish-metrics", false, "Write each API's estimated cost and enablement to Cloud Monitoring custom metrics for alerting (requires --project)")
+	rootCmd.Flags().BoolVar(&usageMetrics, "usage-metrics", false, "Flag enabled APIs with no requests in the last 90 days using Cloud Monitoring (requires --project)")
+	rootCmd.PersistentFlags().Float64Var(&qps, "qps", 0, "Maximum requests per second across all workers and projects (0 = unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by $NO_COLOR or when stdout is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&periodFlag, "period", "monthly", "Show costs per day, month or year: daily, monthly or annual (estimates are monthly; others are projected)")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Replace emoji and Unicode symbols with ASCII, e.g. for legacy Windows consoles")
+	rootCmd.PersistentFlags().StringVar(&universeDomain, "universe-domain", "", "Domain Google APIs are called on instead of googleapis.com, e.g. for a sovereign cloud")
+	rootCmd.PersistentFlags().StringArrayVar(&endpointFlags, "endpoint", nil, "Send a service's requests to this base URL, e.g. serviceusage=https://serviceusage.eu.rep.googleapis.com; * matches every service and {service} is replaced with its name (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&ipFamilyFlag, "ip-family", "", "Connect to Google APIs over IPv4 or IPv6 only: 4, 6 or auto")
+	rootCmd.PersistentFlags().StringArrayVar(&resolveFlags, "resolve", nil, "Connect to HOST at IP instead of resolving it, e.g. *.googleapis.com=199.36.153.4 for restricted.googleapis.com (repeatable)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose output; -v shows per-worker status, -vv also traces every request, response status and status decision to stderr")
+
+	rootCmd.AddCommand(newCheckCmd())
+	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newSchemaCmd())
+	rootCmd.AddCommand(newMigrateCmd())
+	rootCmd.AddCommand(newAuthCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newServerlessCmd())
+	rootCmd.AddCommand(newBundleCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newUpdateCatalogCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newChecksCmd())
+	rootCmd.AddCommand(newRequiredPermissionsCmd())
+	rootCmd.AddCommand(newDemoCmd())
+	rootCmd.AddCommand(newBenchCmd())
+
+	// Keep credentials out of logs and error output
+	log.SetOutput(redactingWriter{os.Stderr})
+	rootCmd.SetOut(redactingWriter{os.Stdout})
+	rootCmd.SetErr(redactingWriter{os.Stderr})
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Println(redactSecrets(err.Error()))
 		os.Exit(1)
 	}
 }
 
+// initCommand loads the config file, then resolves the API token and registers it for redaction
+func initCommand(cmd *cobra.Command, args []string) error {
+	setupConsole(noColor, asciiOutput)
+
+	var err error
+	if projection, err = ParseCostPeriod(periodFlag); err != nil {
+		return err
+	}
+	if config, err = LoadConfig(configPath); err != nil {
+		return err
+	}
+
+	if err := applyContext(cmd); err != nil {
+		return err
+	}
+	// Secret Manager lookups for the token already go to the configured endpoints
+	if endpoints, err = parseEndpoints(config.Endpoints, universeDomain, endpointFlags); err != nil {
+		return err
+	}
+	if network, err = parseNetwork(config.Network, ipFamilyFlag, resolveFlags); err != nil {
+		return err
+	}
+	apiTransport = network.transport()
+
+	if !contextWithoutToken() {
+		token, err := resolveToken(apiToken, tokenFrom)
+		if err != nil {
+			return err
+		}
+		apiToken = token
+		registerSecret(apiToken)
+	}
+
+	if scanTags, err = parseTags(tagFlags); err != nil {
+		return err
+	}
+	return nil
+}
+
+// contextEnvVar selects a context when --context is not given
+const contextEnvVar = "GOOGLE_API_CHECKER_CONTEXT"
+
+// contextHistoryDir is the history directory of a context without history_dir
+func contextHistoryDir(name string) string {
+	return filepath.Join(filepath.Dir(defaultHistoryDir), "contexts", name, "history")
+}
+
+// applyContext fills the token source, projects, thresholds and history directory from the selected
+// context wherever the matching flag was not given. A context never falls back to the default
+// token or history directory, which other contexts share.
+func applyContext(cmd *cobra.Command) error {
+	if contextName == "" {
+		contextName = os.Getenv(contextEnvVar)
+	}
+	if contextName == "" {
+		return nil
+	}
+	selected, ok := config.Contexts[contextName]
+	if !ok {
+		if len(config.Contexts) == 0 {
+			return fmt.Errorf("unknown context %q: the config file defines no contexts", contextName)
+		}
+		return fmt.Errorf("unknown context %q (use %s)", contextName, strings.Join(config.contextNames(), ", "))
+	}
+
+	// The context's token wins over $GOOGLE_API_CHECKER_TOKEN and the keychain, so a token left
+	// over from another context is never used by mistake
+	if apiToken == "" && tokenFrom == "" {
+		tokenFrom = selected.TokenFrom
+	}
+	if !cmd.Flags().Changed("project") && !keyless {
+		projectIDs = selected.Projects
+	}
+	config.Thresholds = mergeThresholds(selected.Thresholds, config.Thresholds)
+	if flag := cmd.Flags().Lookup("history-dir"); flag != nil && !flag.Changed {
+		dir := selected.HistoryDir
+		if dir == "" {
+			dir = contextHistoryDir(contextName)
+		}
+		if err := flag.Value.Set(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contextWithoutToken reports whether the selected context has no token source and none was given
+// on the command line, so the shared $GOOGLE_API_CHECKER_TOKEN and keychain token must not be used
+func contextWithoutToken() bool {
+	return contextName != "" && apiToken == "" && tokenFrom == ""
+}
+
+// requireToken falls back to the keychain and fails the command early when no API token is available
+func requireToken(cmd *cobra.Command, args []string) error {
+	// Keyless scans read public data only and never send a credential
+	if keyless {
+		if len(projectIDs) > 0 {
+			return fmt.Errorf("--keyless scans public Discovery data only and cannot be combined with --project")
+		}
+		apiToken = ""
+		return nil
+	}
+
+	if apiToken != "" {
+		return nil
+	}
+	if contextWithoutToken() {
+		return fmt.Errorf("context %q has no token_from; set one in the config file or pass --token-from", contextName)
+	}
+
+	// Fall back to the keychain entry saved by "auth login"
+	token, err := keyringToken()
+	if err != nil {
+		return fmt.Errorf(`required flag(s) "token" not set and %v`, err)
+	}
+	if token == "" {
+		return fmt.Errorf(`required flag(s) "token" not set (or use --token-from, $%s or "auth login")`, tokenEnvVar)
+	}
+	apiToken = token
+	registerSecret(apiToken)
+	return nil
+}
+
 func runChecker(cmd *cobra.Command, args []string) {
 	fmt.Println("🚀 Starting Google API Checker...")
+	if contextName != "" {
+		fmt.Printf("🧭 Context: %s\n", contextName)
+	}
 	fmt.Printf("📊 Using %d concurrent threads\n", threads)
-	fmt.Printf("💾 Results will be saved to: %s\n", output)
+	if exportDir != "" && !cmd.Flags().Changed("output-dir") {
+		outputDir = exportDir
+	}
+	if err := checkOutputFlags(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if csvStream && export != "csv" && export != "both" {
+		log.Fatalf("Error: --csv-stream needs --export csv or both")
+	}
+	if csvStream && csvPerStatus {
+		log.Fatalf("Error: --csv-stream writes a single results CSV and cannot be combined with --csv-per-status")
+	}
+	if pricingThreads < 0 {
+		log.Fatalf("Error: --pricing-threads must not be negative")
+	}
+	fmt.Printf("💾 Results will be saved to: %s\n", outputDir)
 	if export != "" {
 		fmt.Printf("📤 Export format: %s\n", export)
-		fmt.Printf("📁 Export directory: %s\n", exportDir)
 	}
 	fmt.Println()
 
-	checker := NewGoogleAPIChecker(apiToken, projectID, threads)
-	results, err := checker.CheckAllAPIs()
+	checkerOptions := buildCheckerOptions()
+
+	if len(billingAccounts) > 0 {
+		var err error
+		if projectIDs, err = billingAccountProjects(apiToken, billingAccounts, projectIDs, checkerOptions); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if len(projectIDs) == 0 {
+			log.Fatalf("Error: no projects with billing enabled are linked to %s", strings.Join(billingAccounts, ", "))
+		}
+	}
+
+	var history StateStore
+	if !noHistory {
+		var err error
+		if history, err = OpenStateStore(stateBackend, historyDir, "history"); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	if daemon {
+		if err := runDaemon(checkerOptions, history); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	projects := projectIDs
+	if len(projects) == 0 {
+		projects = []string{""}
+	}
+
+	if err := runScan(projects, checkerOptions, notifySettings(), history); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// addOutputFlags registers the flags for the report, HTML, exports and bundle shared by scans and the report subcommand
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "d", ".", "Directory for the results, reports and exports")
+	cmd.Flags().StringVar(&filenameTemplate, "filename-template", defaultFilenameTemplate, "Name of every output file, from {project}, {date}, {time}, {timestamp}, {type} and {ext}")
+	cmd.Flags().StringVarP(&export, "export", "e", "", "Export format: csv, pdf, both, gitlab (Code Quality JSON) or bitbucket (Code Insights report); PDFs leave out emoji and other characters the embedded DejaVu font lacks, such as CJK")
+	cmd.Flags().BoolVar(&bundle, "bundle", false, "Zip the results, reports and exports into one timestamped archive")
+	cmd.Flags().StringVar(&bundlePassphraseFrom, "bundle-passphrase-from", "", "Encrypt the --bundle archive with a passphrase read from env:VAR, file:path or secretmanager:projects/P/secrets/S")
+	cmd.Flags().BoolVar(&landscape, "pdf-landscape", false, "Use a landscape detailed table with pricing details and check time in PDF exports")
+	cmd.Flags().StringSliceVar(&csvColumns, "csv-columns", nil, "CSV columns to include (e.g. name,status,estimated_cost)")
+	cmd.Flags().StringVar(&csvDelimiter, "csv-delimiter", "comma", "CSV delimiter: comma, semicolon, tab")
+	cmd.Flags().BoolVar(&csvPerStatus, "csv-per-status", false, "Write one CSV file per status (enabled/disabled/errors)")
+	cmd.Flags().BoolVar(&includeRaw, "include-raw", false, "Keep each API's raw Service Usage and billing export responses in the results and write them to raw/PROJECT/API.json with --export, to review or debug how they were read")
+	cmd.Flags().StringVar(&summaryTemplateFile, "summary-template", "", "text/template file laying out the summary export instead of the built-in layout, e.g. a one-paragraph Slack message")
+	cmd.Flags().StringVar(&remediationFile, "generate-remediation", "", "Write a gcloud shell script implementing the recommendations (disable APIs, restrict keys, create budgets) to this file for review")
+	cmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Print a condensed summary of enabled APIs (implies --skip-cost)")
+	cmd.Flags().IntVar(&topN, "top", defaultConsoleTop, "Show at most this many APIs in each console report list, most expensive or riskiest first")
+	cmd.Flags().BoolVar(&showAll, "all", false, "Show every API in the console report lists, overriding --top")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Also list the APIs in the console report grouped by category, status, cost-bucket or cost-tier, with subtotals per group")
+	cmd.Flags().StringVar(&baselineFile, "baseline", "", "Results file of an earlier scan to compare with; the HTML report highlights changed APIs and adds a Changes tab")
+	cmd.Flags().StringVar(&annotations, "annotations", "", "Also print policy violations and unlimited-cost APIs as CI annotations: github")
+}
+
+// checkOutputFlags validates the output flags and reads the bundle passphrase before any work is done
+func checkOutputFlags() error {
+	if err := validateFilenameTemplate(filenameTemplate); err != nil {
+		return err
+	}
+	if _, err := ParseCSVDelimiter(csvDelimiter); err != nil {
+		return err
+	}
+	var err error
+	if annotations, err = ParseAnnotations(annotations); err != nil {
+		return err
+	}
+	if groupBy, err = ParseGroupBy(groupBy); err != nil {
+		return err
+	}
+	if topN < 1 {
+		return fmt.Errorf("--top must be at least 1 (use --all to show everything)")
+	}
+	if baselineFile != "" {
+		if baseline, err = LoadResultsFile(baselineFile); err != nil {
+			return err
+		}
+	}
+	if summaryTemplateFile != "" {
+		if summaryTemplate, err = LoadSummaryTemplate(summaryTemplateFile); err != nil {
+			return err
+		}
+	}
+	if bundlePassphraseFrom != "" {
+		if !bundle {
+			return fmt.Errorf("--bundle-passphrase-from needs --bundle")
+		}
+		if bundleKey, err = bundlePassphrase(bundlePassphraseFrom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildCheckerOptions turns the flags and config file into checker options
+func buildCheckerOptions() CheckerOptions {
+	if summaryOnly {
+		skipCost = true
+	}
+	checkerOptions := CheckerOptions{
+		SkipCost:       skipCost,
+		NoProgress:     noProgress,
+		Verbosity:      verbosity,
+		RateLimiter:    NewRateLimiter(qps),
+		AuditLogs:      auditLogs,
+		UsageMetrics:   usageMetrics,
+		RiskScoring:    riskScoring,
+		Incidents:      incidents,
+		Contacts:       contacts,
+		AssetCounts:    assetCounts,
+		BillingCheck:   billingCheck,
+		Reconcile:      reconcile,
+		PageSize:       pageSize,
+		PublishMetrics: publishMetrics,
+		Keyless:        keyless,
+		IncludeRaw:     includeRaw,
+		PricingThreads: pricingThreads,
+	}
+	if err := applyChecks(&checkerOptions, checkNames); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	state, err := ParseServiceState(stateFlag)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	checkerOptions.State = state
+	expectedUsage, err := LoadExpectedUsage(usageFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(expectedUsage) > 0 {
+		fmt.Printf("📐 Pricing %d APIs from expected usage\n", len(expectedUsage))
+		checkerOptions.ExpectedUsage = expectedUsage
+	}
+	if described := endpoints.String(); described != "" {
+		fmt.Printf("🌐 Endpoints: %s\n", described)
+	}
+	if described := network.String(); described != "" {
+		fmt.Printf("🌐 Network: %s\n", described)
+	}
+	if profileName != "" {
+		profile, err := LookupProfile(profileName)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		checkerOptions.Profile = profile
+	}
+	if expectedFile != "" {
+		if expectedManifest, err = LoadExpectedManifest(expectedFile); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Printf("🧭 Checking API enablement against %s\n", expectedFile)
+	}
+	if applyDrift && expectedManifest == nil {
+		log.Fatalf("Error: --apply needs an --expected manifest listing the required APIs")
+	}
+	if serviceNow {
+		if serviceNowClient, err = NewServiceNowClient(config.ServiceNow); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Printf("🎫 Filing policy violations as ServiceNow %s records\n", serviceNowClient.Table())
+	}
+	if len(config.Environments) > 0 || (expectedManifest != nil && len(expectedManifest.Environments) > 0) {
+		checkerOptions.EnvironmentLabel = config.EnvironmentLabel
+		if checkerOptions.EnvironmentLabel == "" {
+			checkerOptions.EnvironmentLabel = "env"
+		}
+	}
+
+	checkerOptions.BillingExport = config.BillingExport
+	checkerOptions.AllocationLabels = config.CostAllocationLabels
+	checkerOptions.APIOverrides = config.APIs
+	checkerOptions.UnlimitedCost = config.UnlimitedCost
+	if len(allocateBy) > 0 {
+		checkerOptions.AllocationLabels = allocateBy
+		checkerOptions.Reconcile = true
+	}
+
+	return checkerOptions
+}
+
+// notifySettings are the config file's notify settings, with the webhook from --notify-webhook
+func notifySettings() NotifyConfig {
+	notify := config.Notify
+	if notifyWebhook != "" {
+		notify.WebhookURL = notifyWebhook
+	}
+	return notify
+}
+
+// runScan scans the projects, then records, reports, notifies and exports the results.
+// High-priority alerts go to notify.WebhookURL when it is set and the notify rules alert on changes
+// since the previous scan; history is nil with --no-history.
+func runScan(projects []string, checkerOptions CheckerOptions, notify NotifyConfig, history StateStore) error {
+	artifacts, err := NewArtifactNamer(outputDir, filenameTemplate, projects, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := artifacts.EnsureDir(); err != nil {
+		return err
+	}
+	resultsFile := output
+	if resultsFile == "" {
+		resultsFile = artifacts.Path("results", "json")
+	}
+
+	// Partial results survive a crash; --resume picks them up again
+	var resumed []APIResult
+	resumeFrom := resumeFile
+	if resumeFrom != "" {
+		if resumed, err = LoadResults(resumeFrom); err != nil {
+			return fmt.Errorf("error resuming scan: %v", err)
+		}
+		fmt.Printf("⏯️  Resuming from %s (%d results)\n", resumeFrom, len(resumed))
+		// Later daemon scans start fresh
+		resumeFile = ""
+	}
+	collector := NewResultCollector(resultsFile+partialSuffix, flushEvery, resumed)
+	checkerOptions.Collector = collector
+
+	// Rows go to the results CSV as checks finish; the export then leaves it alone
+	if csvStream {
+		columns, err := resolveCSVColumns(csvColumns)
+		if err != nil {
+			return err
+		}
+		delimiter, err := ParseCSVDelimiter(csvDelimiter)
+		if err != nil {
+			return err
+		}
+		if checkerOptions.CSVStream, err = NewCSVStream(artifacts.Path("results", "csv"), columns, delimiter); err != nil {
+			return err
+		}
+	}
+
+	scan, err := ScanProjects(apiToken, projects, threads, parallelProjects, checkerOptions)
+	results := scan.Results
+	// A stopped scan is left to --resume rather than recorded half done
+	cancelled := checkerOptions.Context != nil && checkerOptions.Context.Err() != nil
+	if checkerOptions.CSVStream != nil {
+		if err := checkerOptions.CSVStream.Close(); err != nil {
+			log.Printf("Warning: %v; the results CSV is exported again after the scan", err)
+		} else {
+			csvStreamed = true
+			defer func() { csvStreamed = false }()
+		}
+	}
+	if err != nil {
+		if len(results) == 0 {
+			return fmt.Errorf("error checking APIs: %v", err)
+		}
+		if cancelled {
+			return fmt.Errorf("scan stopped after %d checks; resume it with --resume %s: %v", len(results), resultsFile+partialSuffix, err)
+		}
+		log.Printf("Warning: some projects could not be scanned: %v", err)
+	}
+
+	// Load the previous scan before this one is recorded
+	var previous []APIResult
+	if history != nil {
+		if previous, err = LoadPreviousScan(history, projects); err != nil {
+			log.Printf("Warning: could not read scan history: %v", err)
+		}
+	}
+
+	// The report assigns the cost tiers of the config file's thresholds, which the saved results keep
+	report := GenerateReportWithPolicy(results, PolicyFromConfig(config))
+	if err := SaveResults(results, resultsFile, scanTags); err != nil {
+		return fmt.Errorf("error saving results: %v", err)
+	}
+	if err := collector.Remove(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	// A finished results file resumed to re-check skipped APIs is kept
+	if strings.HasSuffix(resumeFrom, partialSuffix) && resumeFrom != resultsFile+partialSuffix {
+		if err := os.Remove(resumeFrom); err != nil {
+			log.Printf("Warning: could not remove %s: %v", resumeFrom, err)
+		}
+	}
+	if history != nil {
+		if _, err := SaveHistory(history, results, scanTags); err != nil {
+			log.Printf("Warning: could not record scan history: %v", err)
+		}
+	}
+
+	// Complete and print report
+	report.SetFindings(scan.Findings)
+	report.Overhead = scan.Overhead
+	report.SetContacts(scan.Contacts)
+	report.Tags = scanTags
+	compareWithBaseline(report, &ResultsFile{GeneratedAt: time.Now(), Tags: scanTags, Results: results}, resultsFile)
+	if history != nil {
+		if report.Trend, err = LoadTrend(history); err != nil {
+			log.Printf("Warning: could not read scan history: %v", err)
+		}
+	}
+	if previous != nil {
+		report.SetCostAnomalies(DetectCostAnomalies(previous, results, anomalyThreshold), anomalyThreshold)
+	}
+	report.Summary.CostSkipped = skipCost
+	report.Summary.Keyless = checkerOptions.Keyless
+	if expectedManifest != nil {
+		report.SetDrift(expectedManifest.Drift(results), expectedManifest.Path)
+	}
+	printReport(report)
+
+	// Send high-priority alerts
+	if notify.WebhookURL != "" && len(report.CostAnomalies) > 0 {
+		alerts := anomalyAlerts(report.CostAnomalies)
+		routeAlerts(alerts, report.Contacts, "BILLING")
+		if err := NewWebhookNotifier(notify.WebhookURL).Notify(alerts); err != nil {
+			log.Printf("Warning: notification failed: %v", err)
+		}
+	}
+	// Without a previous scan there is nothing to compare, so the first scan alerts on nothing
+	if len(notify.Rules) > 0 && previous != nil {
+		before, after := sharedProjects(previous, results)
+		diff := DiffScans(&ResultsFile{Results: before}, &ResultsFile{Results: after}, "previous scan", "this scan")
+		sendAlerts(changeAlerts(diff, notify, report.Contacts))
+	}
+	// Record numbers are kept in the report, so records are filed before it is saved
+	if serviceNowClient != nil && len(report.PolicyViolations) > 0 {
+		created, open, err := serviceNowClient.FileViolations(report.PolicyViolations)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		fmt.Printf("🎫 ServiceNow: %d %s record(s) created, %d violation(s) already tracked\n", created, serviceNowClient.Table(), open)
+	}
+
+	var extra []string
+	if output != "" {
+		extra = append(extra, output)
+	}
+	reportFile, err := writeReportFiles(report, results, artifacts, extra)
 	if err != nil {
-		log.Fatalf("Error checking APIs: %v", err)
+		return err
 	}
 
-	// Save results
-	if err := checker.SaveResults(results, output); err != nil {
-		log.Fatalf("Error saving results: %v", err)
+	fmt.Println("✅ API checking completed successfully!")
+	fmt.Printf("📄 Results saved to: %s\n", resultsFile)
+	fmt.Printf("📊 Report saved to: %s\n", reportFile)
+
+	// Drift found by this scan is still reported; the next scan shows what --apply enabled
+	if applyDrift {
+		if err := applyMissingAPIs(report.Drift, checkerOptions); err != nil {
+			return err
+		}
 	}
+	return driftError(report)
+}
 
-	// Generate and print report
-	report := GenerateReport(results)
-	PrintReport(report)
+// printReport prints the full or, with --summary-only, the condensed console report
+func printReport(report *Report) {
+	if summaryOnly {
+		PrintSummaryReport(report)
+	} else {
+		PrintReport(report)
+	}
+	if annotations == annotationsGitHub {
+		printGitHubAnnotations(os.Stdout, report)
+	}
+}
 
-	// Save report
-	reportFile := strings.Replace(output, ".json", "_report.json", 1)
+// writeReportFiles saves the report, HTML, remediation script, exports and bundle for the results.
+// extra lists files outside the filename template to add to the bundle. It returns the report path.
+func writeReportFiles(report *Report, results []APIResult, artifacts ArtifactNamer, extra []string) (string, error) {
+	reportFile := artifacts.Path("report", "json")
 	if err := SaveReport(report, reportFile); err != nil {
-		log.Fatalf("Error saving report: %v", err)
+		return "", fmt.Errorf("error saving report: %v", err)
 	}
 
 	// Generate HTML report
-	htmlFile := strings.Replace(output, ".json", "_report.html", 1)
-	if err := generateHTMLReport(results, htmlFile); err != nil {
+	htmlFile := artifacts.Path("report", "html")
+	if err := generateHTMLReport(report, results, htmlFile); err != nil {
 		log.Printf("Warning: HTML report generation failed: %v", err)
 	}
 
+	if remediationFile != "" {
+		if err := WriteRemediationScript(report, remediationFile); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			fmt.Printf("🛠️  Remediation script written to %s; review it before running\n", remediationFile)
+		}
+	}
+
 	// Export if requested
 	if export != "" {
 		fmt.Println("📤 Exporting results...")
+		delimiter, err := ParseCSVDelimiter(csvDelimiter)
+		if err != nil {
+			return "", err
+		}
+
 		exportOptions := ExportOptions{
-			Format:    export,
-			OutputDir: exportDir,
+			Format:          export,
+			IncludeRaw:      includeRaw,
+			Artifacts:       artifacts,
+			Landscape:       landscape,
+			SourcePath:      qualitySourcePath(),
+			CSVColumns:      csvColumns,
+			CSVDelimiter:    delimiter,
+			CSVPerStatus:    csvPerStatus,
+			CSVStreamed:     csvStreamed,
+			SummaryTemplate: summaryTemplate,
 		}
 
 		if err := ExportResults(report, results, exportOptions); err != nil {
@@ -96,7 +758,24 @@ func runChecker(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	fmt.Println("✅ API checking completed successfully!")
-	fmt.Printf("📄 Results saved to: %s\n", output)
-	fmt.Printf("📊 Report saved to: %s\n", reportFile)
+	if bundle {
+		files := append(artifacts.Named(), extra...)
+		if remediationFile != "" {
+			files = append(files, remediationFile)
+		}
+		if archive, err := WriteBundle(bundlePath(artifacts), files, bundleKey); err != nil {
+			log.Printf("Warning: bundle failed: %v", err)
+		} else {
+			fmt.Printf("🗜️  Bundle saved to: %s\n", archive)
+		}
+	}
+	return reportFile, nil
+}
+
+// qualitySourcePath is the file CI code-quality findings are attached to: the config file, since it holds the policy
+func qualitySourcePath() string {
+	if configPath != "" {
+		return filepath.ToSlash(configPath)
+	}
+	return defaultConfigFile
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Custom metrics written by --publish-metrics, labelled with the service name
+const (
+	metricEstimatedCost = "custom.googleapis.com/apichecker/estimated_cost"
+	metricEnabled       = "custom.googleapis.com/apichecker/enabled"
+)
+
+// maxTimeSeriesPerWrite is the most time series Cloud Monitoring accepts in one create call
+const maxTimeSeriesPerWrite = 200
+
+// monitoringTimeSeries is a time series point in a Cloud Monitoring timeSeries.create request
+type monitoringTimeSeries struct {
+	Metric struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metric"`
+	Resource struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	Points []monitoringPoint `json:"points"`
+}
+
+// monitoringPoint is a single gauge value
+type monitoringPoint struct {
+	Interval struct {
+		EndTime string `json:"endTime"`
+	} `json:"interval"`
+	Value struct {
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		Int64Value  *string  `json:"int64Value,omitempty"`
+	} `json:"value"`
+}
+
+// publishMetrics writes each API's estimated cost and enablement to the project as custom metrics,
+// so alerts on them can be set up in Cloud Monitoring. APIs whose state is not known are skipped
+func (c *GoogleAPIChecker) publishMetrics(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("publishing metrics requires a project ID")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var series []monitoringTimeSeries
+	for _, result := range results {
+		if result.Status == statusError || statusUndetermined(result.Status) {
+			continue
+		}
+
+		cost := result.CostInfo.EstimatedCost
+		series = append(series, c.timeSeries(metricEstimatedCost, result.Name, now, func(p *monitoringPoint) {
+			p.Value.DoubleValue = &cost
+		}))
+
+		enabled := "0"
+		if result.Enabled {
+			enabled = "1"
+		}
+		series = append(series, c.timeSeries(metricEnabled, result.Name, now, func(p *monitoringPoint) {
+			p.Value.Int64Value = &enabled
+		}))
+	}
+
+	requestURL := fmt.Sprintf("https://monitoring.googleapis.com/v3/projects/%s/timeSeries", c.projectID)
+	for start := 0; start < len(series); start += maxTimeSeriesPerWrite {
+		end := min(start+maxTimeSeriesPerWrite, len(series))
+		body := map[string]interface{}{"timeSeries": series[start:end]}
+		var response struct{}
+		if err := c.postJSON(requestURL, "metric write", body, &response); err != nil {
+			return err
+		}
+	}
+
+	c.status("📡 Published %d metric points to Cloud Monitoring", len(series))
+	return nil
+}
+
+// timeSeries builds one point of a custom metric for a service on the global resource of the project
+func (c *GoogleAPIChecker) timeSeries(metricType, service, endTime string, setValue func(*monitoringPoint)) monitoringTimeSeries {
+	var series monitoringTimeSeries
+	series.Metric.Type = metricType
+	series.Metric.Labels = map[string]string{"service": service}
+	series.Resource.Type = "global"
+	series.Resource.Labels = map[string]string{"project_id": c.projectID}
+
+	var point monitoringPoint
+	point.Interval.EndTime = endTime
+	setValue(&point)
+	series.Points = []monitoringPoint{point}
+	return series
+}
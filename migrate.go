@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// OutputSchemaVersion is the current version of the results and report JSON formats.
+// Bump it whenever the structs change shape and add a step to resultsMigrations/reportMigrations.
+//
+//	1: results.json was a bare array of APIResult, reports had no schema_version
+//	2: results.json is wrapped in ResultsFile; both carry schema_version
+const OutputSchemaVersion = 2
+
+// migrationStep upgrades a decoded document from version N to N+1
+type migrationStep func(doc map[string]interface{}) error
+
+// resultsMigrations holds the upgrade step from version N (the key) to N+1
+var resultsMigrations = map[int]migrationStep{
+	1: func(doc map[string]interface{}) error {
+		// The v1 array was wrapped into doc["results"] by decodeOutputFile
+		doc["tool_version"] = "unknown"
+		if _, ok := doc["generated_at"]; !ok {
+			doc["generated_at"] = time.Time{}.Format(time.RFC3339)
+		}
+		return nil
+	},
+}
+
+// reportMigrations holds the upgrade step from version N (the key) to N+1
+var reportMigrations = map[int]migrationStep{
+	1: func(doc map[string]interface{}) error {
+		if _, ok := doc["tool_version"]; !ok {
+			doc["tool_version"] = "unknown"
+		}
+		return nil
+	},
+}
+
+// decodeOutputFile reads a results or report file of any version.
+// It returns the document as a generic map, its kind ("results" or "report") and version.
+func decodeOutputFile(data []byte) (map[string]interface{}, string, int, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, "", 0, fmt.Errorf("file is empty")
+	}
+
+	// Version 1 results files are a bare array
+	if data[0] == '[' {
+		var results []interface{}
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, "", 0, fmt.Errorf("failed to parse results: %v", err)
+		}
+		return map[string]interface{}{"results": results}, "results", 1, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, "", 0, fmt.Errorf("failed to parse file: %v", err)
+	}
+
+	kind := "report"
+	if _, ok := doc["results"]; ok {
+		kind = "results"
+	} else if _, ok := doc["summary"]; !ok {
+		return nil, "", 0, fmt.Errorf("file is neither a results nor a report file")
+	}
+
+	version := 1
+	if v, ok := doc["schema_version"].(float64); ok {
+		version = int(v)
+	}
+
+	return doc, kind, version, nil
+}
+
+// migrateDocument upgrades doc to OutputSchemaVersion
+func migrateDocument(doc map[string]interface{}, kind string, version int) error {
+	if version > OutputSchemaVersion {
+		return fmt.Errorf("file has schema version %d, newer than supported version %d; upgrade the tool", version, OutputSchemaVersion)
+	}
+
+	migrations := resultsMigrations
+	if kind == "report" {
+		migrations = reportMigrations
+	}
+
+	for v := version; v < OutputSchemaVersion; v++ {
+		step, ok := migrations[v]
+		if !ok {
+			return fmt.Errorf("no migration from %s schema version %d", kind, v)
+		}
+		if err := step(doc); err != nil {
+			return fmt.Errorf("migration from %s schema version %d failed: %v", kind, v, err)
+		}
+	}
+
+	doc["schema_version"] = OutputSchemaVersion
+	return nil
+}
+
+// LoadResults reads a results file of any supported schema version
+func LoadResults(filename string) ([]APIResult, error) {
+	resultsFile, err := LoadResultsFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return resultsFile.Results, nil
+}
+
+// LoadResultsFile reads a results file of any supported schema version, with its metadata
+func LoadResultsFile(filename string) (*ResultsFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %v", err)
+	}
+	return decodeResultsFile(data, filename)
+}
+
+// decodeResults decodes results JSON of any supported schema version; name is used in errors
+func decodeResults(data []byte, filename string) ([]APIResult, error) {
+	resultsFile, err := decodeResultsFile(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	return resultsFile.Results, nil
+}
+
+// decodeResultsFile decodes a results file of any supported schema version; name is used in errors
+func decodeResultsFile(data []byte, filename string) (*ResultsFile, error) {
+	doc, kind, version, err := decodeOutputFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+	if kind != "results" {
+		return nil, fmt.Errorf("%s is a report file, not a results file", filename)
+	}
+	if err := migrateDocument(doc, kind, version); err != nil {
+		return nil, err
+	}
+
+	// Round-trip through JSON to decode the migrated document into the current structs
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated results: %v", err)
+	}
+
+	var resultsFile ResultsFile
+	if err := json.Unmarshal(migrated, &resultsFile); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %v", err)
+	}
+
+	return &resultsFile, nil
+}
+
+// newMigrateCmd creates the subcommand that upgrades old output files
+func newMigrateCmd() *cobra.Command {
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "migrate FILE [FILE...]",
+		Short: "Upgrade results or report files to the current format",
+		Long: fmt.Sprintf(`Upgrade results.json or report JSON files written by older versions of the tool
+to schema version %d. Files are rewritten in place and the original is kept
+with a .bak suffix, unless --output is given for a single file.`, OutputSchemaVersion),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFile != "" && len(args) > 1 {
+				return fmt.Errorf("--output can only be used with a single file")
+			}
+
+			for _, filename := range args {
+				if err := migrateFile(filename, outputFile); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the migrated file here instead of in place")
+	return cmd
+}
+
+// migrateFile upgrades one file, writing to target or in place
+func migrateFile(filename, target string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+
+	doc, kind, version, err := decodeOutputFile(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+
+	if version == OutputSchemaVersion && target == "" {
+		fmt.Printf("✅ %s is already at schema version %d\n", filename, version)
+		return nil
+	}
+
+	if err := migrateDocument(doc, kind, version); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	if target == "" {
+		target = filename
+		if err := os.WriteFile(filename+".bak", data, 0644); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+	}
+
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migrated file: %v", err)
+	}
+	if err := os.WriteFile(target, append(migrated, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", target, err)
+	}
+
+	fmt.Printf("✅ Migrated %s %s from schema version %d to %d: %s\n", kind, filename, version, OutputSchemaVersion, target)
+	return nil
+}
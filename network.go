@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiTransport carries Google API requests with the --ip-family and --resolve settings,
+// nil for Go's default transport
+var apiTransport http.RoundTripper
+
+// network holds the connection settings from the config file, --ip-family and --resolve
+var network NetworkConfig
+
+// Network flags
+var (
+	ipFamilyFlag string
+	resolveFlags []string
+)
+
+// NetworkConfig controls how connections to Google APIs are made, e.g. to reach the
+// restricted.googleapis.com or private.googleapis.com addresses of Private Google Access
+type NetworkConfig struct {
+	// IPFamily is 4 or 6 to only connect over IPv4 or IPv6; empty lets the system choose
+	IPFamily string `yaml:"ip_family"`
+	// Resolve maps a host, or *.domain for its subdomains, to the IP address connections go to.
+	// TLS still uses the host name, so certificates keep validating.
+	Resolve map[string]string `yaml:"resolve"`
+}
+
+// parseNetwork merges the --ip-family and --resolve HOST=IP flags over the config file's network settings
+func parseNetwork(configured NetworkConfig, family string, flags []string) (NetworkConfig, error) {
+	merged := NetworkConfig{IPFamily: configured.IPFamily, Resolve: make(map[string]string)}
+	for host, ip := range configured.Resolve {
+		merged.Resolve[strings.ToLower(host)] = ip
+	}
+	if family != "" {
+		merged.IPFamily = family
+	}
+	for _, flag := range flags {
+		host, ip, ok := strings.Cut(flag, "=")
+		if !ok || host == "" {
+			return NetworkConfig{}, fmt.Errorf("invalid --resolve %q (use HOST=IP, e.g. *.googleapis.com=199.36.153.4)", flag)
+		}
+		merged.Resolve[strings.ToLower(host)] = ip
+	}
+
+	switch strings.TrimPrefix(strings.ToLower(merged.IPFamily), "ipv") {
+	case "", "auto":
+		merged.IPFamily = ""
+	case "4":
+		merged.IPFamily = "4"
+	case "6":
+		merged.IPFamily = "6"
+	default:
+		return NetworkConfig{}, fmt.Errorf("invalid IP family %q (use 4, 6 or auto)", merged.IPFamily)
+	}
+	for host, ip := range merged.Resolve {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return NetworkConfig{}, fmt.Errorf("invalid address %q for %s: not an IP address", ip, host)
+		}
+		if (merged.IPFamily == "4" && parsed.To4() == nil) || (merged.IPFamily == "6" && parsed.To4() != nil) {
+			return NetworkConfig{}, fmt.Errorf("address %s for %s is not an IPv%s address", ip, host, merged.IPFamily)
+		}
+	}
+	return merged, nil
+}
+
+// lookup returns the address configured for host, empty when it resolves through DNS.
+// Exact hosts win over the longest matching *.domain.
+func (n NetworkConfig) lookup(host string) string {
+	host = strings.ToLower(host)
+	if ip, ok := n.Resolve[host]; ok {
+		return ip
+	}
+	best := ""
+	for pattern := range n.Resolve {
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok && strings.HasSuffix(host, "."+domain) && len(pattern) > len(best) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return n.Resolve[best]
+}
+
+// transport returns a transport applying the settings, nil when there are none
+func (n NetworkConfig) transport() http.RoundTripper {
+	if n.IPFamily == "" && len(n.Resolve) == 0 {
+		return nil
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip := n.lookup(host); ip != "" {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		if n.IPFamily != "" {
+			network = "tcp" + n.IPFamily
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return transport
+}
+
+// String describes the settings, empty when there are none
+func (n NetworkConfig) String() string {
+	var parts []string
+	if n.IPFamily != "" {
+		parts = append(parts, "IPv"+n.IPFamily+" only")
+	}
+	hosts := make([]string, 0, len(n.Resolve))
+	for host := range n.Resolve {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		parts = append(parts, host+" → "+n.Resolve[host])
+	}
+	return strings.Join(parts, ", ")
+}
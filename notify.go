@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Alert is a high-priority finding sent to notifiers
+type Alert struct {
+	Severity  string `json:"severity"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Recipients are the project's contacts for this kind of alert
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// Notifier delivers alerts to an external system
+type Notifier interface {
+	Notify(alerts []Alert) error
+}
+
+// WebhookNotifier posts alerts as JSON; the "text" field makes it work with Slack-style incoming webhooks
+type WebhookNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Notify posts all alerts in a single request
+func (w *WebhookNotifier) Notify(alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(alerts)+1)
+	lines = append(lines, fmt.Sprintf("Google API Checker: %d alert(s)", len(alerts)))
+	for _, alert := range alerts {
+		line := fmt.Sprintf("[%s] %s: %s", alert.Severity, alert.Title, alert.Message)
+		if len(alert.Recipients) > 0 {
+			line += " → " + strings.Join(alert.Recipients, ", ")
+		}
+		lines = append(lines, line)
+	}
+
+	payload := struct {
+		Text   string  `json:"text"`
+		Alerts []Alert `json:"alerts"`
+	}{
+		Text:   strings.Join(lines, "\n"),
+		Alerts: alerts,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %v", err)
+	}
+
+	resp, err := w.client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// anomalyAlerts converts cost anomalies into high-priority alerts
+func anomalyAlerts(anomalies []CostAnomaly) []Alert {
+	alerts := make([]Alert, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		alerts = append(alerts, Alert{
+			Severity:  "high",
+			Title:     "Cost increase",
+			Message:   anomaly.String(),
+			ProjectID: anomaly.ProjectID,
+		})
+	}
+	return alerts
+}
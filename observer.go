@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// APIEvent describes one API check reported to a ProgressObserver
+type APIEvent struct {
+	ProjectID string
+	API       string     // Service name, empty for project-wide errors
+	Worker    int        // Worker running the check, starting at 1
+	Result    *APIResult // The check's result, set for OnAPIDone and failed checks
+	Done      int        // Checks finished so far in this project, including this one for OnAPIDone
+	Total     int        // Checks in this project
+}
+
+// ProgressObserver receives a scan's progress so embedding applications can render it their own way.
+// OnAPIStart may be called from several workers at once; the other methods are called from one goroutine per project.
+type ProgressObserver interface {
+	// OnStatus reports a scan stage, e.g. discovering services or looking up audit logs
+	OnStatus(projectID, message string)
+	// OnAPIStart is called when a worker starts checking an API
+	OnAPIStart(event APIEvent)
+	// OnAPIDone is called for every finished check, including failed ones
+	OnAPIDone(event APIEvent)
+	// OnError reports a failed check after its OnAPIDone, or a failed project-wide lookup with an empty API
+	OnError(event APIEvent, err error)
+}
+
+// NopObserver ignores all progress; embed it to implement only some ProgressObserver methods
+type NopObserver struct{}
+
+func (NopObserver) OnStatus(projectID, message string) {}
+func (NopObserver) OnAPIStart(event APIEvent)          {}
+func (NopObserver) OnAPIDone(event APIEvent)           {}
+func (NopObserver) OnError(event APIEvent, err error)  {}
+
+// consoleObserver prints status lines and draws a progress bar on stdout
+type consoleObserver struct {
+	options ProgressOptions
+
+	mu       sync.Mutex
+	progress *ProgressBar
+}
+
+// newConsoleObserver creates the observer used when CheckerOptions has none
+func newConsoleObserver(options ProgressOptions) *consoleObserver {
+	return &consoleObserver{options: options}
+}
+
+// bar returns the progress bar, creating it on the first check
+func (o *consoleObserver) bar(total int) *ProgressBar {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.progress == nil {
+		o.progress = NewProgressBar(total, o.options)
+	}
+	return o.progress
+}
+
+func (o *consoleObserver) OnStatus(projectID, message string) {
+	fmt.Println(message)
+}
+
+func (o *consoleObserver) OnAPIStart(event APIEvent) {
+	o.bar(event.Total).WorkerStatus(event.Worker, fmt.Sprintf("checking %s", event.API))
+}
+
+func (o *consoleObserver) OnAPIChecked(event APIEvent) {
+	o.bar(event.Total).Checked()
+}
+
+func (o *consoleObserver) OnAPIDone(event APIEvent) {
+	progress := o.bar(event.Total)
+	progress.WorkerStatus(event.Worker, fmt.Sprintf("%s → %s", event.API, statusBadge(event.Result.Status)))
+	progress.Update(*event.Result)
+	if event.Done == event.Total {
+		progress.Complete()
+	}
+}
+
+func (o *consoleObserver) OnError(event APIEvent, err error) {
+	// Failed checks are counted on the progress bar and listed in the report
+	if event.API == "" {
+		fmt.Printf("⚠️  %s\n", redactSecrets(err.Error()))
+	}
+}
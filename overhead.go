@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ServiceCalls counts the requests a scan sent to one Google API
+type ServiceCalls struct {
+	Service   string `json:"service"`
+	Requests  int    `json:"requests"`
+	Throttled int    `json:"throttled"`
+	Failed    int    `json:"failed"` // Requests that got no response, e.g. timeouts
+}
+
+// ScanOverhead is the quota the scan itself consumed: the requests it sent per API and how
+// many were throttled with HTTP 429
+type ScanOverhead struct {
+	Requests  int `json:"requests"`
+	Throttled int `json:"throttled"`
+	Failed    int `json:"failed"`
+	// ThrottleRate is the percentage of requests answered with HTTP 429
+	ThrottleRate float64        `json:"throttle_rate"`
+	Services     []ServiceCalls `json:"services"`
+}
+
+// callCounter counts a checker's requests per service; workers share it
+type callCounter struct {
+	mu       sync.Mutex
+	services map[string]*ServiceCalls
+}
+
+// record counts a request to the service's host, e.g. www.googleapis.com for Discovery, with its response or nil when it failed
+func (c *callCounter) record(service string, resp *http.Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.services == nil {
+		c.services = make(map[string]*ServiceCalls)
+	}
+	calls, ok := c.services[service]
+	if !ok {
+		calls = &ServiceCalls{Service: service}
+		c.services[service] = calls
+	}
+	calls.Requests++
+	switch {
+	case resp == nil:
+		calls.Failed++
+	case resp.StatusCode == http.StatusTooManyRequests:
+		calls.Throttled++
+	}
+}
+
+// mergeOverhead adds up the requests of several checkers, busiest service first; nil without requests
+func mergeOverhead(counters ...*callCounter) *ScanOverhead {
+	totals := make(map[string]*ServiceCalls)
+	for _, counter := range counters {
+		if counter == nil {
+			continue
+		}
+		counter.mu.Lock()
+		for service, calls := range counter.services {
+			total, ok := totals[service]
+			if !ok {
+				total = &ServiceCalls{Service: service}
+				totals[service] = total
+			}
+			total.Requests += calls.Requests
+			total.Throttled += calls.Throttled
+			total.Failed += calls.Failed
+		}
+		counter.mu.Unlock()
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+
+	overhead := &ScanOverhead{}
+	for _, calls := range totals {
+		overhead.Services = append(overhead.Services, *calls)
+		overhead.Requests += calls.Requests
+		overhead.Throttled += calls.Throttled
+		overhead.Failed += calls.Failed
+	}
+	sort.Slice(overhead.Services, func(i, j int) bool {
+		if overhead.Services[i].Requests != overhead.Services[j].Requests {
+			return overhead.Services[i].Requests > overhead.Services[j].Requests
+		}
+		return overhead.Services[i].Service < overhead.Services[j].Service
+	})
+	overhead.ThrottleRate = float64(overhead.Throttled) * 100 / float64(overhead.Requests)
+	return overhead
+}
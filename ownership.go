@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ProjectContacts lists who owns a project and who should be told about it
+type ProjectContacts struct {
+	ProjectID string `json:"project_id"`
+	// Owners are the members granted roles/owner, e.g. "user:alice@example.com"
+	Owners   []string  `json:"owners"`
+	Contacts []Contact `json:"contacts"`
+}
+
+// Contact is an Essential Contact and the notification categories it subscribes to
+type Contact struct {
+	Email      string   `json:"email"`
+	Categories []string `json:"categories"`
+}
+
+// collectContacts reads the project's owners and Essential Contacts
+func (c *GoogleAPIChecker) collectContacts() error {
+	if c.projectID == "" {
+		return fmt.Errorf("contact lookup requires a project ID")
+	}
+
+	contacts := &ProjectContacts{ProjectID: c.projectID}
+
+	// Either lookup may be denied on its own; keep whatever could be read
+	var problems []string
+	owners, err := c.getProjectOwners()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	contacts.Owners = owners
+
+	essential, err := c.getEssentialContacts()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	contacts.Contacts = essential
+
+	c.mu.Lock()
+	c.contacts = contacts
+	c.mu.Unlock()
+
+	if len(problems) > 0 {
+		return fmt.Errorf("contacts are partial: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Contacts returns the project's owners and Essential Contacts, nil when they were not collected
+func (c *GoogleAPIChecker) Contacts() *ProjectContacts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contacts
+}
+
+// getProjectOwners returns the members bound to roles/owner in the project's IAM policy
+func (c *GoogleAPIChecker) getProjectOwners() ([]string, error) {
+	var policy struct {
+		Bindings []struct {
+			Role    string   `json:"role"`
+			Members []string `json:"members"`
+		} `json:"bindings"`
+	}
+
+	requestURL := fmt.Sprintf("https://cloudresourcemanager.googleapis.com/v1/projects/%s:getIamPolicy", c.projectID)
+	if err := c.postJSON(requestURL, "project IAM policy", map[string]interface{}{}, &policy); err != nil {
+		return nil, err
+	}
+
+	var owners []string
+	for _, binding := range policy.Bindings {
+		if binding.Role == "roles/owner" {
+			owners = append(owners, binding.Members...)
+		}
+	}
+	sort.Strings(owners)
+	return owners, nil
+}
+
+// getEssentialContacts lists the project's Essential Contacts
+func (c *GoogleAPIChecker) getEssentialContacts() ([]Contact, error) {
+	var contacts []Contact
+	pageToken := ""
+	for {
+		requestURL := fmt.Sprintf("https://essentialcontacts.googleapis.com/v1/projects/%s/contacts", c.projectID)
+		if pageToken != "" {
+			requestURL += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var result struct {
+			Contacts []struct {
+				Email                             string   `json:"email"`
+				NotificationCategorySubscriptions []string `json:"notificationCategorySubscriptions"`
+			} `json:"contacts"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.getJSON(requestURL, "Essential Contacts", &result); err != nil {
+			return nil, err
+		}
+
+		for _, contact := range result.Contacts {
+			contacts = append(contacts, Contact{
+				Email:      contact.Email,
+				Categories: contact.NotificationCategorySubscriptions,
+			})
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return contacts, nil
+}
+
+// Recipients returns the email addresses subscribed to the notification category (or ALL),
+// falling back to the project's user owners when no contact is subscribed
+func (p ProjectContacts) Recipients(category string) []string {
+	var recipients []string
+	for _, contact := range p.Contacts {
+		for _, subscribed := range contact.Categories {
+			if subscribed == category || subscribed == "ALL" {
+				recipients = append(recipients, contact.Email)
+				break
+			}
+		}
+	}
+	if len(recipients) > 0 {
+		return recipients
+	}
+
+	for _, owner := range p.Owners {
+		if email, ok := strings.CutPrefix(owner, "user:"); ok {
+			recipients = append(recipients, email)
+		}
+	}
+	return recipients
+}
+
+// String summarizes the owners and contacts on one line
+func (p ProjectContacts) String() string {
+	var parts []string
+	if len(p.Owners) > 0 {
+		parts = append(parts, "owners: "+strings.Join(p.Owners, ", "))
+	}
+	for _, contact := range p.Contacts {
+		parts = append(parts, fmt.Sprintf("%s (%s)", contact.Email, strings.ToLower(strings.Join(contact.Categories, ", "))))
+	}
+	if len(parts) == 0 {
+		return "no owners or contacts found"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SetContacts records each project's owners and contacts on the report and addresses
+// findings to the contacts subscribed to their category
+func (r *Report) SetContacts(contacts []ProjectContacts) {
+	r.Contacts = contacts
+
+	byProject := make(map[string]ProjectContacts, len(contacts))
+	for _, project := range contacts {
+		byProject[project.ProjectID] = project
+	}
+	for i, finding := range r.Findings {
+		project, ok := byProject[finding.ProjectID]
+		if !ok {
+			continue
+		}
+		category := "TECHNICAL"
+		if finding.Category == "billing" {
+			category = "BILLING"
+		}
+		r.Findings[i].Recipients = project.Recipients(category)
+	}
+}
+
+// routeAlerts addresses each alert to the contacts of its project for the notification category
+func routeAlerts(alerts []Alert, contacts []ProjectContacts, category string) {
+	byProject := make(map[string]ProjectContacts, len(contacts))
+	for _, project := range contacts {
+		byProject[project.ProjectID] = project
+	}
+	for i := range alerts {
+		if project, ok := byProject[alerts[i].ProjectID]; ok {
+			alerts[i].Recipients = project.Recipients(category)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// pdfFont is the family name the embedded UTF-8 fonts are registered under
+const pdfFont = "DejaVu"
+
+//go:embed fonts/DejaVuSansCondensed.ttf
+var dejaVuRegular []byte
+
+//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+var dejaVuBold []byte
+
+//go:embed fonts/DejaVuSansCondensed-Oblique.ttf
+var dejaVuOblique []byte
+
+// pdfTOCEntry records where a section starts for the table of contents
+type pdfTOCEntry struct {
+	Title string
+	Page  int
+	Link  int
+}
+
+// newPDF creates an A4 document with the embedded UTF-8 fonts and a page-numbered footer
+func newPDF(orientation string) *gofpdf.Fpdf {
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", dejaVuRegular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", dejaVuBold)
+	pdf.AddUTF8FontFromBytes(pdfFont, "I", dejaVuOblique)
+	pdf.AliasNbPages("")
+
+	generatedAt := time.Now().Format("2006-01-02 15:04:05")
+	pdf.SetFooterFunc(func() {
+		pageWidth, _ := pdf.GetPageSize()
+		left, _, right, _ := pdf.GetMargins()
+		half := (pageWidth - left - right) / 2
+
+		pdf.SetY(-15)
+		pdf.SetFont(pdfFont, "I", 8)
+		pdf.CellFormat(half, 6, fmt.Sprintf("Generated by Google API Checker %s at %s", toolVersion(), generatedAt), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 6, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	return pdf
+}
+
+// addPDFSection writes a section heading and returns its table of contents entry
+func addPDFSection(pdf *gofpdf.Fpdf, title string) pdfTOCEntry {
+	title = pdfText(title)
+	link := pdf.AddLink()
+	pdf.SetLink(link, pdf.GetY(), pdf.PageNo())
+
+	pdf.SetFont(pdfFont, "B", 12)
+	pdf.Cell(190, 8, title)
+	pdf.Ln(10)
+
+	return pdfTOCEntry{Title: title, Page: pdf.PageNo(), Link: link}
+}
+
+// writePDFTableOfContents renders the collected sections on the current page
+func writePDFTableOfContents(pdf *gofpdf.Fpdf, toc []pdfTOCEntry) {
+	pdf.SetY(20)
+	pdf.SetFont(pdfFont, "B", 14)
+	pdf.Cell(190, 10, "Table of Contents")
+	pdf.Ln(14)
+
+	pdf.SetFont(pdfFont, "", 11)
+	for _, entry := range toc {
+		pageLabel := fmt.Sprintf("%d", entry.Page)
+		titleWidth := pdf.GetStringWidth(entry.Title)
+		labelWidth := pdf.GetStringWidth(pageLabel)
+		dotWidth := pdf.GetStringWidth(".")
+
+		dots := ""
+		if gap := 180 - titleWidth - labelWidth - 4; gap > 0 {
+			dots = strings.Repeat(".", int(gap/dotWidth))
+		}
+
+		pdf.CellFormat(180, 8, fmt.Sprintf("%s %s %s", entry.Title, dots, pageLabel), "", 1, "L", false, entry.Link, "")
+	}
+}
+
+// drawCostChart renders a horizontal bar chart of estimated cost per API
+func drawCostChart(pdf *gofpdf.Fpdf, breakdown map[string]float64) {
+	type costEntry struct {
+		name string
+		cost float64
+	}
+
+	var entries []costEntry
+	for name, cost := range breakdown {
+		if cost > 0 {
+			entries = append(entries, costEntry{name: pdfText(name), cost: cost})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].cost == entries[j].cost {
+			return entries[i].name < entries[j].name
+		}
+		return entries[i].cost > entries[j].cost
+	})
+
+	// Keep the chart on a single page; the rest share one bar, so the bars add up to the total
+	const maxBars = 25
+	if len(entries) > maxBars {
+		other := costEntry{name: fmt.Sprintf("Other (%d APIs)", len(entries)-maxBars+1)}
+		for _, entry := range entries[maxBars-1:] {
+			other.cost += entry.cost
+		}
+		entries = append(entries[:maxBars-1], other)
+	}
+
+	if len(entries) == 0 {
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.Cell(190, 6, "No APIs with an estimated cost above zero.")
+		pdf.Ln(6)
+		return
+	}
+
+	maxCost := 0.0
+	for _, entry := range entries {
+		maxCost = max(maxCost, entry.cost)
+	}
+	const (
+		labelWidth = 60.0
+		barMax     = 100.0
+		barHeight  = 6.0
+		rowHeight  = 9.0
+	)
+
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY() + 2
+
+	pdf.SetFont(pdfFont, "", 8)
+	for _, entry := range entries {
+		name := []rune(entry.name)
+		if len(name) > 35 {
+			name = append(name[:32], []rune("...")...)
+		}
+
+		pdf.SetXY(left, y)
+		pdf.CellFormat(labelWidth, barHeight, string(name), "", 0, "R", false, 0, "")
+
+		width := barMax * entry.cost / maxCost
+		if width < 0.5 {
+			width = 0.5
+		}
+		pdf.SetFillColor(124, 58, 237)
+		pdf.Rect(left+labelWidth+2, y, width, barHeight, "F")
+
+		pdf.SetXY(left+labelWidth+4+width, y)
+		pdf.CellFormat(25, barHeight, formatCost(entry.cost), "", 0, "L", false, 0, "")
+
+		y += rowHeight
+	}
+
+	pdf.SetFillColor(255, 255, 255)
+	pdf.SetY(y + 4)
+}
+
+// pdfRowHeight returns the height a word-wrapped table row needs
+func pdfRowHeight(pdf *gofpdf.Fpdf, widths []float64, cells []string, lineHeight float64) float64 {
+	maxLines := 1
+	for i, cell := range cells {
+		if lines := len(pdf.SplitText(cell, widths[i])); lines > maxLines {
+			maxLines = lines
+		}
+	}
+	return float64(maxLines) * lineHeight
+}
+
+// pdfRowFits reports whether a row fits above the footer on the current page
+func pdfRowFits(pdf *gofpdf.Fpdf, widths []float64, cells []string, lineHeight float64) bool {
+	_, pageHeight := pdf.GetPageSize()
+	return pdf.GetY()+pdfRowHeight(pdf, widths, cells, lineHeight) < pageHeight-20
+}
+
+// writePDFTableRow writes one bordered table row, wrapping long cell text
+func writePDFTableRow(pdf *gofpdf.Fpdf, widths []float64, cells []string, lineHeight float64) {
+	writePDFTableRowColored(pdf, widths, cells, lineHeight, nil)
+}
+
+// writePDFTableRowColored writes a table row, drawing the cells in colors in their RGB text color
+func writePDFTableRowColored(pdf *gofpdf.Fpdf, widths []float64, cells []string, lineHeight float64, colors map[int][3]int) {
+	rowHeight := pdfRowHeight(pdf, widths, cells, lineHeight)
+	x, y := pdf.GetXY()
+
+	for i, cell := range cells {
+		pdf.Rect(x, y, widths[i], rowHeight, "D")
+		pdf.SetXY(x, y)
+		if rgb, ok := colors[i]; ok {
+			pdf.SetTextColor(rgb[0], rgb[1], rgb[2])
+		}
+		pdf.MultiCell(widths[i], lineHeight, cell, "", "L", false)
+		if _, ok := colors[i]; ok {
+			pdf.SetTextColor(0, 0, 0)
+		}
+		x += widths[i]
+	}
+
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetXY(left, y+rowHeight)
+}
+
+// pdfGlyphs are the runes the embedded font has glyphs for, read once from its character map
+var pdfGlyphs = sync.OnceValue(func() [][2]rune {
+	return fontRuneRanges(dejaVuRegular)
+})
+
+// fontRuneRanges returns the rune ranges a TrueType font maps to glyphs, from its Unicode
+// format 12 cmap subtable, or nil when the font has none
+func fontRuneRanges(font []byte) [][2]rune {
+	u16 := func(at int) int {
+		if at < 0 || at+2 > len(font) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(font[at:]))
+	}
+	u32 := func(at int) int {
+		if at < 0 || at+4 > len(font) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint32(font[at:]))
+	}
+
+	cmap := 0
+	for i := 0; i < u16(4) && 12+16*(i+1) <= len(font); i++ {
+		if record := 12 + 16*i; string(font[record:record+4]) == "cmap" {
+			cmap = u32(record + 8)
+		}
+	}
+	if cmap == 0 {
+		return nil
+	}
+	for i := 0; i < u16(cmap+2); i++ {
+		record := cmap + 4 + 8*i
+		subtable := cmap + u32(record+4)
+		if u16(record) != 3 || u16(record+2) != 10 || u16(subtable) != 12 {
+			continue
+		}
+		var ranges [][2]rune
+		for group := 0; group < u32(subtable+12); group++ {
+			at := subtable + 16 + 12*group
+			if at+12 > len(font) {
+				break
+			}
+			ranges = append(ranges, [2]rune{rune(u32(at)), rune(u32(at + 4))})
+		}
+		return ranges
+	}
+	return nil
+}
+
+// pdfHasGlyph reports whether the embedded font can draw the rune
+func pdfHasGlyph(r rune) bool {
+	ranges := pdfGlyphs()
+	if ranges == nil {
+		return true
+	}
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i][1] >= r })
+	return i < len(ranges) && ranges[i][0] <= r
+}
+
+// pdfText removes emoji and other runes the embedded font has no glyph for, which the PDF
+// would otherwise show as blank boxes
+func pdfText(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == 0xFE0F || r == 0x200D:
+			return -1
+		case !unicode.IsPrint(r) && r != ' ':
+			return -1
+		case !pdfHasGlyph(r):
+			return -1
+		}
+		return r
+	}, s))
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestPDFText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Cloud Vision API", "Cloud Vision API"},
+		{"café Ω Ж → ⚠", "café Ω Ж → ⚠"},
+		{"🚨 Unlimited cost", "Unlimited cost"},
+		{"⚠️ quota", "⚠ quota"},
+		{"東京 maps", "maps"},
+		{"line\nbreak\x00", "linebreak"},
+	}
+	for _, test := range tests {
+		if got := pdfText(test.text); got != test.want {
+			t.Errorf("pdfText(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestFontRuneRanges(t *testing.T) {
+	if ranges := fontRuneRanges(dejaVuRegular); len(ranges) == 0 {
+		t.Fatal("no character map in the embedded font")
+	}
+	for _, font := range [][]byte{nil, []byte("not a font"), dejaVuRegular[:200]} {
+		if ranges := fontRuneRanges(font); ranges != nil {
+			t.Errorf("got %d ranges from a truncated font", len(ranges))
+		}
+	}
+}
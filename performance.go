@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// slowestChecks is how many of the slowest checks the performance section lists
+const slowestChecks = 5
+
+// errThrottled marks checks the API answered with HTTP 429
+var errThrottled = errors.New("throttled")
+
+// quotaExhaustedThrottles is how many checks in a row must be throttled before the quota is
+// considered exhausted and the remaining checks are skipped
+const quotaExhaustedThrottles = 5
+
+// PerformanceStats summarises how long the API checks took, including time waiting for --qps
+type PerformanceStats struct {
+	Checks    int   `json:"checks"`
+	P50Ms     int64 `json:"p50_ms"`
+	P95Ms     int64 `json:"p95_ms"`
+	MaxMs     int64 `json:"max_ms"`
+	TotalMs   int64 `json:"total_ms"`
+	Throttled int   `json:"throttled"`
+	// Slowest are the slowest checks, slowest first
+	Slowest []APIResult `json:"slowest"`
+}
+
+// apiStatusError describes a failed status request, wrapping errThrottled for HTTP 429
+func apiStatusError(code int) error {
+	if code == http.StatusTooManyRequests {
+		return fmt.Errorf("API request failed with status: %d (%w)", code, errThrottled)
+	}
+	return fmt.Errorf("API request failed with status: %d", code)
+}
+
+// computePerformance summarises the check durations, nil when the results carry none (e.g. older results files)
+func computePerformance(results []APIResult) *PerformanceStats {
+	var timed []APIResult
+	stats := &PerformanceStats{}
+	for _, result := range results {
+		if result.Throttled {
+			stats.Throttled++
+		}
+		if result.DurationMs > 0 {
+			timed = append(timed, result)
+			stats.TotalMs += result.DurationMs
+		}
+	}
+	if len(timed) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].DurationMs > timed[j].DurationMs
+	})
+	stats.Checks = len(timed)
+	stats.MaxMs = timed[0].DurationMs
+	stats.P50Ms = percentileMs(timed, 50)
+	stats.P95Ms = percentileMs(timed, 95)
+	if len(timed) > slowestChecks {
+		timed = timed[:slowestChecks]
+	}
+	stats.Slowest = timed
+	return stats
+}
+
+// percentileMs returns the nearest-rank percentile of durations sorted slowest first
+func percentileMs(sorted []APIResult, percentile int) int64 {
+	rank := (percentile*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[len(sorted)-rank].DurationMs
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// costPeriod is a period cost figures are shown for. Estimates are monthly; other periods are
+// projected from them, e.g. for --period annual
+type costPeriod struct {
+	Name   string  `json:"name"`
+	Suffix string  `json:"suffix"` // Appended to amounts, e.g. "/month"
+	Factor float64 `json:"factor"` // Multiplier from a monthly amount
+}
+
+// costPeriods are the periods --period accepts
+var costPeriods = []costPeriod{
+	{Name: "daily", Suffix: "/day", Factor: 12.0 / 365},
+	{Name: "monthly", Suffix: "/month", Factor: 1},
+	{Name: "annual", Suffix: "/year", Factor: 12},
+}
+
+// projection is the period the console, HTML, PDF and CSV output show costs for
+var projection = costPeriods[1]
+
+// ParseCostPeriod returns the period for a --period value
+func ParseCostPeriod(name string) (costPeriod, error) {
+	var names []string
+	for _, period := range costPeriods {
+		if strings.EqualFold(period.Name, name) {
+			return period, nil
+		}
+		names = append(names, period.Name)
+	}
+	return costPeriod{}, fmt.Errorf("unknown cost period %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// amount projects a monthly amount to the period
+func (p costPeriod) amount(monthly float64) float64 {
+	return monthly * p.Factor
+}
+
+// formatCost formats a monthly amount for the selected period, e.g. "$1200.00/year"
+func formatCost(monthly float64) string {
+	return fmt.Sprintf("$%.2f%s", projection.amount(monthly), projection.Suffix)
+}
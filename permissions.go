@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Scopes of the access a feature needs
+const (
+	accessScan        = "scan"
+	accessRemediation = "remediation"
+)
+
+// AccessRequirement is the IAM access one check module or feature needs
+type AccessRequirement struct {
+	Name string `json:"name"`
+	// Scope is scan (read-only) or remediation (changes the project)
+	Scope       string   `json:"scope"`
+	Flags       string   `json:"flags"`
+	Purpose     string   `json:"purpose"`
+	Permissions []string `json:"permissions"`
+	Roles       []string `json:"roles"`
+}
+
+// enablementAccess is what every scan needs to list the services and their states
+var enablementAccess = AccessRequirement{
+	Name: "enablement", Scope: accessScan, Flags: "(always)",
+	Purpose:     "List the project's services and check whether each is enabled, and quotas in the check subcommand",
+	Permissions: []string{"serviceusage.services.list", "serviceusage.services.get", "serviceusage.quotas.get"},
+	Roles:       []string{"roles/serviceusage.serviceUsageViewer"},
+}
+
+// featureAccess is the access of features outside the --checks modules
+var featureAccess = []AccessRequirement{
+	{Name: "environments", Scope: accessScan, Flags: "config environments, --expected environments, --allocate-by",
+		Purpose:     "Read the project's labels for its environment and cost allocation",
+		Permissions: []string{"resourcemanager.projects.get"}, Roles: []string{"roles/browser"}},
+	{Name: "billing-accounts", Scope: accessScan, Flags: "--billing-account",
+		Purpose:     "List the projects linked to the billing accounts",
+		Permissions: []string{"billing.resourceAssociations.list"}, Roles: []string{"roles/billing.viewer"}},
+	{Name: "unlimited-cost-budgets", Scope: accessScan, Flags: "config unlimited_cost.lookup_budgets",
+		Purpose:     "Look up the budget alerts that clear the unlimited-cost flag",
+		Permissions: []string{"resourcemanager.projects.get", "billing.budgets.list"}, Roles: []string{"roles/browser", "roles/billing.viewer"}},
+	{Name: "firebase", Scope: accessScan, Flags: "--profile firebase",
+		Purpose:     "Read the Firebase project's resources and registered apps",
+		Permissions: []string{"firebase.projects.get", "firebase.clients.list"}, Roles: []string{"roles/firebase.viewer"}},
+	{Name: "metrics", Scope: accessScan, Flags: "--publish-metrics",
+		Purpose:     "Write estimated cost and enablement as Cloud Monitoring custom metrics",
+		Permissions: []string{"monitoring.timeSeries.create"}, Roles: []string{"roles/monitoring.metricWriter"}},
+	{Name: "state-backend", Scope: accessScan, Flags: "--state-backend",
+		Purpose:     "Keep the scan history in a Cloud Storage bucket (granted on the bucket)",
+		Permissions: []string{"storage.objects.create", "storage.objects.get", "storage.objects.list"}, Roles: []string{"roles/storage.objectUser"}},
+	{Name: "secret-token", Scope: accessScan, Flags: "--token-from secretmanager:...",
+		Purpose:     "Read the token from Secret Manager (granted on the secret)",
+		Permissions: []string{"secretmanager.versions.access"}, Roles: []string{"roles/secretmanager.secretAccessor"}},
+	{Name: "apply", Scope: accessRemediation, Flags: "--apply",
+		Purpose:     "Enable the required APIs missing from the --expected manifest",
+		Permissions: []string{"serviceusage.services.enable"}, Roles: []string{"roles/serviceusage.serviceUsageAdmin"}},
+	{Name: "remediation-script", Scope: accessRemediation, Flags: "--generate-remediation (whoever runs the script)",
+		Purpose:     "Disable APIs, restrict API keys and create budgets",
+		Permissions: []string{"serviceusage.services.disable", "apikeys.keys.update", "billing.budgets.create"},
+		Roles:       []string{"roles/serviceusage.serviceUsageAdmin", "roles/serviceusage.apiKeysAdmin", "roles/billing.costsManager"}},
+}
+
+// accessRequirements lists the access of the enablement check, every --checks module and the other
+// features. Modules declare their own permissions, so this follows the checks the code runs.
+func accessRequirements() []AccessRequirement {
+	requirements := []AccessRequirement{enablementAccess}
+	for _, module := range checkModules {
+		flags := "--checks " + module.Name + ", " + module.Flags
+		if strings.HasPrefix(module.Flags, "(") {
+			flags = "--checks " + module.Name + " " + module.Flags
+		}
+		requirements = append(requirements, AccessRequirement{
+			Name:        module.Name,
+			Scope:       accessScan,
+			Flags:       flags,
+			Purpose:     module.Description,
+			Permissions: append([]string{}, module.Permissions...),
+			Roles:       append([]string{}, module.Roles...),
+		})
+	}
+	return append(requirements, featureAccess...)
+}
+
+// uniquePermissions lists the distinct permissions of the requirements in the scope, sorted
+func uniquePermissions(requirements []AccessRequirement, scope string) []string {
+	seen := make(map[string]bool)
+	var permissions []string
+	for _, requirement := range requirements {
+		if requirement.Scope != scope {
+			continue
+		}
+		for _, permission := range requirement.Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// newRequiredPermissionsCmd creates the subcommand that prints the IAM access each check needs
+func newRequiredPermissionsCmd() *cobra.Command {
+	var (
+		names      []string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "required-permissions",
+		Short: "Print the IAM permissions and roles each check module and feature needs",
+		Long: `Print the IAM permissions and predefined roles needed by the enablement check,
+each --checks module and the other features, split into read-only scan access
+and remediation access that changes projects. The list comes from the checks
+themselves, so it matches the release that prints it. The permissions of every
+scan feature are also printed as one list, e.g. for a custom role for the audit
+service account. Use --checks to limit the list to the modules a scan runs.`,
+		Example: "  googleapichecker required-permissions\n  googleapichecker required-permissions --checks cost,security --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			requirements := accessRequirements()
+			if len(names) > 0 {
+				selected := map[string]bool{enablementAccess.Name: true}
+				for _, name := range names {
+					name = strings.ToLower(strings.TrimSpace(name))
+					found := false
+					for _, module := range checkModules {
+						found = found || module.Name == name
+					}
+					if !found {
+						return fmt.Errorf("unknown check module %q (use %s)", name, strings.Join(checkModuleNames(), ", "))
+					}
+					selected[name] = true
+				}
+				filtered := requirements[:0]
+				for _, requirement := range requirements {
+					if selected[requirement.Name] {
+						filtered = append(filtered, requirement)
+					}
+				}
+				requirements = filtered
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(map[string]interface{}{
+					"version":                 toolVersion(),
+					"requirements":            requirements,
+					"scan_permissions":        uniquePermissions(requirements, accessScan),
+					"remediation_permissions": uniquePermissions(requirements, accessRemediation),
+				})
+			}
+
+			for _, scope := range []struct{ name, title string }{
+				{accessScan, "🔍 SCAN (read-only)"},
+				{accessRemediation, "🛠️  REMEDIATION (changes projects)"},
+			} {
+				permissions := uniquePermissions(requirements, scope.name)
+				if len(permissions) == 0 {
+					continue
+				}
+				fmt.Printf("%s\n\n", scope.title)
+				for _, requirement := range requirements {
+					if requirement.Scope != scope.name {
+						continue
+					}
+					fmt.Printf("   %s: %s\n", requirement.Name, requirement.Purpose)
+					fmt.Printf("      Flags: %s\n", requirement.Flags)
+					if len(requirement.Permissions) == 0 {
+						fmt.Println("      No Google Cloud permissions needed")
+					} else {
+						fmt.Printf("      Permissions: %s\n", strings.Join(requirement.Permissions, ", "))
+						fmt.Printf("      Roles: %s\n", strings.Join(requirement.Roles, ", "))
+					}
+					fmt.Println()
+				}
+				fmt.Printf("   All %s permissions (%d):\n", scope.name, len(permissions))
+				for _, permission := range permissions {
+					fmt.Printf("      %s\n", permission)
+				}
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&names, "checks", nil, "Only list the enablement check and these modules: "+strings.Join(checkModuleNames(), ", "))
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the requirements as JSON")
+	return cmd
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Default cost thresholds used when the configuration does not set them
+const (
+	defaultHighCostThreshold  = 50.0
+	defaultTotalCostThreshold = 500.0
+)
+
+// Thresholds are the cost limits used to flag APIs and totals; zero means inherit
+type Thresholds struct {
+	HighCost  float64 `yaml:"high_cost" json:"high_cost"`
+	TotalCost float64 `yaml:"total_cost" json:"total_cost"`
+	// MediumCost and CriticalCost bound the MEDIUM and CRITICAL cost tiers, derived from HighCost when unset
+	MediumCost   float64 `yaml:"medium_cost" json:"medium_cost,omitempty"`
+	CriticalCost float64 `yaml:"critical_cost" json:"critical_cost,omitempty"`
+}
+
+// EnvironmentPolicy holds thresholds and rules for projects in one environment
+type EnvironmentPolicy struct {
+	Thresholds Thresholds `yaml:"thresholds"`
+	// DisallowedAPIs must not be enabled in this environment
+	DisallowedAPIs []string `yaml:"disallowed_apis"`
+}
+
+// PolicyViolation is an enabled API that breaks an environment rule
+type PolicyViolation struct {
+	ProjectID   string `json:"project_id,omitempty"`
+	Environment string `json:"environment"`
+	API         string `json:"api,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+	// Ticket is the ServiceNow record tracking the violation, with --servicenow
+	Ticket string `json:"ticket,omitempty"`
+}
+
+// Policy resolves thresholds and rules for results based on their environment
+type Policy struct {
+	Defaults     Thresholds
+	Environments map[string]EnvironmentPolicy
+	// Recommendations are the rules that add report recommendations
+	Recommendations []*compiledRule
+}
+
+// defaultRules are the built-in recommendation rules, compiled once
+var defaultRules, _ = compileRecommendationRules(RecommendationsConfig{})
+
+// DefaultPolicy returns the built-in thresholds and recommendation rules with no environment rules
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Defaults: Thresholds{
+			HighCost:  defaultHighCostThreshold,
+			TotalCost: defaultTotalCostThreshold,
+		},
+		Recommendations: defaultRules,
+	}
+}
+
+// PolicyFromConfig builds a policy from the configuration, filling unset values with defaults
+func PolicyFromConfig(config *Config) *Policy {
+	policy := DefaultPolicy()
+	if config == nil {
+		return policy
+	}
+
+	policy.Defaults = mergeThresholds(config.Thresholds, policy.Defaults)
+	policy.Environments = config.Environments
+	if config.recommendationRules != nil {
+		policy.Recommendations = config.recommendationRules
+	}
+	return policy
+}
+
+// mergeThresholds fills zero values in t from fallback
+func mergeThresholds(t, fallback Thresholds) Thresholds {
+	if t.HighCost == 0 {
+		t.HighCost = fallback.HighCost
+	}
+	if t.TotalCost == 0 {
+		t.TotalCost = fallback.TotalCost
+	}
+	if t.MediumCost == 0 {
+		t.MediumCost = fallback.MediumCost
+	}
+	if t.CriticalCost == 0 {
+		t.CriticalCost = fallback.CriticalCost
+	}
+	return t
+}
+
+// ThresholdsFor returns the effective thresholds for an environment
+func (p *Policy) ThresholdsFor(environment string) Thresholds {
+	if env, ok := p.Environments[environment]; ok && environment != "" {
+		return mergeThresholds(env.Thresholds, p.Defaults)
+	}
+	return p.Defaults
+}
+
+// Violations checks enabled results against the environment rules and per-project cost limits
+func (p *Policy) Violations(results []APIResult) []PolicyViolation {
+	var violations []PolicyViolation
+	projectCosts := make(map[string]float64)
+	projectEnvironments := make(map[string]string)
+
+	for _, result := range results {
+		if !result.Enabled || result.Environment == "" {
+			continue
+		}
+
+		env, ok := p.Environments[result.Environment]
+		if !ok {
+			continue
+		}
+
+		if result.CostInfo.HasPricing {
+			projectCosts[result.ProjectID] += result.CostInfo.EstimatedCost
+			projectEnvironments[result.ProjectID] = result.Environment
+		}
+
+		for _, api := range env.DisallowedAPIs {
+			if normalizeServiceName(api) != result.Name {
+				continue
+			}
+			violations = append(violations, PolicyViolation{
+				ProjectID:   result.ProjectID,
+				Environment: result.Environment,
+				API:         result.Name,
+				DisplayName: result.DisplayName,
+				Rule:        "disallowed_api",
+				Message:     fmt.Sprintf("%s is enabled but not allowed in %s environments", result.DisplayName, result.Environment),
+			})
+		}
+	}
+
+	for projectID, cost := range projectCosts {
+		environment := projectEnvironments[projectID]
+		limit := p.ThresholdsFor(environment).TotalCost
+		if cost <= limit {
+			continue
+		}
+		violations = append(violations, PolicyViolation{
+			ProjectID:   projectID,
+			Environment: environment,
+			Rule:        "total_cost",
+			Message:     fmt.Sprintf("estimated cost $%.2f exceeds the %s limit of $%.2f", cost, environment, limit),
+		})
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].ProjectID != violations[j].ProjectID {
+			return violations[i].ProjectID < violations[j].ProjectID
+		}
+		return violations[i].API < violations[j].API
+	})
+
+	return violations
+}
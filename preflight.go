@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// preflight checks with one cheap Service Usage call that the project exists and the credential
+// is accepted, so a bad --project or --token fails fast instead of erroring on every API
+func (c *GoogleAPIChecker) preflight() error {
+	// Simulated scans, scans without a project and other backends have nothing to validate
+	if !c.liveProject() {
+		return nil
+	}
+
+	usage, err := c.serviceUsage()
+	if err != nil {
+		return err
+	}
+	_, err = usage.ListServices(context.Background(), "projects/"+c.projectID, "", 1, "")
+	if err == nil {
+		return nil
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("cannot reach the Service Usage API: %v (check your network or proxy settings)", err)
+	}
+
+	detail := ""
+	if message := strings.TrimSpace(apiErr.Message); message != "" {
+		detail = fmt.Sprintf(" (Google said: %s)", redactSecrets(message))
+	}
+
+	switch apiErr.Code {
+	case 400:
+		return fmt.Errorf("the token was rejected as invalid%s; check --token or --token-from", detail)
+	case 401:
+		return fmt.Errorf("the token is not accepted%s; check that it is current, or run \"auth login\" again", detail)
+	case 403:
+		return fmt.Errorf("the token may not read project %s%s; check that the project ID is right, that the Service Usage API is enabled and that the credential has serviceusage.services.list", c.projectID, detail)
+	case 404:
+		return fmt.Errorf("project %s was not found%s; check the --project ID", c.projectID, detail)
+	}
+
+	// Throttling and server errors may clear up, so let the scan try
+	return nil
+}
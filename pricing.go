@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// unitPrice is the list price for a quantity of an API's billing unit
+type unitPrice struct {
+	Unit  string  // Billing unit expected usage is given in, e.g. "requests" or "GB"
+	Per   float64 // Number of units the price applies to
+	Price float64 // USD per Per units
+	// FreeTier is the monthly quantity, in Unit, that Google does not bill
+	FreeTier float64
+}
+
+// unitPrices are the list prices and monthly free allowances used to turn expected usage into estimates
+var unitPrices = map[string]unitPrice{
+	"compute.googleapis.com":        {Unit: "hours", Per: 1, Price: 0.05, FreeTier: 720},
+	"storage.googleapis.com":        {Unit: "GB", Per: 1, Price: 0.02, FreeTier: 5},
+	"bigquery.googleapis.com":       {Unit: "TB", Per: 1, Price: 6.25, FreeTier: 1},
+	"pubsub.googleapis.com":         {Unit: "messages", Per: 1000000, Price: 0.40},
+	"cloudfunctions.googleapis.com": {Unit: "invocations", Per: 1000000, Price: 0.40, FreeTier: 2000000},
+	"firestore.googleapis.com":      {Unit: "reads", Per: 100000, Price: 0.06, FreeTier: 1500000},
+	"datastore.googleapis.com":      {Unit: "reads", Per: 100000, Price: 0.06, FreeTier: 1500000},
+	"maps.googleapis.com":           {Unit: "requests", Per: 1000, Price: 5.00, FreeTier: 10000},
+	"places.googleapis.com":         {Unit: "requests", Per: 1000, Price: 17.00, FreeTier: 10000},
+	"geocoding.googleapis.com":      {Unit: "requests", Per: 1000, Price: 5.00, FreeTier: 10000},
+	"cloudsql.googleapis.com":       {Unit: "hours", Per: 1, Price: 0.10},
+	"container.googleapis.com":      {Unit: "hours", Per: 1, Price: 0.10, FreeTier: 744},
+	"vision.googleapis.com":         {Unit: "requests", Per: 1000, Price: 1.50, FreeTier: 1000},
+	"speech.googleapis.com":         {Unit: "minutes", Per: 1, Price: 0.024, FreeTier: 60},
+	"translate.googleapis.com":      {Unit: "characters", Per: 1000000, Price: 20.00, FreeTier: 500000},
+	"dataflow.googleapis.com":       {Unit: "vCPU hours", Per: 1, Price: 0.06},
+	"dataproc.googleapis.com":       {Unit: "vCPU hours", Per: 1, Price: 0.10},
+	"analytics.googleapis.com":      {Unit: "requests", Per: 1000, Price: 0.50},
+	"appengine.googleapis.com":      {Unit: "hours", Per: 1, Price: 0.05, FreeTier: 840},
+}
+
+// storageUnitsInGB converts storage suffixes to GB
+var storageUnitsInGB = map[string]float64{
+	"MB": 1.0 / 1024,
+	"GB": 1,
+	"TB": 1024,
+	"PB": 1024 * 1024,
+}
+
+// countMultipliers are the accepted magnitude suffixes for counts
+var countMultipliers = map[string]float64{
+	"":  1,
+	"K": 1e3,
+	"M": 1e6,
+	"B": 1e9,
+}
+
+// parseUsageQuantity parses an expected monthly usage such as "2M requests/month" or "500GB"
+// into the given billing unit
+func parseUsageQuantity(value, unit string) (float64, error) {
+	value = strings.TrimSpace(value)
+	end := 0
+	for end < len(value) && (value[end] >= '0' && value[end] <= '9' || value[end] == '.' || value[end] == ',' || value[end] == '_') {
+		end++
+	}
+	if end == 0 {
+		return 0, fmt.Errorf("invalid usage %q: must start with a number", value)
+	}
+
+	number, err := strconv.ParseFloat(strings.NewReplacer(",", "", "_", "").Replace(value[:end]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid usage %q: %v", value, err)
+	}
+
+	// The suffix is the first word after the number; a separate word that is not a
+	// known suffix is descriptive, e.g. "720 hours"
+	rest := value[end:]
+	suffix := strings.TrimLeft(rest, " ")
+	if i := strings.IndexAny(suffix, " /"); i >= 0 {
+		suffix = suffix[:i]
+	}
+	suffix = strings.ToUpper(suffix)
+	_, isStorage := storageUnitsInGB[suffix]
+	_, isMultiplier := countMultipliers[suffix]
+	if !isStorage && !isMultiplier && strings.HasPrefix(rest, " ") {
+		suffix = ""
+	}
+
+	if gb, ok := storageUnitsInGB[suffix]; ok {
+		perUnit, ok := storageUnitsInGB[unit]
+		if !ok {
+			return 0, fmt.Errorf("invalid usage %q: storage size given but the API is billed in %s", value, unit)
+		}
+		return number * gb / perUnit, nil
+	}
+
+	multiplier, ok := countMultipliers[suffix]
+	if !ok {
+		return 0, fmt.Errorf("invalid usage %q: unknown suffix %q (use K, M, B or MB, GB, TB, PB)", value, suffix)
+	}
+	return number * multiplier, nil
+}
+
+// String describes the price, e.g. "$5.00 per 1K requests" or "$0.05 per hour"
+func (p unitPrice) String() string {
+	price := fmt.Sprintf("%.2f", p.Price)
+	if p.Price < 0.1 {
+		// Keep sub-cent precision for per-unit prices such as $0.024 per minute
+		price = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	}
+	if p.Per == 1 {
+		return fmt.Sprintf("$%s per %s", price, strings.TrimSuffix(p.Unit, "s"))
+	}
+	return fmt.Sprintf("$%s per %s %s", price, formatQuantity(p.Per), p.Unit)
+}
+
+// estimateCost prices an expected monthly quantity after subtracting the free tier
+func (p unitPrice) estimateCost(quantity float64) float64 {
+	billable := quantity - p.FreeTier
+	if billable <= 0 {
+		return 0
+	}
+	return billable / p.Per * p.Price
+}
+
+// formatQuantity prints a usage quantity compactly, e.g. 2000000 as "2M"
+func formatQuantity(quantity float64) string {
+	switch {
+	case quantity >= 1e9:
+		return strconv.FormatFloat(quantity/1e9, 'f', -1, 64) + "B"
+	case quantity >= 1e6:
+		return strconv.FormatFloat(quantity/1e6, 'f', -1, 64) + "M"
+	case quantity >= 1e3:
+		return strconv.FormatFloat(quantity/1e3, 'f', -1, 64) + "K"
+	default:
+		return strconv.FormatFloat(quantity, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// PricingObserver is implemented by progress observers that follow the pricing pool separately.
+// OnAPIChecked may be called from several workers at once.
+type PricingObserver interface {
+	// OnAPIChecked is called when an API's status check finishes and it is queued for pricing;
+	// its OnAPIDone follows once it is priced
+	OnAPIChecked(event APIEvent)
+}
+
+// pricingThreads is the size of the pricing pool
+func (c *GoogleAPIChecker) pricingThreads() int {
+	if c.options.PricingThreads > 0 {
+		return c.options.PricingThreads
+	}
+	return max(c.threads, 1)
+}
+
+// pricingWorker prices checked APIs and passes them on to the collector, so slow pricing
+// lookups do not hold up the status checks
+func (c *GoogleAPIChecker) pricingWorker(id int, wg *sync.WaitGroup, checked <-chan APIEvent, results chan<- APIEvent) {
+	defer wg.Done()
+
+	for event := range checked {
+		c.priceResult(withTracePricer(context.Background(), id), event.Result)
+		results <- event
+	}
+}
+
+// priceResult adds the cost information of a checked API; failed and skipped checks are not priced
+func (c *GoogleAPIChecker) priceResult(ctx context.Context, result *APIResult) {
+	if result.Error != "" || result.Status == statusSkipped {
+		return
+	}
+
+	costInfo, err := c.pricing.CostInfo(ctx, result.Name)
+	if err != nil {
+		c.trace(ctx, "%s: no pricing: %v", result.Name, err)
+		result.CostInfo = CostInfo{
+			HasPricing: false,
+		}
+		return
+	}
+	result.CostInfo = c.applyExpectedUsage(result.Name, costInfo)
+	if result.CostInfo.HasPricing {
+		result.CostInfo.SKUs = skuBreakdown(result.Name, result.CostInfo.EstimatedCost)
+	}
+	c.classifyUnlimitedCost(ctx, result)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ScanProfile narrows a scan to a group of related services
+type ScanProfile struct {
+	Name        string
+	Description string
+	Services    []string
+	// RiskNotes explains why a service is a common abuse target when it is enabled
+	RiskNotes map[string]string
+	// Inspect runs profile-specific project checks after the services are scanned
+	Inspect func(c *GoogleAPIChecker) error
+}
+
+// scanProfiles are the profiles selectable with --profile
+var scanProfiles = map[string]*ScanProfile{
+	"firebase": {
+		Name:        "firebase",
+		Description: "Firebase and Identity Platform services",
+		Services: []string{
+			"firebase.googleapis.com",
+			"firebaseappcheck.googleapis.com",
+			"firebaseappdistribution.googleapis.com",
+			"firebasedatabase.googleapis.com",
+			"firebasedynamiclinks.googleapis.com",
+			"firebasehosting.googleapis.com",
+			"firebaseinstallations.googleapis.com",
+			"firebaseml.googleapis.com",
+			"firebaseremoteconfig.googleapis.com",
+			"firebaserules.googleapis.com",
+			"firebasestorage.googleapis.com",
+			"fcm.googleapis.com",
+			"fcmregistrations.googleapis.com",
+			"firestore.googleapis.com",
+			"identitytoolkit.googleapis.com",
+			"securetoken.googleapis.com",
+			"cloudfunctions.googleapis.com",
+			"storage.googleapis.com",
+		},
+		RiskNotes: map[string]string{
+			"identitytoolkit.googleapis.com":      "Sign-up and phone auth endpoints are abused for fake accounts and SMS pumping; restrict the API key and enable App Check",
+			"securetoken.googleapis.com":          "Token refresh is callable with the public API key; pair it with App Check and short session lifetimes",
+			"firebasedynamiclinks.googleapis.com": "Dynamic Links are abused for phishing redirects; restrict allowed URL patterns",
+			"fcm.googleapis.com":                  "Leaked server credentials allow spam push notifications to all users",
+			"firebasedatabase.googleapis.com":     "Open security rules expose the whole database; review rules before launch",
+			"firestore.googleapis.com":            "Open security rules expose documents to anyone with the API key",
+			"firebasestorage.googleapis.com":      "Public bucket rules allow free hosting of abusive content at your cost",
+		},
+		Inspect: (*GoogleAPIChecker).inspectFirebaseProject,
+	},
+}
+
+// LookupProfile returns the named scan profile
+func LookupProfile(name string) (*ScanProfile, error) {
+	profile, ok := scanProfiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// profileNames lists the available profile names in order
+func profileNames() []string {
+	names := make([]string, 0, len(scanProfiles))
+	for name := range scanProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// annotateRisk sets RiskNote on enabled results the profile considers abuse targets
+func (p *ScanProfile) annotateRisk(results []APIResult) {
+	for i := range results {
+		if note, ok := p.RiskNotes[results[i].Name]; ok && results[i].Enabled {
+			results[i].RiskNote = note
+		}
+	}
+}
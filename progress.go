@@ -2,42 +2,162 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
 )
 
+// progressMode controls how progress is rendered
+type progressMode int
+
+const (
+	progressBarMode  progressMode = iota // Animated bar redrawn in place with \r
+	progressLineMode                     // Periodic plain lines for CI logs and containers
+	progressOffMode                      // No progress output
+)
+
+// progressLineInterval is how often line-based progress is printed
+const progressLineInterval = 5 * time.Second
+
 // ProgressBar represents a progress bar for API checking
 type ProgressBar struct {
 	total        int
 	current      int
+	checked      int // Status checks finished, set when costs are looked up by the pricing pool
+	enabled      int
+	disabled     int
+	errors       int
+	cost         float64
+	verbose      bool
 	mu           sync.Mutex
 	startTime    time.Time
+	lastLine     time.Time
 	spinner      []string
 	spinnerIndex int
+	mode         progressMode
+	label        string
+}
+
+// ProgressOptions configures how a ProgressBar renders
+type ProgressOptions struct {
+	Disabled bool   // No progress output at all
+	LineMode bool   // Force periodic plain lines even on a terminal
+	Verbose  bool   // Show per-worker status
+	Label    string // Prefix for every line, e.g. the project ID
 }
 
-// NewProgressBar creates a new progress bar
-func NewProgressBar(total int) *ProgressBar {
+// NewProgressBar creates a new progress bar, falling back to line output when stdout is not a terminal
+func NewProgressBar(total int, options ProgressOptions) *ProgressBar {
+	mode := progressBarMode
+	if options.Disabled {
+		mode = progressOffMode
+	} else if options.LineMode || !isTerminal(os.Stdout) {
+		mode = progressLineMode
+	}
+
+	label := ""
+	if options.Label != "" {
+		label = fmt.Sprintf("[%s] ", options.Label)
+	}
+
 	return &ProgressBar{
 		total:        total,
 		current:      0,
 		startTime:    time.Now(),
-		spinner:      []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		lastLine:     time.Now(),
+		spinner:      spinnerFrames(),
 		spinnerIndex: 0,
+		mode:         mode,
+		verbose:      options.Verbose,
+		label:        label,
+	}
+}
+
+// isTerminal reports whether the file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
-// Update updates the progress bar
-func (p *ProgressBar) Update() {
+// Update records a finished check and updates the progress output
+func (p *ProgressBar) Update(result APIResult) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	p.current++
+	switch {
+	case result.Error != "":
+		p.errors++
+	case result.Enabled:
+		p.enabled++
+		if result.CostInfo.HasPricing {
+			p.cost += result.CostInfo.EstimatedCost
+		}
+	default:
+		p.disabled++
+	}
 	p.spinnerIndex = (p.spinnerIndex + 1) % len(p.spinner)
 
+	if p.mode == progressLineMode {
+		// Print periodically, and always for the final check
+		if time.Since(p.lastLine) < progressLineInterval && p.current < p.total {
+			return
+		}
+		p.lastLine = time.Now()
+	}
+
+	p.render()
+}
+
+// Checked records a finished status check waiting for the pricing pool
+func (p *ProgressBar) Checked() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checked++
+	// Line mode prints the pricing progress with the next finished check
+	if p.mode == progressBarMode {
+		p.render()
+	}
+}
+
+// WorkerStatus reports what a worker is doing; it is only shown in verbose mode
+func (p *ProgressBar) WorkerStatus(worker int, message string) {
+	if !p.verbose {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.mode {
+	case progressOffMode:
+		return
+	case progressLineMode:
+		fmt.Fprintf(console, "%s[worker %d] %s\n", p.label, worker, message)
+	default:
+		// Print the status above the bar, then redraw the bar
+		ClearLine()
+		fmt.Fprintf(console, "%s[worker %d] %s\n", p.label, worker, message)
+		p.render()
+	}
+}
+
+// render prints the current progress; callers must hold the lock
+func (p *ProgressBar) render() {
+	if p.mode == progressOffMode {
+		return
+	}
+
 	// Calculate progress percentage
-	percentage := float64(p.current) / float64(p.total) * 100
+	percentage := 0.0
+	if p.total > 0 {
+		percentage = float64(p.current) / float64(p.total) * 100
+	}
 
 	// Calculate elapsed time
 	elapsed := time.Since(p.startTime)
@@ -48,18 +168,39 @@ func (p *ProgressBar) Update() {
 		eta = time.Duration(float64(elapsed) * float64(p.total-p.current) / float64(p.current))
 	}
 
+	if p.mode == progressLineMode {
+		fmt.Fprintf(console, "%s%d/%d checked, %d enabled, %d disabled, %d errors, $%.2f estimated, ETA %s\n",
+			p.label,
+			p.current, p.total, p.enabled, p.disabled, p.errors, p.cost, formatDuration(eta))
+		if p.checked > 0 {
+			fmt.Fprintf(console, "%spricing: %d/%d checked APIs priced, %d waiting\n", p.label, p.current, p.checked, p.checked-p.current)
+		}
+		return
+	}
+
+	pricing := ""
+	if p.checked > 0 {
+		pricing = fmt.Sprintf(" | 💲 %d/%d priced", p.current, p.checked)
+	}
+
 	// Create progress bar
 	barWidth := 30
 	filled := int(float64(barWidth) * percentage / 100)
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 
 	// Clear line and print progress
-	fmt.Printf("\r%s Scanning APIs... [%s] %d/%d (%.1f%%) | Elapsed: %s | ETA: %s",
+	fmt.Fprintf(console, "\r%s %sScanning APIs... [%s] %d/%d (%.1f%%) | ✅ %d ⛔ %d ❌ %d | $%.2f%s | Elapsed: %s | ETA: %s",
 		p.spinner[p.spinnerIndex],
+		p.label,
 		bar,
 		p.current,
 		p.total,
 		percentage,
+		p.enabled,
+		p.disabled,
+		p.errors,
+		p.cost,
+		pricing,
 		formatDuration(elapsed),
 		formatDuration(eta))
 }
@@ -71,8 +212,15 @@ func (p *ProgressBar) Complete() {
 
 	elapsed := time.Since(p.startTime)
 
-	// Clear line and print completion message
-	fmt.Printf("\r✅ Scanning completed! %d APIs checked in %s\n", p.total, formatDuration(elapsed))
+	switch p.mode {
+	case progressOffMode:
+		return
+	case progressLineMode:
+		fmt.Fprintf(console, "%s✅ Scanning completed! %d APIs checked in %s\n", p.label, p.total, formatDuration(elapsed))
+	default:
+		// Clear line and print completion message
+		fmt.Fprintf(console, "\r%s✅ Scanning completed! %d APIs checked in %s\n", p.label, p.total, formatDuration(elapsed))
+	}
 }
 
 // formatDuration formats duration in a human-readable way
@@ -91,16 +239,16 @@ func formatDuration(d time.Duration) string {
 
 // LoadingSpinner shows a simple loading spinner
 func LoadingSpinner(message string, done chan bool) {
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	spinner := spinnerFrames()
 	i := 0
 
 	for {
 		select {
 		case <-done:
-			fmt.Printf("\r%s Done!\n", strings.Repeat(" ", len(message)+10))
+			fmt.Fprintf(console, "\r%s Done!\n", strings.Repeat(" ", len(message)+10))
 			return
 		default:
-			fmt.Printf("\r%s %s", spinner[i], message)
+			fmt.Fprintf(console, "\r%s %s", spinner[i], message)
 			time.Sleep(100 * time.Millisecond)
 			i = (i + 1) % len(spinner)
 		}
@@ -110,11 +258,11 @@ func LoadingSpinner(message string, done chan bool) {
 // StatusUpdate shows a status update with timestamp
 func StatusUpdate(message string) {
 	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("[%s] %s\n", timestamp, message)
+	fmt.Fprintf(console, "[%s] %s\n", timestamp, message)
 }
 
 // ClearLine clears the current line
 func ClearLine() {
-	fmt.Printf("\r%s", strings.Repeat(" ", 100))
-	fmt.Printf("\r")
+	fmt.Fprintf(console, "\r%s", strings.Repeat(" ", 140))
+	fmt.Fprintf(console, "\r")
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Sources of the raw responses kept with --include-raw
+const (
+	rawServiceUsage = "serviceusage"
+	rawBilling      = "billing"
+)
+
+// includeRaw keeps the raw Service Usage and billing export responses of each API, set from --include-raw
+var includeRaw bool
+
+// recordRaw keeps a response an API's result is read from when raw responses are included.
+// Bodies that are not JSON, e.g. HTML error pages, are kept as a JSON string.
+func (c *GoogleAPIChecker) recordRaw(apiName, source string, data []byte) {
+	if !c.options.IncludeRaw {
+		return
+	}
+	raw := json.RawMessage(append([]byte(nil), data...))
+	if !json.Valid(data) {
+		raw, _ = json.Marshal(string(data))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.raw == nil {
+		c.raw = make(map[string]map[string][]json.RawMessage)
+	}
+	if c.raw[apiName] == nil {
+		c.raw[apiName] = make(map[string][]json.RawMessage)
+	}
+	c.raw[apiName][source] = append(c.raw[apiName][source], raw)
+}
+
+// recordRawJSON keeps a response decoded by a typed client, encoded again as JSON
+func (c *GoogleAPIChecker) recordRawJSON(apiName, source string, v json.Marshaler) {
+	if !c.options.IncludeRaw {
+		return
+	}
+	if data, err := v.MarshalJSON(); err == nil {
+		c.recordRaw(apiName, source, data)
+	}
+}
+
+// attachRaw moves the responses recorded for the result's API onto the result
+func (c *GoogleAPIChecker) attachRaw(result *APIResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	responses, ok := c.raw[result.Name]
+	if !ok {
+		return
+	}
+	delete(c.raw, result.Name)
+	if result.Raw == nil {
+		result.Raw = make(map[string][]json.RawMessage)
+	}
+	for source, raw := range responses {
+		result.Raw[source] = append(result.Raw[source], raw...)
+	}
+}
+
+// writeRawResponses writes the raw responses of each result to dir/PROJECT/API.json and returns how many files it wrote
+func writeRawResponses(dir string, results []APIResult) (int, error) {
+	written := 0
+	for _, result := range results {
+		if len(result.Raw) == 0 {
+			continue
+		}
+		project := result.ProjectID
+		if project == "" {
+			project = "default"
+		}
+		projectDir := filepath.Join(dir, sanitizeFilename(project))
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			return written, fmt.Errorf("failed to create raw response directory: %v", err)
+		}
+
+		data, err := json.MarshalIndent(struct {
+			ProjectID string                       `json:"project_id,omitempty"`
+			Name      string                       `json:"name"`
+			Status    string                       `json:"status"`
+			Responses map[string][]json.RawMessage `json:"responses"`
+		}{result.ProjectID, result.Name, result.Status, result.Raw}, "", "  ")
+		if err != nil {
+			return written, fmt.Errorf("failed to encode raw responses of %s: %v", result.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(projectDir, sanitizeFilename(result.Name)+".json"), data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write raw responses: %v", err)
+		}
+		written++
+	}
+	return written, nil
+}
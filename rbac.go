@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/idtoken"
+)
+
+// role is what a scan API client may do; each role includes the ones below it
+type role int
+
+const (
+	roleViewer   role = iota + 1 // read scans, reports, events and dashboards
+	roleOperator                 // also start and cancel scans
+	roleAdmin                    // also manage the Google API credentials scans use
+)
+
+// roleNames maps the role names used in the config file to roles
+var roleNames = map[string]role{
+	"viewer":   roleViewer,
+	"operator": roleOperator,
+	"admin":    roleAdmin,
+}
+
+func (r role) String() string {
+	for name, value := range roleNames {
+		if value == r {
+			return name
+		}
+	}
+	return "none"
+}
+
+// parseRole converts a role name from the config file
+func parseRole(name string) (role, error) {
+	if r, ok := roleNames[strings.ToLower(name)]; ok {
+		return r, nil
+	}
+	return 0, fmt.Errorf("unknown role %q (use viewer, operator or admin)", name)
+}
+
+// AccessConfig grants a role on the scan API to the clients sending a bearer token or to a Google
+// identity signing in with an OIDC ID token
+type AccessConfig struct {
+	// Role is viewer, operator or admin
+	Role string `yaml:"role"`
+	// TokenFrom is where the bearer token is read from: env:VAR, file:path or secretmanager:...
+	TokenFrom string `yaml:"token_from"`
+	// Email is a Google account or service account, or *@domain for every account of a domain;
+	// needs oidc_audience
+	Email string `yaml:"email"`
+}
+
+// accessGrant is a loaded AccessConfig
+type accessGrant struct {
+	role  role
+	token string
+	email string
+}
+
+// matchesEmail reports whether the grant covers a verified email address
+func (g accessGrant) matchesEmail(email string) bool {
+	if g.email == "" || email == "" {
+		return false
+	}
+	if domain, ok := strings.CutPrefix(g.email, "*@"); ok {
+		return strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(domain))
+	}
+	return strings.EqualFold(g.email, email)
+}
+
+// loadGrants resolves the tokens of access entries; owners maps each token already in use to its
+// owner, so no token is granted twice
+func loadGrants(entries []AccessConfig, owner string, owners map[string]string) ([]accessGrant, error) {
+	grants := make([]accessGrant, 0, len(entries))
+	for i, entry := range entries {
+		r, err := parseRole(entry.Role)
+		if err != nil {
+			return nil, fmt.Errorf("access %d: %v", i+1, err)
+		}
+		if (entry.TokenFrom == "") == (entry.Email == "") {
+			return nil, fmt.Errorf("access %d: set either token_from or email", i+1)
+		}
+		if entry.Email != "" {
+			if config.OIDCAudience == "" {
+				return nil, fmt.Errorf("access %d: email grants need oidc_audience in the config file", i+1)
+			}
+			grants = append(grants, accessGrant{role: r, email: entry.Email})
+			continue
+		}
+
+		token, err := resolveToken("", entry.TokenFrom)
+		if err != nil {
+			return nil, fmt.Errorf("access %d: %v", i+1, err)
+		}
+		if token == "" {
+			return nil, fmt.Errorf("access %d: token is empty", i+1)
+		}
+		if previous, ok := owners[token]; ok {
+			if previous == owner {
+				return nil, fmt.Errorf("access %d: the token is granted twice", i+1)
+			}
+			return nil, fmt.Errorf("%s and %s use the same token", previous, owner)
+		}
+		owners[token] = owner
+		registerSecret(token)
+		grants = append(grants, accessGrant{role: r, token: token})
+	}
+	return grants, nil
+}
+
+// findAccess returns the tenant and role of a bearer token, comparing it against every token in
+// constant time. Tokens that match no grant are tried as Google OIDC ID tokens when an audience is set.
+func findAccess(ctx context.Context, tenants []*serverTenant, token string) (*serverTenant, role) {
+	var found *serverTenant
+	var granted role
+	for _, tenant := range tenants {
+		for _, grant := range tenant.grants {
+			if grant.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(grant.token)) == 1 {
+				found, granted = tenant, grant.role
+			}
+		}
+	}
+	if found != nil || config.OIDCAudience == "" || strings.Count(token, ".") != 2 {
+		return found, granted
+	}
+
+	email, err := verifiedEmail(ctx, token)
+	if err != nil {
+		return nil, 0
+	}
+	// An identity granted by several tenants belongs to the first one in the config file
+	for _, tenant := range tenants {
+		for _, grant := range tenant.grants {
+			if grant.matchesEmail(email) && grant.role > granted {
+				found, granted = tenant, grant.role
+			}
+		}
+		if found != nil {
+			break
+		}
+	}
+	return found, granted
+}
+
+// verifiedEmail validates a Google-signed OIDC ID token for the configured audience and returns its
+// verified email address
+func verifiedEmail(ctx context.Context, token string) (string, error) {
+	payload, err := idtoken.Validate(ctx, token, config.OIDCAudience)
+	if err != nil {
+		return "", fmt.Errorf("invalid ID token: %v", err)
+	}
+	email, _ := payload.Claims["email"].(string)
+	if verified, _ := payload.Claims["email_verified"].(bool); !verified || email == "" {
+		return "", fmt.Errorf("ID token has no verified email")
+	}
+	return email, nil
+}
+
+// allowed writes a 403 response and returns false when the role is below the required one
+func allowed(w http.ResponseWriter, granted, required role, action string) bool {
+	if granted >= required {
+		return true
+	}
+	writeJSONError(w, http.StatusForbidden, fmt.Sprintf("role %s may not %s (needs %s)", granted, action, required))
+	return false
+}
+
+// CredentialsRequest is the body of PUT /api/v1/credentials: the Google API token for the tenant's
+// later scans, given directly or as a Secret Manager secret
+type CredentialsRequest struct {
+	Token     string `json:"token,omitempty"`
+	TokenFrom string `json:"token_from,omitempty"`
+}
+
+// CredentialsStatus describes the tenant's Google API credentials without revealing them
+type CredentialsStatus struct {
+	Tenant    string     `json:"tenant,omitempty"`
+	Source    string     `json:"source"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// handleCredentials serves GET and PUT /api/v1/credentials for admins
+func (s *ScanServer) handleCredentials(w http.ResponseWriter, r *http.Request, tenant *serverTenant, granted role) {
+	if !allowed(w, granted, roleAdmin, "manage credentials") {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, tenant.credentialsStatus())
+	case http.MethodPut:
+		var request CredentialsRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid credentials request: %v", err))
+			return
+		}
+		if (request.Token == "") == (request.TokenFrom == "") {
+			writeJSONError(w, http.StatusBadRequest, "set either token or token_from")
+			return
+		}
+		// Environment variables and files on the server are not for remote clients to read
+		if request.TokenFrom != "" && !strings.HasPrefix(request.TokenFrom, "secretmanager:") {
+			writeJSONError(w, http.StatusBadRequest, "token_from must be a secretmanager: source")
+			return
+		}
+		token, err := resolveToken(request.Token, request.TokenFrom)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("could not read the token: %v", err))
+			return
+		}
+		if token == "" {
+			writeJSONError(w, http.StatusBadRequest, "the token is empty")
+			return
+		}
+		source := "token set over the API"
+		if request.TokenFrom != "" {
+			source = request.TokenFrom
+		}
+		registerSecret(token)
+		tenant.setAPIToken(token, source)
+		writeJSON(w, http.StatusOK, tenant.credentialsStatus())
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use GET to read or PUT to replace the credentials")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// billingServiceAPIs maps billing export service descriptions to the API they bill
+var billingServiceAPIs = map[string]string{
+	"Compute Engine":                 "compute.googleapis.com",
+	"Cloud Storage":                  "storage.googleapis.com",
+	"BigQuery":                       "bigquery.googleapis.com",
+	"Cloud Pub/Sub":                  "pubsub.googleapis.com",
+	"Cloud Functions":                "cloudfunctions.googleapis.com",
+	"Cloud Run":                      "run.googleapis.com",
+	"Cloud Firestore":                "firestore.googleapis.com",
+	"Cloud Datastore":                "datastore.googleapis.com",
+	"Cloud SQL":                      "cloudsql.googleapis.com",
+	"Kubernetes Engine":              "container.googleapis.com",
+	"Cloud Build":                    "cloudbuild.googleapis.com",
+	"Cloud Tasks":                    "cloudtasks.googleapis.com",
+	"Cloud Scheduler":                "cloudscheduler.googleapis.com",
+	"Cloud Key Management Service":   "cloudkms.googleapis.com",
+	"Cloud Translation API":          "translate.googleapis.com",
+	"Translate":                      "translate.googleapis.com",
+	"Cloud Vision API":               "vision.googleapis.com",
+	"Cloud Speech API":               "speech.googleapis.com",
+	"Cloud Natural Language API":     "language.googleapis.com",
+	"Cloud Machine Learning Engine":  "ml.googleapis.com",
+	"Vertex AI":                      "aiplatform.googleapis.com",
+	"Cloud AutoML":                   "automl.googleapis.com",
+	"Cloud Dataflow":                 "dataflow.googleapis.com",
+	"Cloud Dataproc":                 "dataproc.googleapis.com",
+	"App Engine":                     "appengine.googleapis.com",
+	"Maps API":                       "maps.googleapis.com",
+	"Maps JavaScript API":            "maps.googleapis.com",
+	"Places API":                     "places.googleapis.com",
+	"Geocoding API":                  "geocoding.googleapis.com",
+	"Directions API":                 "directions.googleapis.com",
+	"Distance Matrix API":            "distancematrix.googleapis.com",
+	"Identity Platform":              "identitytoolkit.googleapis.com",
+	"Firebase Realtime Database":     "firebasedatabase.googleapis.com",
+	"Firebase Hosting":               "firebasehosting.googleapis.com",
+	"Cloud Logging":                  "logging.googleapis.com",
+	"Cloud Monitoring":               "monitoring.googleapis.com",
+	"Secret Manager":                 "secretmanager.googleapis.com",
+	"Cloud Spanner":                  "spanner.googleapis.com",
+	"Cloud Bigtable":                 "bigtable.googleapis.com",
+	"Document AI":                    "documentai.googleapis.com",
+	"Cloud Video Intelligence API":   "videointelligence.googleapis.com",
+	"Dialogflow":                     "dialogflow.googleapis.com",
+	"Cloud Data Loss Prevention API": "dlp.googleapis.com",
+}
+
+// lastMonthCostQuery sums last month's net cost (after credits) per service for one project
+const lastMonthCostQuery = "SELECT service.description AS service, " +
+	"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS cost " +
+	"FROM `%s` " +
+	"WHERE invoice.month = FORMAT_DATE('%%Y%%m', DATE_SUB(CURRENT_DATE(), INTERVAL 1 MONTH)) " +
+	"AND project.id = @project " +
+	"GROUP BY service"
+
+// annotateActualCosts sets CostInfo.ActualCost on enabled results from last month's billing export
+func (c *GoogleAPIChecker) annotateActualCosts(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("reconciliation requires a project ID")
+	}
+
+	table, err := c.findBillingExport()
+	if err != nil {
+		return err
+	}
+	if table == "" {
+		return fmt.Errorf("no billing export to BigQuery found (set billing_export in the config file)")
+	}
+
+	costs, unmatched, err := c.getActualCosts(table)
+	if err != nil {
+		return err
+	}
+
+	var labelCosts map[string]map[string]map[string]float64
+	if len(c.options.AllocationLabels) > 0 {
+		if labelCosts, err = c.getLabelCosts(table, c.options.AllocationLabels); err != nil {
+			fmt.Printf("⚠️  Cost allocation by label failed: %s\n", redactSecrets(err.Error()))
+		}
+	}
+
+	for i := range results {
+		if !results[i].Enabled {
+			continue
+		}
+		// An enabled API with no billing rows cost nothing last month
+		cost := costs[results[i].Name]
+		results[i].CostInfo.ActualCost = &cost
+		c.attachRaw(&results[i])
+		if labelCosts != nil {
+			results[i].CostInfo.ActualCostByLabel = allocateByLabel(cost, labelCosts[results[i].Name], c.options.AllocationLabels)
+		}
+	}
+
+	if unmatched > 0 {
+		fmt.Printf("ℹ️  $%.2f of last month's billed cost is for services not mapped to a checked API\n", unmatched)
+	}
+	return nil
+}
+
+// getActualCosts queries the billing export for last month's cost per API.
+// It also returns the cost of services that could not be mapped to an API.
+func (c *GoogleAPIChecker) getActualCosts(table string) (map[string]float64, float64, error) {
+	rows, raws, err := c.queryBillingExport(fmt.Sprintf(lastMonthCostQuery, table), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	costs := make(map[string]float64)
+	var unmatched float64
+	for i, row := range rows {
+		if len(row) < 2 || row[0] == nil || row[1] == nil {
+			continue
+		}
+		cost, err := strconv.ParseFloat(*row[1], 64)
+		if err != nil {
+			continue
+		}
+
+		if apiName, ok := billingServiceAPIs[*row[0]]; ok {
+			costs[apiName] += cost
+			c.recordRaw(apiName, rawBilling, raws[i])
+		} else {
+			unmatched += cost
+		}
+	}
+
+	return costs, unmatched, nil
+}
+
+// queryBillingExport runs a standard SQL query with the @project parameter set to the scanned
+// project plus any extra named parameters, and returns the row values with each row's JSON as received
+func (c *GoogleAPIChecker) queryBillingExport(query string, params []map[string]interface{}) ([][]*string, []json.RawMessage, error) {
+	queryParameters := []map[string]interface{}{{
+		"name":           "project",
+		"parameterType":  map[string]string{"type": "STRING"},
+		"parameterValue": map[string]string{"value": c.projectID},
+	}}
+	request := map[string]interface{}{
+		"query":           query,
+		"useLegacySql":    false,
+		"parameterMode":   "NAMED",
+		"timeoutMs":       60000,
+		"queryParameters": append(queryParameters, params...),
+	}
+
+	var response struct {
+		JobComplete bool              `json:"jobComplete"`
+		Rows        []json.RawMessage `json:"rows"`
+	}
+
+	requestURL := fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/queries", c.projectID)
+	if err := c.postJSON(requestURL, "billing export query", request, &response); err != nil {
+		return nil, nil, err
+	}
+	if !response.JobComplete {
+		return nil, nil, fmt.Errorf("billing export query did not finish within 60s")
+	}
+
+	rows := make([][]*string, len(response.Rows))
+	for i, raw := range response.Rows {
+		var row struct {
+			F []struct {
+				V *string `json:"v"`
+			} `json:"f"`
+		}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse billing export row: %v", err)
+		}
+		rows[i] = make([]*string, len(row.F))
+		for j, field := range row.F {
+			rows[i][j] = field.V
+		}
+	}
+	return rows, response.Rows, nil
+}
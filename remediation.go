@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// remediationProject is the placeholder used for results scanned without a project ID
+const remediationProject = "${PROJECT_ID:?set PROJECT_ID}"
+
+// GenerateRemediationScript turns the report's recommendations into a gcloud shell script.
+// The script is meant to be reviewed and run by a human; commands that need input are commented out.
+func GenerateRemediationScript(report *Report) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&b, "# Remediation script generated by Google API Checker %s at %s\n", report.ToolVersion, report.GeneratedAt.Format(time.RFC3339))
+	b.WriteString("# REVIEW EVERY COMMAND BEFORE RUNNING. Commands that need your input are commented out.\n")
+	b.WriteString("# Budget commands need BILLING_ACCOUNT (e.g. 012345-6789AB-CDEF01); PROJECT_ID is used where the scan had no project ID.\n")
+	b.WriteString("set -euo pipefail\n")
+
+	steps := 0
+
+	// APIs to disable, with the reason for each
+	type disableStep struct {
+		project, api, reason string
+	}
+	var disables []disableStep
+	seen := make(map[string]bool)
+	addDisable := func(project, api, reason string) {
+		key := project + "/" + api
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		disables = append(disables, disableStep{remediationProjectID(project), api, reason})
+	}
+	for _, violation := range report.PolicyViolations {
+		if violation.Rule == "disallowed_api" {
+			addDisable(violation.ProjectID, violation.API, violation.Message)
+		}
+	}
+	for _, api := range report.EmptyAPIs {
+		addDisable(api.ProjectID, api.Name, "0 resources in Cloud Asset Inventory")
+	}
+	for _, api := range report.UnusedAPIs {
+		addDisable(api.ProjectID, api.Name, "no requests in the last 90 days")
+	}
+
+	if len(disables) > 0 {
+		b.WriteString("\n# --- Disable APIs that are disallowed, empty or unused ---\n")
+		for _, step := range disables {
+			fmt.Fprintf(&b, "# %s\n", commentText(step.reason))
+			fmt.Fprintf(&b, "gcloud services disable %s --project=%s\n", shellQuote(step.api), shellQuote(step.project))
+			steps++
+		}
+	}
+
+	// Key restrictions for Maps Platform APIs callable from any application
+	keyTargets := make(map[string][]string)
+	var keyNames []string
+	budgetProjects := make(map[string]bool)
+	for _, api := range report.RiskScoredAPIs {
+		for _, key := range api.UnrestrictedKeys {
+			if _, ok := keyTargets[key]; !ok {
+				keyNames = append(keyNames, key)
+			}
+			keyTargets[key] = append(keyTargets[key], api.Name)
+		}
+		for _, factor := range api.RiskFactors {
+			if factor == noBudgetFactor {
+				budgetProjects[remediationProjectID(api.ProjectID)] = true
+			}
+		}
+	}
+	sort.Strings(keyNames)
+
+	if len(keyNames) > 0 {
+		b.WriteString("\n# --- Restrict API keys that can call Maps Platform APIs from any application ---\n")
+		b.WriteString("# Limit each key to the APIs it needs and to your websites or apps; fill in the referrers before running.\n")
+		for _, key := range keyNames {
+			var targets []string
+			for _, service := range keyTargets[key] {
+				targets = append(targets, "--api-target="+shellQuote("service="+service))
+			}
+			// Names read from a results file may hold newlines, which would end the comment
+			command := fmt.Sprintf("gcloud services api-keys update %s %s --allowed-referrers=%s",
+				shellQuote(key), strings.Join(targets, " "), shellQuote("https://www.example.com/*"))
+			fmt.Fprintf(&b, "# %s\n", commentText(command))
+			steps++
+		}
+	}
+
+	if len(budgetProjects) > 0 {
+		projects := make([]string, 0, len(budgetProjects))
+		for project := range budgetProjects {
+			projects = append(projects, project)
+		}
+		sort.Strings(projects)
+
+		b.WriteString("\n# --- Create budgets with alert thresholds for projects without one ---\n")
+		for _, project := range projects {
+			fmt.Fprintf(&b, "gcloud billing budgets create --billing-account=\"${BILLING_ACCOUNT:?set BILLING_ACCOUNT}\" \\\n")
+			fmt.Fprintf(&b, "  --display-name=%s --budget-amount=%.0fUSD \\\n", shellQuote("googleapichecker-")+shellQuote(project), report.CostAnalysis.TotalCostThreshold)
+			fmt.Fprintf(&b, "  --filter-projects=%s%s \\\n", shellQuote("projects/"), shellQuote(project))
+			b.WriteString("  --threshold-rule=percent=0.5 --threshold-rule=percent=0.9 --threshold-rule=percent=1.0\n")
+			steps++
+		}
+	}
+
+	if steps == 0 {
+		b.WriteString("\n# Nothing to remediate.\n")
+	}
+	return b.String()
+}
+
+// WriteRemediationScript writes the remediation script for the report to filename
+func WriteRemediationScript(report *Report, filename string) error {
+	if err := os.WriteFile(filename, []byte(GenerateRemediationScript(report)), 0644); err != nil {
+		return fmt.Errorf("failed to write remediation script: %v", err)
+	}
+	return nil
+}
+
+// remediationProjectID substitutes the placeholder for results without a project
+func remediationProjectID(projectID string) string {
+	if projectID == "" {
+		return remediationProject
+	}
+	return projectID
+}
+
+// shellQuote single-quotes a value for bash. Only the generator's own PROJECT_ID placeholder is
+// left expandable; any other value, e.g. a name read from a results file, is taken literally.
+func shellQuote(value string) string {
+	if value == remediationProject {
+		return "\"" + value + "\""
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// commentText keeps text on a single comment line
+func commentText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
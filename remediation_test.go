@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemediationScriptTakesNamesLiterally(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	injected := "touch " + marker
+
+	// Every name below comes from a results file and tries to run a command of its own
+	report := &Report{
+		PolicyViolations: []PolicyViolation{
+			{ProjectID: "p1$(" + injected + ")", Rule: "disallowed_api", API: "x.googleapis.com'; " + injected + "; '", Message: "disallowed\n" + injected},
+		},
+		RiskScoredAPIs: []APIResult{
+			{ProjectID: "p1`" + injected + "`", Name: "maps.googleapis.com\n" + injected,
+				UnrestrictedKeys: []string{"projects/p1/keys/k1\n" + injected + "\n#"}, RiskFactors: []string{noBudgetFactor}},
+		},
+	}
+	script := GenerateRemediationScript(report)
+
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, injected) {
+			t.Errorf("injected command on its own line: %q", line)
+		}
+	}
+
+	// Run the script with a gcloud that only records its arguments
+	gcloud := "#!/bin/sh\nprintf '%s\\n' \"$@\" >> " + filepath.Join(dir, "args") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "gcloud"), []byte(gcloud), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bash, "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"), "BILLING_ACCOUNT=0-0-0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, output)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("the script ran a command taken from a name")
+	}
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "x.googleapis.com'; "+injected+"; '") {
+		t.Errorf("gcloud did not get the API name literally:\n%s", args)
+	}
+}
@@ -3,20 +3,46 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Report represents the analysis report
 type Report struct {
-	Summary         SummaryInfo  `json:"summary"`
-	EnabledAPIs     []APIResult  `json:"enabled_apis"`
-	DisabledAPIs    []APIResult  `json:"disabled_apis"`
-	CostAnalysis    CostAnalysis `json:"cost_analysis"`
-	Recommendations []string     `json:"recommendations"`
-	GeneratedAt     time.Time    `json:"generated_at"`
+	SchemaVersion    int                 `json:"schema_version"`
+	Summary          SummaryInfo         `json:"summary"`
+	EnabledAPIs      []APIResult         `json:"enabled_apis"`
+	DisabledAPIs     []APIResult         `json:"disabled_apis"`
+	UnknownAPIs      []APIResult         `json:"unknown_apis,omitempty"`
+	SkippedAPIs      []APIResult         `json:"skipped_apis,omitempty"`
+	CostAnalysis     CostAnalysis        `json:"cost_analysis"`
+	UnusedAPIs       []APIResult         `json:"unused_apis"`
+	EmptyAPIs        []APIResult         `json:"empty_apis"`
+	HighRiskAPIs     []APIResult         `json:"high_risk_apis"`
+	IncidentAPIs     []APIResult         `json:"incident_apis"`
+	RiskScoredAPIs   []APIResult         `json:"risk_scored_apis"`
+	Scores           []ProjectScore      `json:"scores"`
+	CostAnomalies    []CostAnomaly       `json:"cost_anomalies"`
+	Findings         []Finding           `json:"findings"`
+	Compliance       []ComplianceFinding `json:"compliance"`
+	Contacts         []ProjectContacts   `json:"contacts"`
+	PolicyViolations []PolicyViolation   `json:"policy_violations"`
+	Drift            []Drift             `json:"drift,omitempty"`
+	Performance      *PerformanceStats   `json:"performance,omitempty"`
+	Overhead         *ScanOverhead       `json:"overhead,omitempty"`
+	Trend            []TrendPoint        `json:"trend,omitempty"`
+	Changes          *ScanDiff           `json:"changes,omitempty"`
+	Savings          SavingsEstimate     `json:"savings"`
+	Tags             map[string]string   `json:"tags,omitempty"`
+	Recommendations  []string            `json:"recommendations"`
+	GeneratedAt      time.Time           `json:"generated_at"`
+	ToolVersion      string              `json:"tool_version"`
 }
 
 // SummaryInfo contains summary statistics
@@ -25,8 +51,16 @@ type SummaryInfo struct {
 	EnabledCount  int     `json:"enabled_count"`
 	DisabledCount int     `json:"disabled_count"`
 	ErrorCount    int     `json:"error_count"`
+	UnknownCount  int     `json:"unknown_count"`
+	SkippedCount  int     `json:"skipped_count,omitempty"`
 	TotalCost     float64 `json:"total_cost"`
 	Currency      string  `json:"currency"`
+	CostSkipped   bool    `json:"cost_skipped,omitempty"`
+	Keyless       bool    `json:"keyless,omitempty"`
+	// PotentialSavings is the monthly saving of acting on the disable and quota recommendations
+	PotentialSavings float64 `json:"potential_savings"`
+	// ActualCost is last month's billed total for reconciled APIs, nil without --reconcile
+	ActualCost *float64 `json:"actual_cost,omitempty"`
 }
 
 // CostAnalysis contains detailed cost information
@@ -34,20 +68,35 @@ type CostAnalysis struct {
 	TotalEstimatedCost float64            `json:"total_estimated_cost"`
 	UnlimitedCostAPIs  []APIResult        `json:"unlimited_cost_apis"`
 	HighCostAPIs       []APIResult        `json:"high_cost_apis"`
+	FreeTierAPIs       []APIResult        `json:"free_tier_apis"`
+	ReconciledAPIs     []APIResult        `json:"reconciled_apis"`
+	CostAllocation     []CostAllocation   `json:"cost_allocation"`
+	HighCostThreshold  float64            `json:"high_cost_threshold"`
+	TotalCostThreshold float64            `json:"total_cost_threshold"`
 	CostBreakdown      map[string]float64 `json:"cost_breakdown"`
 }
 
-// GenerateReport creates a comprehensive analysis report
+// GenerateReport creates a comprehensive analysis report using the default thresholds
 func GenerateReport(results []APIResult) *Report {
+	return GenerateReportWithPolicy(results, DefaultPolicy())
+}
+
+// GenerateReportWithPolicy creates the report using per-environment thresholds and rules
+func GenerateReportWithPolicy(results []APIResult, policy *Policy) *Report {
+	policy.assignCostTiers(results)
+
 	report := &Report{
-		GeneratedAt: time.Now(),
+		SchemaVersion: OutputSchemaVersion,
+		GeneratedAt:   time.Now(),
+		ToolVersion:   toolVersion(),
 	}
 
 	// Separate APIs by status
-	var enabledAPIs, disabledAPIs []APIResult
+	var enabledAPIs, disabledAPIs, unknownAPIs, skippedAPIs []APIResult
 	var errorCount int
 	var totalCost float64
-	var unlimitedCostAPIs, highCostAPIs []APIResult
+	var unlimitedCostAPIs, highCostAPIs, freeTierAPIs, reconciledAPIs, unusedAPIs, emptyAPIs, highRiskAPIs, riskScoredAPIs, incidentAPIs []APIResult
+	var actualCost *float64
 	costBreakdown := make(map[string]float64)
 
 	for _, result := range results {
@@ -55,25 +104,70 @@ func GenerateReport(results []APIResult) *Report {
 			errorCount++
 			continue
 		}
+		if result.Status == statusSkipped {
+			skippedAPIs = append(skippedAPIs, result)
+			continue
+		}
 
 		if result.Enabled {
 			enabledAPIs = append(enabledAPIs, result)
 
+			// Enabled but no traffic in the usage window
+			if result.RequestCount90d != nil && *result.RequestCount90d == 0 {
+				unusedAPIs = append(unusedAPIs, result)
+			}
+
+			// Enabled without a single resource behind it
+			if result.ResourceCount != nil && *result.ResourceCount == 0 {
+				emptyAPIs = append(emptyAPIs, result)
+			}
+
+			// Billed cost from the billing export
+			if result.CostInfo.ActualCost != nil {
+				reconciledAPIs = append(reconciledAPIs, result)
+				if actualCost == nil {
+					actualCost = new(float64)
+				}
+				*actualCost += *result.CostInfo.ActualCost
+			}
+
+			// Flagged by the scan profile as a common abuse target
+			if result.RiskNote != "" {
+				highRiskAPIs = append(highRiskAPIs, result)
+			}
+
+			if result.RiskScore != nil {
+				riskScoredAPIs = append(riskScoredAPIs, result)
+			}
+
+			// Affected by an ongoing Google Cloud incident
+			if len(result.Incidents) > 0 {
+				incidentAPIs = append(incidentAPIs, result)
+			}
+
 			// Calculate costs
 			if result.CostInfo.HasPricing {
 				totalCost += result.CostInfo.EstimatedCost
-				costBreakdown[result.DisplayName] = result.CostInfo.EstimatedCost
+				// The same API in several projects adds up, so the breakdown sums to the total
+				costBreakdown[result.DisplayName] += result.CostInfo.EstimatedCost
+
+				// Expected usage fits in the free tier
+				if result.CostInfo.FreeTierCovered {
+					freeTierAPIs = append(freeTierAPIs, result)
+				}
 
 				// Check for unlimited cost APIs
 				if result.CostInfo.UnlimitedCost {
 					unlimitedCostAPIs = append(unlimitedCostAPIs, result)
 				}
 
-				// Check for high cost APIs (>$50)
-				if result.CostInfo.EstimatedCost > 50.0 {
+				// Check for high cost APIs against the environment's threshold
+				if result.CostInfo.EstimatedCost > policy.ThresholdsFor(result.Environment).HighCost {
 					highCostAPIs = append(highCostAPIs, result)
 				}
 			}
+		} else if statusUndetermined(result.Status) {
+			unknownAPIs = append(unknownAPIs, result)
 		} else {
 			disabledAPIs = append(disabledAPIs, result)
 		}
@@ -89,35 +183,151 @@ func GenerateReport(results []APIResult) *Report {
 		return unlimitedCostAPIs[i].DisplayName < unlimitedCostAPIs[j].DisplayName
 	})
 
+	// Sort risk-scored APIs by score (highest first)
+	sort.SliceStable(riskScoredAPIs, func(i, j int) bool {
+		return *riskScoredAPIs[i].RiskScore > *riskScoredAPIs[j].RiskScore
+	})
+
+	// Sort reconciled APIs by the size of the estimation error (largest first)
+	sort.Slice(reconciledAPIs, func(i, j int) bool {
+		return math.Abs(reconciliationGap(reconciledAPIs[i])) > math.Abs(reconciliationGap(reconciledAPIs[j]))
+	})
+
+	// Sort unused APIs by cost (highest first) so the biggest savings come first
+	sort.Slice(unusedAPIs, func(i, j int) bool {
+		return unusedAPIs[i].CostInfo.EstimatedCost > unusedAPIs[j].CostInfo.EstimatedCost
+	})
+
 	// Create summary
 	report.Summary = SummaryInfo{
 		TotalAPIs:     len(results),
 		EnabledCount:  len(enabledAPIs),
 		DisabledCount: len(disabledAPIs),
 		ErrorCount:    errorCount,
+		UnknownCount:  len(unknownAPIs),
+		SkippedCount:  len(skippedAPIs),
 		TotalCost:     totalCost,
 		Currency:      "USD",
+		ActualCost:    actualCost,
 	}
 
 	report.EnabledAPIs = enabledAPIs
 	report.DisabledAPIs = disabledAPIs
+	report.UnknownAPIs = unknownAPIs
+	report.SkippedAPIs = skippedAPIs
+	report.UnusedAPIs = unusedAPIs
+	report.EmptyAPIs = emptyAPIs
+	report.HighRiskAPIs = highRiskAPIs
+	report.IncidentAPIs = incidentAPIs
+	report.RiskScoredAPIs = riskScoredAPIs
+	report.PolicyViolations = policy.Violations(results)
+	report.Compliance = complianceFindings(results)
 	report.CostAnalysis = CostAnalysis{
 		TotalEstimatedCost: totalCost,
 		UnlimitedCostAPIs:  unlimitedCostAPIs,
 		HighCostAPIs:       highCostAPIs,
+		FreeTierAPIs:       freeTierAPIs,
+		ReconciledAPIs:     reconciledAPIs,
+		CostAllocation:     buildCostAllocation(results),
+		HighCostThreshold:  policy.Defaults.HighCost,
+		TotalCostThreshold: policy.Defaults.TotalCost,
 		CostBreakdown:      costBreakdown,
 	}
 
+	report.Performance = computePerformance(results)
+	report.Savings = estimateSavings(report, policy)
+	report.Summary.PotentialSavings = report.Savings.Total
+
 	// Generate recommendations
-	report.Recommendations = generateRecommendations(report)
+	report.Scores = computeScores(report, results)
+	report.Recommendations = generateRecommendations(report, policy)
 
 	return report
 }
 
-// generateRecommendations creates actionable recommendations based on the analysis
-func generateRecommendations(report *Report) []string {
+// generateRecommendations creates actionable recommendations based on the analysis and the policy's rules
+func generateRecommendations(report *Report, policy *Policy) []string {
 	var recommendations []string
 
+	// A partial report needs a re-run before anything else in it is complete
+	if report.Summary.SkippedCount > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("⛔ The Service Usage quota ran out and %d APIs were SKIPPED. Re-run after the quota resets with --resume on the results file to check only those, and lower --threads or set --qps", report.Summary.SkippedCount))
+	}
+
+	// High abuse-risk scores come first
+	var highRisk []APIResult
+	for _, api := range report.RiskScoredAPIs {
+		if *api.RiskScore >= highRiskScore {
+			highRisk = append(highRisk, api)
+		}
+	}
+	if len(highRisk) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🚨 URGENT: %d Maps Platform APIs have a high abuse-risk score. Restrict API keys to your apps and add a budget alert:", len(highRisk)))
+
+		for _, api := range highRisk {
+			recommendations = append(recommendations,
+				fmt.Sprintf("   - %s (score %d): %s", api.DisplayName, *api.RiskScore, strings.Join(api.RiskFactors, ", ")))
+		}
+	}
+
+	// Throttled checks come back as errors; a slower scan avoids them
+	if report.Performance != nil && report.Performance.Throttled > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🐢 %d checks were throttled (HTTP 429). Lower --threads or set --qps and re-run them", report.Performance.Throttled))
+	}
+
+	// Ongoing incidents explain errors and latency that are not the project's fault
+	if len(report.IncidentAPIs) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🩺 %d enabled APIs are affected by active Google Cloud incidents; expect degraded service until they are resolved", len(report.IncidentAPIs)))
+	}
+
+	// Environment policy violations
+	if len(report.PolicyViolations) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🚫 POLICY: %d environment policy violations found:", len(report.PolicyViolations)))
+
+		for _, violation := range report.PolicyViolations {
+			recommendations = append(recommendations,
+				fmt.Sprintf("   - %s", violationText(violation)))
+		}
+	}
+
+	// Abuse targets need protection even when they are cheap
+	if len(report.HighRiskAPIs) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🎯 %d enabled APIs are common abuse targets. Restrict their API keys and review:", len(report.HighRiskAPIs)))
+
+		for _, api := range report.HighRiskAPIs {
+			recommendations = append(recommendations,
+				fmt.Sprintf("   - %s: %s", api.DisplayName, api.RiskNote))
+		}
+	}
+
+	// Enabled APIs with no resources are the strongest disable candidates
+	if len(report.EmptyAPIs) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🗑️  %d enabled APIs have 0 resources in Cloud Asset Inventory. They are strong candidates to disable:", len(report.EmptyAPIs)))
+
+		for _, api := range report.EmptyAPIs {
+			recommendations = append(recommendations,
+				fmt.Sprintf("   - %s (%s)%s", api.DisplayName, api.Name, savingsNote(report.Savings.savingsFor(api))))
+		}
+	}
+
+	// Enabled-but-unused APIs are the safest to disable
+	if len(report.UnusedAPIs) > 0 {
+		recommendations = append(recommendations,
+			fmt.Sprintf("🧹 %d enabled APIs had no requests in the last 90 days. Disable them to reduce cost and attack surface:", len(report.UnusedAPIs)))
+
+		for _, api := range report.UnusedAPIs {
+			recommendations = append(recommendations,
+				fmt.Sprintf("   - %s (%s)%s", api.DisplayName, api.Name, savingsNote(report.Savings.savingsFor(api))))
+		}
+	}
+
 	// Check for unlimited cost APIs
 	if len(report.CostAnalysis.UnlimitedCostAPIs) > 0 {
 		recommendations = append(recommendations,
@@ -125,42 +335,126 @@ func generateRecommendations(report *Report) []string {
 
 		for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
 			recommendations = append(recommendations,
-				fmt.Sprintf("   - %s: %s", api.DisplayName, api.CostInfo.PricingDetails))
+				fmt.Sprintf("   - %s: %s%s", api.DisplayName, api.CostInfo.PricingDetails, savingsNote(report.Savings.savingsFor(api))))
 		}
 	}
 
 	// Check for high cost APIs
 	if len(report.CostAnalysis.HighCostAPIs) > 0 {
 		recommendations = append(recommendations,
-			fmt.Sprintf("💰 High cost APIs detected (%d APIs with >$50 estimated cost):", len(report.CostAnalysis.HighCostAPIs)))
+			fmt.Sprintf("💰 High cost APIs detected (%d APIs above their environment's threshold, default >%s):", len(report.CostAnalysis.HighCostAPIs), formatCost(report.CostAnalysis.HighCostThreshold)))
 
 		for _, api := range report.CostAnalysis.HighCostAPIs {
 			recommendations = append(recommendations,
-				fmt.Sprintf("   - %s: $%.2f/month", api.DisplayName, api.CostInfo.EstimatedCost))
+				fmt.Sprintf("   - %s: %s%s", api.DisplayName, formatCost(api.CostInfo.EstimatedCost), savingsNote(report.Savings.savingsFor(api))))
 		}
 	}
 
-	// Check total cost
-	if report.Summary.TotalCost > 500 {
+	// The figure management asks for
+	if report.Savings.Total > 0 {
 		recommendations = append(recommendations,
-			fmt.Sprintf("💸 Total estimated monthly cost is high: $%.2f. Consider reviewing usage patterns.", report.Summary.TotalCost))
+			fmt.Sprintf("💵 Disabling unused APIs and capping expensive ones with quotas could save about %s", formatCost(report.Savings.Total)))
 	}
 
-	// Check for disabled APIs that might be needed
-	if len(report.DisabledAPIs) > 0 {
-		recommendations = append(recommendations,
-			fmt.Sprintf("🔒 %d APIs are currently disabled. Review if any are needed for your application.", len(report.DisabledAPIs)))
+	// Total cost, general advice and the config file's rules
+	recommendations = append(recommendations, evaluateRules(policy.Recommendations, report)...)
+
+	return recommendations
+}
+
+// printScores prints each project's score with its grade colored
+func printScores(scores []ProjectScore) {
+	if len(scores) == 0 {
+		return
 	}
 
-	// General recommendations
-	recommendations = append(recommendations,
-		"📊 Set up billing alerts and budget limits in Google Cloud Console")
-	recommendations = append(recommendations,
-		"🔍 Regularly monitor API usage and costs")
-	recommendations = append(recommendations,
-		"⚡ Consider using quotas and rate limiting for high-cost APIs")
+	const (
+		reset  = "\033[0m"
+		bold   = "\033[1m"
+		red    = "\033[31m"
+		green  = "\033[32m"
+		yellow = "\033[33m"
+	)
 
-	return recommendations
+	fmt.Fprintf(console, "\n"+bold+"🏆 SCORE:"+reset+"\n")
+	for _, score := range scores {
+		color := yellow
+		switch score.Grade {
+		case "A", "B":
+			color = green
+		case "F":
+			color = red
+		}
+		fmt.Fprintf(console, "   %s: %s%s%s%s\n", score.Label(), bold, color, score.String(), reset)
+	}
+}
+
+// truncate shortens s to at most width runes for fixed-width console columns
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// defaultConsoleTop is how many APIs each console report list shows unless --top or --all is given
+const defaultConsoleTop = 10
+
+// consoleLimit is the most entries a console report list shows, 0 for all
+func consoleLimit() int {
+	if showAll {
+		return 0
+	}
+	return topN
+}
+
+// topAPIs returns the first entries of a console list within consoleLimit and how many are left out
+func topAPIs(apis []APIResult) ([]APIResult, int) {
+	if limit := consoleLimit(); limit > 0 && len(apis) > limit {
+		return apis[:limit], len(apis) - limit
+	}
+	return apis, 0
+}
+
+// mostExpensiveFirst returns a copy of the APIs ordered by estimated cost, highest first
+func mostExpensiveFirst(apis []APIResult) []APIResult {
+	sorted := append([]APIResult(nil), apis...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CostInfo.EstimatedCost > sorted[j].CostInfo.EstimatedCost
+	})
+	return sorted
+}
+
+// printHidden tells how many entries of a console list --top left out
+func printHidden(indent string, hidden int) {
+	if hidden > 0 {
+		fmt.Fprintf(console, "%s… and %d more (--all shows everything)\n", indent, hidden)
+	}
+}
+
+// incidentAPILabel names an API with its project when several projects were scanned
+func incidentAPILabel(api APIResult) string {
+	if api.ProjectID == "" {
+		return api.DisplayName
+	}
+	return fmt.Sprintf("%s (%s)", api.DisplayName, api.ProjectID)
+}
+
+// reconciliationGap is how far the estimate was from the billed cost; positive means underestimated
+func reconciliationGap(result APIResult) float64 {
+	if result.CostInfo.ActualCost == nil {
+		return 0
+	}
+	return *result.CostInfo.ActualCost - result.CostInfo.EstimatedCost
+}
+
+// violationText formats a policy violation with its project
+func violationText(violation PolicyViolation) string {
+	if violation.ProjectID == "" {
+		return violation.Message
+	}
+	return fmt.Sprintf("[%s] %s", violation.ProjectID, violation.Message)
 }
 
 // SaveReport saves the report to a JSON file
@@ -181,14 +475,20 @@ func SaveReport(report *Report, filename string) error {
 	return nil
 }
 
-// generateHTMLReport creates an HTML table report
-func generateHTMLReport(results []APIResult, filename string) error {
+// generateHTMLReport creates an HTML table report with the report's scores in the header
+func generateHTMLReport(report *Report, results []APIResult, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create HTML file: %v", err)
 	}
 	defer file.Close()
 
+	return writeHTMLReport(file, report, results, "")
+}
+
+// writeHTMLReport writes the HTML report. With an eventsURL the page follows a running scan's
+// server-sent events, adding results to the table as they arrive and reloading when it finishes.
+func writeHTMLReport(w io.Writer, report *Report, results []APIResult, eventsURL string) error {
 	// Calculate statistics
 	var enabledCount, disabledCount, errorCount int
 	var totalCost float64
@@ -213,194 +513,699 @@ func generateHTMLReport(results []APIResult, filename string) error {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>Google API Checker Report</title>
     <script src="https://cdn.tailwindcss.com"></script>
+    <script>
+    tailwind.config = { darkMode: 'class' };
+    (function () {
+        const saved = localStorage.getItem('theme');
+        if (saved === 'dark' || (!saved && window.matchMedia('(prefers-color-scheme: dark)').matches)) {
+            document.documentElement.classList.add('dark');
+        }
+    })();
+    </script>
     <script defer src="https://unpkg.com/alpinejs@3.x.x/dist/cdn.min.js"></script>
+    <style>
+        @media (prefers-reduced-motion: reduce) {
+            *, *::before, *::after { transition: none !important; animation: none !important; }
+        }
+        @media print {
+            @page { size: A4 landscape; margin: 12mm; }
+            html, body { background: #fff !important; color: #000 !important; }
+            .no-print { display: none !important; }
+            .container { max-width: none !important; padding: 0 !important; }
+            .shadow-md { box-shadow: none !important; }
+            .bg-gradient-to-r { background: none !important; color: #000 !important; border-bottom: 2px solid #000; border-radius: 0 !important; }
+            .overflow-x-auto, .overflow-hidden { overflow: visible !important; }
+            table { font-size: 9pt; border-collapse: collapse; }
+            thead { display: table-header-group; }
+            tr { page-break-inside: avoid; break-inside: avoid; }
+            th, td { padding: 4px 6px !important; white-space: normal !important; border-bottom: 1px solid #ccc; }
+            .dark * { background-color: transparent !important; color: #000 !important; }
+        }
+    </style>
 </head>
-<body class="bg-gray-100 min-h-screen">
+<body class="bg-gray-100 dark:bg-gray-900 min-h-screen transition-colors">
     <script id="apidata" type="application/json">%s</script>
+    <script id="scoredata" type="application/json">%s</script>
+    <script id="livedata" type="application/json">%s</script>
+    <script id="perioddata" type="application/json">%s</script>
+    <script id="changedata" type="application/json">%s</script>
+    <a href="#results" class="sr-only focus:not-sr-only focus:absolute focus:top-2 focus:left-2 focus:z-50 focus:px-4 focus:py-2 focus:bg-white focus:text-blue-800 focus:rounded-lg focus:ring-2 focus:ring-blue-600">Skip to results</a>
     <div class="container mx-auto px-4 py-8" x-data="apiChecker()" x-init="init()">
         <div class="max-w-7xl mx-auto">
             <!-- Header -->
-            <div class="bg-gradient-to-r from-blue-600 to-purple-600 text-white rounded-lg p-8 mb-8 text-center">
-                <h1 class="text-4xl font-bold mb-2">🔍 Google API Checker Report</h1>
-                <p class="text-lg opacity-90">Generated on %s</p>
-            </div>
-            <!-- Stats Cards -->
-            <div class="grid grid-cols-1 md:grid-cols-5 gap-6 mb-8">
-                <div class="bg-white rounded-lg p-6 shadow-md border-l-4 border-blue-500">
-                    <div class="text-3xl font-bold text-blue-600" x-text="stats.total"></div>
-                    <div class="text-gray-600 mt-2">Total APIs</div>
+            <header class="relative bg-gradient-to-r from-blue-700 to-purple-700 text-white rounded-lg p-8 mb-8 text-center">
+                <div class="no-print absolute top-4 right-4 flex space-x-2">
+                    <button
+                        type="button"
+                        @click="toggleTheme()"
+                        :aria-pressed="darkMode.toString()"
+                        aria-label="Dark theme"
+                        class="px-3 py-2 rounded-lg bg-white/20 hover:bg-white/30 text-sm font-medium transition-colors focus:outline-none focus:ring-2 focus:ring-white"
+                    ><span aria-hidden="true" x-text="darkMode ? '☀️ Light' : '🌙 Dark'"></span></button>
+                    <button
+                        type="button"
+                        @click="window.print()"
+                        class="px-3 py-2 rounded-lg bg-white/20 hover:bg-white/30 text-sm font-medium transition-colors focus:outline-none focus:ring-2 focus:ring-white"
+                    ><span aria-hidden="true">🖨️</span> Print</button>
                 </div>
-                <div class="bg-white rounded-lg p-6 shadow-md border-l-4 border-green-500">
-                    <div class="text-3xl font-bold text-green-600" x-text="stats.enabled"></div>
-                    <div class="text-gray-600 mt-2">Enabled</div>
+                <h1 class="text-4xl font-bold mb-2"><span aria-hidden="true">🔍</span> Google API Checker Report</h1>
+                <p class="text-lg">Generated on %s by Google API Checker %s</p>%s
+                <ul class="mt-4 flex flex-wrap gap-3" x-show="visibleScores.length > 0" aria-label="Project scores">
+                    <template x-for="s in visibleScores" :key="s.project_id || 'scan'">
+                        <li class="bg-white/20 rounded-lg px-4 py-2" :aria-label="(s.project_id ? s.project_id + ': ' : '') + 'grade ' + s.grade + ', score ' + s.score + ' out of 100'">
+                            <span aria-hidden="true">
+                                <span class="text-3xl font-bold" x-text="s.grade"></span>
+                                <span class="ml-2 font-semibold" x-text="s.score + '/100'"></span>
+                                <span class="ml-2 text-sm" x-text="s.project_id || ''"></span>
+                            </span>
+                            <div class="text-xs" x-text="'Security ' + s.security + ' · Cost hygiene ' + s.cost_hygiene + ' · Reliability ' + s.reliability"></div>
+                        </li>
+                    </template>
+                </ul>
+            </header>
+            <main>
+            <!-- Live Scan Progress -->
+            <section class="no-print mb-6 bg-white dark:bg-gray-800 rounded-lg p-4 shadow-md" x-show="live" aria-label="Scan progress">
+                <div class="flex items-center justify-between text-gray-700 dark:text-gray-300 mb-2">
+                    <span class="font-medium" role="status" x-text="liveState === 'running' ? '⏳ Scan in progress…' : (liveState === 'queued' ? '🕒 Scan queued…' : '🔄 Loading the final report…')"></span>
+                    <span class="text-sm" x-text="liveDone + ' / ' + liveTotal + ' APIs checked'"></span>
                 </div>
-                <div class="bg-white rounded-lg p-6 shadow-md border-l-4 border-red-500">
-                    <div class="text-3xl font-bold text-red-600" x-text="stats.disabled"></div>
-                    <div class="text-gray-600 mt-2">Disabled</div>
-                </div>
-                <div class="bg-white rounded-lg p-6 shadow-md border-l-4 border-yellow-500">
-                    <div class="text-3xl font-bold text-yellow-600" x-text="stats.errors"></div>
-                    <div class="text-gray-600 mt-2">Errors</div>
-                </div>
-                <div class="bg-white rounded-lg p-6 shadow-md border-l-4 border-purple-500">
-                    <div class="text-3xl font-bold text-purple-600" x-text="'$' + (typeof stats.totalCost === 'number' ? stats.totalCost.toFixed(2) : '0.00')"></div>
-                    <div class="text-gray-600 mt-2">Total Cost (USD)</div>
+                <div
+                    class="w-full bg-gray-200 dark:bg-gray-700 rounded-full h-2"
+                    role="progressbar"
+                    aria-label="APIs checked"
+                    aria-valuemin="0"
+                    :aria-valuemax="liveTotal"
+                    :aria-valuenow="liveDone"
+                >
+                    <div class="bg-blue-600 h-2 rounded-full transition-all" :style="'width: ' + (liveTotal ? Math.round(liveDone * 100 / liveTotal) : 0) + '%%'"></div>
                 </div>
+            </section>
+            <!-- Project Selector -->
+            <div class="no-print mb-6 flex items-center space-x-3" x-show="projects.length > 1">
+                <label for="project-select" class="text-gray-700 dark:text-gray-300 font-medium">Project:</label>
+                <select
+                    id="project-select"
+                    x-model="activeProject"
+                    class="px-4 py-2 border border-gray-500 dark:border-gray-400 dark:bg-gray-800 dark:text-gray-100 rounded-lg focus:ring-2 focus:ring-blue-600"
+                >
+                    <option value="all">All projects</option>
+                    <template x-for="project in projects" :key="project">
+                        <option :value="project" x-text="project"></option>
+                    </template>
+                </select>
             </div>
+            <!-- Per-Project Stats (aggregate view) -->
+            <section class="mb-8" x-show="projects.length > 1 && activeProject === 'all'" aria-labelledby="projects-heading">
+                <h2 id="projects-heading" class="text-xl font-semibold text-gray-800 dark:text-gray-100 mb-4">Projects</h2>
+                <ul class="grid grid-cols-1 md:grid-cols-3 gap-4">
+                    <template x-for="ps in projectStats" :key="ps.project">
+                        <li>
+                            <button
+                                type="button"
+                                @click="activeProject = ps.project"
+                                :aria-label="'Show project ' + ps.project"
+                                class="w-full text-left bg-white dark:bg-gray-800 rounded-lg p-5 shadow-md hover:ring-2 hover:ring-blue-600 focus:outline-none focus:ring-2 focus:ring-blue-600"
+                            >
+                                <span class="block text-lg font-semibold text-gray-900 dark:text-gray-100 mb-2" x-text="ps.project"></span>
+                                <span class="grid grid-cols-2 gap-1 text-sm text-gray-700 dark:text-gray-300">
+                                    <span>Total: <span class="font-semibold" x-text="ps.total"></span></span>
+                                    <span>Enabled: <span class="font-semibold" x-text="ps.enabled"></span></span>
+                                    <span>Disabled: <span class="font-semibold" x-text="ps.disabled"></span></span>
+                                    <span>Errors: <span class="font-semibold" x-text="ps.errors"></span></span>
+                                    <span x-show="ps.unknown > 0">Undetermined: <span class="font-semibold" x-text="ps.unknown"></span></span>
+                                    <span class="col-span-2">Cost: <span class="font-semibold" x-text="money(ps.totalCost)"></span></span>
+                                </span>
+                            </button>
+                        </li>
+                    </template>
+                </ul>
+            </section>
+            <!-- Stats Cards -->
+            <section aria-label="Summary">
+                <dl class="grid grid-cols-1 gap-6 mb-8" :class="stats.unknown > 0 ? 'md:grid-cols-6' : 'md:grid-cols-5'">
+                    <div class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-blue-600">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2">Total APIs</dt>
+                        <dd class="text-3xl font-bold text-blue-700 dark:text-blue-400" x-text="stats.total"></dd>
+                    </div>
+                    <div class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-green-600">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2"><span aria-hidden="true">✔</span> Enabled</dt>
+                        <dd class="text-3xl font-bold text-green-700 dark:text-green-400" x-text="stats.enabled"></dd>
+                    </div>
+                    <div class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-red-600">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2"><span aria-hidden="true">✖</span> Disabled</dt>
+                        <dd class="text-3xl font-bold text-red-700 dark:text-red-400" x-text="stats.disabled"></dd>
+                    </div>
+                    <div class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-yellow-600">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2"><span aria-hidden="true">⚠</span> Errors</dt>
+                        <dd class="text-3xl font-bold text-yellow-800 dark:text-yellow-400" x-text="stats.errors"></dd>
+                    </div>
+                    <div x-show="stats.unknown > 0" class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-gray-500">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2"><span aria-hidden="true">?</span> Undetermined</dt>
+                        <dd class="text-3xl font-bold text-gray-700 dark:text-gray-300" x-text="stats.unknown"></dd>
+                    </div>
+                    <div class="flex flex-col-reverse bg-white dark:bg-gray-800 rounded-lg p-6 shadow-md border-l-4 border-purple-600">
+                        <dt class="text-gray-700 dark:text-gray-300 mt-2" x-text="'Total Cost (USD' + period.suffix + ')'">Total Cost (USD)</dt>
+                        <dd class="text-3xl font-bold text-purple-700 dark:text-purple-400" x-text="money(typeof stats.totalCost === 'number' ? stats.totalCost : 0)"></dd>
+                    </div>
+                </dl>
+            </section>%s
             <!-- Search Box -->
-            <div class="mb-6">
+            <div class="no-print mb-6" role="search">
+                <label for="api-search" class="sr-only">Search APIs by name or display name</label>
                 <input 
-                    type="text" 
+                    id="api-search"
+                    type="search" 
                     x-model="searchTerm"
                     placeholder="Search APIs..." 
-                    class="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+                    aria-controls="results-table"
+                    class="w-full px-4 py-3 border border-gray-500 dark:border-gray-400 dark:bg-gray-800 dark:text-gray-100 rounded-lg focus:ring-2 focus:ring-blue-600 focus:border-transparent"
                 >
             </div>
-            <!-- Tabs -->
-            <div class="flex space-x-2 mb-6">
-                <button 
-                    @click="activeTab = 'all'"
-                    :class="activeTab === 'all' ? 'bg-blue-600 text-white' : 'bg-gray-200 text-gray-700'"
-                    class="px-6 py-3 rounded-lg font-medium transition-colors"
-                >
-                    All APIs
-                </button>
-                <button 
-                    @click="activeTab = 'enabled'"
-                    :class="activeTab === 'enabled' ? 'bg-green-600 text-white' : 'bg-gray-200 text-gray-700'"
-                    class="px-6 py-3 rounded-lg font-medium transition-colors"
-                >
-                    Enabled
-                </button>
-                <button 
-                    @click="activeTab = 'disabled'"
-                    :class="activeTab === 'disabled' ? 'bg-red-600 text-white' : 'bg-gray-200 text-gray-700'"
-                    class="px-6 py-3 rounded-lg font-medium transition-colors"
-                >
-                    Disabled
-                </button>
-                <button 
-                    @click="activeTab = 'errors'"
-                    :class="activeTab === 'errors' ? 'bg-yellow-600 text-white' : 'bg-gray-200 text-gray-700'"
-                    class="px-6 py-3 rounded-lg font-medium transition-colors"
-                >
-                    Errors
-                </button>
+            <!-- Tabs (arrow keys move between them) -->
+            <div class="no-print flex space-x-2 mb-6" role="tablist" aria-label="Filter APIs by status" @keydown.arrow-right.prevent="moveTab(1)" @keydown.arrow-left.prevent="moveTab(-1)" @keydown.home.prevent="moveTab(-tabs.length)" @keydown.end.prevent="moveTab(tabs.length)">
+                <template x-for="tab in tabs" :key="tab.id">
+                    <button 
+                        type="button"
+                        role="tab"
+                        :id="'tab-' + tab.id"
+                        :aria-selected="(activeTab === tab.id).toString()"
+                        aria-controls="results"
+                        :tabindex="activeTab === tab.id ? 0 : -1"
+                        @click="activeTab = tab.id"
+                        :class="activeTab === tab.id ? tab.activeClass + ' text-white' : 'bg-gray-200 text-gray-800 dark:bg-gray-700 dark:text-gray-100'"
+                        class="px-6 py-3 rounded-lg font-medium transition-colors focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-blue-600"
+                    ><span aria-hidden="true" x-text="tab.icon"></span> <span x-text="tab.label"></span></button>
+                </template>
+                <label for="tier-filter" class="sr-only">Filter APIs by cost tier</label>
+                <select id="tier-filter" x-model="activeTier" aria-controls="results" class="px-4 py-3 border border-gray-500 dark:border-gray-400 dark:bg-gray-800 dark:text-gray-100 rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-600">
+                    <option value="all">All cost tiers</option>
+                    <template x-for="tier in costTiers" :key="tier">
+                        <option :value="tier" x-text="tier.charAt(0) + tier.slice(1).toLowerCase()"></option>
+                    </template>
+                </select>
             </div>
             <!-- Results Count -->
-            <div class="mb-4 text-gray-600">
-                Showing <span class="font-semibold" x-text="filteredApis.length"></span> of <span class="font-semibold" x-text="stats.total"></span> APIs
+            <div class="mb-4 flex flex-wrap items-center justify-between gap-3 text-gray-700 dark:text-gray-300">
+                <div role="status" aria-live="polite">
+                    Showing <span class="font-semibold" x-text="filteredApis.length"></span> of <span class="font-semibold" x-text="stats.total"></span> APIs
+                    <span x-show="activeProject !== 'all'"> in <span class="font-semibold" x-text="activeProject"></span></span>
+                </div>
+                <div class="no-print flex items-center space-x-3">
+                    <label for="page-size" class="text-sm">Rows per page:</label>
+                    <select
+                        id="page-size"
+                        x-model.number="pageSize"
+                        class="px-2 py-1 border border-gray-500 dark:border-gray-400 dark:bg-gray-800 dark:text-gray-100 rounded-lg text-sm focus:ring-2 focus:ring-blue-600"
+                    >
+                        <option value="25">25</option>
+                        <option value="50">50</option>
+                        <option value="100">100</option>
+                        <option value="0">All</option>
+                    </select>
+                    <button
+                        type="button"
+                        @click="downloadCSV()"
+                        class="px-4 py-2 rounded-lg bg-blue-700 hover:bg-blue-800 text-white text-sm font-medium transition-colors focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-blue-600"
+                    ><span aria-hidden="true">⬇️</span> Download CSV</button>
+                </div>
             </div>
             <!-- Table -->
-            <div class="bg-white rounded-lg shadow-md overflow-hidden">
+            <section id="results" tabindex="-1" role="tabpanel" :aria-labelledby="'tab-' + activeTab" class="bg-white dark:bg-gray-800 rounded-lg shadow-md overflow-hidden focus:outline-none">
                 <div class="overflow-x-auto">
-                    <table class="w-full">
-                        <thead class="bg-gray-50">
+                    <table id="results-table" class="w-full">
+                        <caption class="sr-only" x-text="'APIs (' + activeTabLabel + '), sorted by ' + sortLabel + ' ' + (sortDir === 'asc' ? 'ascending' : 'descending') + '. Column headers with buttons sort the table.'"></caption>
+                        <thead class="bg-gray-50 dark:bg-gray-700">
                             <tr>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">API Name</th>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Display Name</th>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Status</th>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Cost (USD)</th>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Pricing Details</th>
-                                <th class="px-6 py-4 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Checked At</th>
+                                <template x-for="column in visibleColumns" :key="column.key">
+                                    <th
+                                        scope="col"
+                                        :aria-sort="column.sortable ? ariaSort(column.key) : null"
+                                        class="px-6 py-4 text-left text-xs font-medium text-gray-700 dark:text-gray-200 uppercase tracking-wider"
+                                    >
+                                        <template x-if="column.sortable">
+                                            <button
+                                                type="button"
+                                                @click="sortBy(column.key)"
+                                                class="uppercase tracking-wider select-none rounded focus:outline-none focus:ring-2 focus:ring-blue-600"
+                                            ><span x-text="column.label"></span> <span aria-hidden="true" x-text="sortIndicator(column.key)"></span></button>
+                                        </template>
+                                        <template x-if="!column.sortable">
+                                            <span x-text="column.label"></span>
+                                        </template>
+                                    </th>
+                                </template>
                             </tr>
                         </thead>
-                        <tbody class="bg-white divide-y divide-gray-200">
-                            <template x-for="(api, idx) in filteredApis" :key="api.name + idx">
-                                <tr class="hover:bg-gray-50">
-                                    <td class="px-6 py-4 whitespace-nowrap text-sm font-medium text-gray-900" x-text="api.name"></td>
-                                    <td class="px-6 py-4 whitespace-nowrap text-sm text-gray-900" x-text="api.displayName"></td>
+                        <tbody class="bg-white dark:bg-gray-800 divide-y divide-gray-200 dark:divide-gray-700">
+                            <template x-for="(api, idx) in pagedApis" :key="(api.projectId || '') + api.name + idx">
+                                <tr class="hover:bg-gray-50 dark:hover:bg-gray-700" :class="changeClass(api)">
+                                    <td x-show="projects.length > 1" class="px-6 py-4 whitespace-nowrap text-sm text-gray-700 dark:text-gray-300" x-text="api.projectId"></td>
+                                    <th scope="row" class="px-6 py-4 whitespace-nowrap text-sm text-left font-medium text-gray-900 dark:text-gray-100" x-text="api.name"></th>
+                                    <td class="px-6 py-4 whitespace-nowrap text-sm text-gray-900 dark:text-gray-100" x-text="api.displayName"></td>
                                     <td class="px-6 py-4 whitespace-nowrap">
                                         <span 
                                             :class="{
-                                                'bg-green-100 text-green-800': api.status === 'ENABLED',
-                                                'bg-red-100 text-red-800': api.status === 'DISABLED',
-                                                'bg-yellow-100 text-yellow-800': api.status === 'ERROR'
+                                                'bg-green-100 text-green-900': api.status === 'ENABLED',
+                                                'bg-red-100 text-red-900': api.status === 'DISABLED',
+                                                'bg-yellow-100 text-yellow-900': api.status === 'ERROR',
+                                                'bg-cyan-100 text-cyan-900': api.status === 'ENABLING',
+                                                'bg-orange-100 text-orange-900': api.status === 'DISABLING',
+                                                'bg-purple-100 text-purple-900': api.status === 'DEPRECATED',
+                                                'bg-gray-200 text-gray-900': api.status === 'UNKNOWN' || api.status === 'STATE_UNSPECIFIED' || api.status === 'SKIPPED'
                                             }"
                                             class="px-2 py-1 text-xs font-medium rounded-full"
-                                            x-text="api.status"
-                                        ></span>
+                                        ><span aria-hidden="true" x-text="statusIcon(api.status)"></span> <span x-text="api.status"></span></span>
+                                        <span x-show="changeOf(api)" class="ml-1 text-xs font-medium text-indigo-800 dark:text-indigo-300" x-text="changeLabel(api)"></span>
                                     </td>
                                     <td class="px-6 py-4 whitespace-nowrap text-sm">
-                                        <span 
-                                            :class="{
-                                                'text-red-600 font-bold': api.costInfo.estimatedCost > 50,
-                                                'text-yellow-600 font-bold': api.costInfo.estimatedCost > 10 && api.costInfo.estimatedCost <= 50,
-                                                'text-green-600': api.costInfo.estimatedCost <= 10
-                                            }"
-                                            x-text="'$' + (typeof api.costInfo.estimatedCost === 'number' ? api.costInfo.estimatedCost.toFixed(2) : '0.00')"
-                                        ></span>
+                                        <span :class="tierClass(api.costTier)"
+                                        ><span x-text="money(costOf(api))"></span><span class="ml-1 text-xs font-normal" x-show="api.costTier" x-text="'(' + (api.costTier || '').toLowerCase() + ')'"></span></span>
+                                    </td>
+                                    <td class="px-6 py-4 text-sm text-gray-900 dark:text-gray-100">
+                                        <span x-text="api.costInfo.pricing_details"></span>
+                                        <details x-show="(api.costInfo.skus || []).length > 0" class="mt-1">
+                                            <summary class="cursor-pointer text-xs text-blue-700 dark:text-blue-400">SKU breakdown</summary>
+                                            <ul class="mt-1 space-y-1 text-xs text-gray-700 dark:text-gray-300">
+                                                <template x-for="sku in (api.costInfo.skus || [])" :key="sku.name">
+                                                    <li><span class="font-medium" x-text="sku.name"></span>: <span x-text="'$' + sku.unit_price + ' per ' + sku.unit"></span>, <span x-text="'~' + money(sku.estimated_cost) + period.suffix"></span></li>
+                                                </template>
+                                            </ul>
+                                        </details>
                                     </td>
-                                    <td class="px-6 py-4 text-sm text-gray-900" x-text="api.costInfo.pricingDetails"></td>
-                                    <td class="px-6 py-4 whitespace-nowrap text-sm text-gray-500" x-text="new Date(api.checkedAt).toLocaleString()"></td>
+                                    <td x-show="hasAuditData" class="px-6 py-4 text-sm text-gray-700 dark:text-gray-300">
+                                        <div x-text="api.enabledBy || ''"></div>
+                                        <div class="text-xs" x-text="api.enabledAt ? new Date(api.enabledAt).toLocaleString() : ''"></div>
+                                    </td>
+                                    <td class="px-6 py-4 whitespace-nowrap text-sm text-gray-700 dark:text-gray-300"><time :datetime="api.checkedAt" x-text="new Date(api.checkedAt).toLocaleString()"></time></td>
                                 </tr>
                             </template>
                         </tbody>
                     </table>
                 </div>
-            </div>
+            </section>
+            <!-- Pagination -->
+            <nav class="no-print mt-4 flex items-center justify-between text-gray-700 dark:text-gray-300" x-show="totalPages > 1" aria-label="Pagination">
+                <button
+                    type="button"
+                    @click="page = Math.max(1, page - 1)"
+                    :disabled="page === 1"
+                    aria-label="Previous page"
+                    class="px-4 py-2 rounded-lg bg-gray-200 text-gray-800 dark:bg-gray-700 dark:text-gray-100 disabled:opacity-50 focus:outline-none focus:ring-2 focus:ring-blue-600"
+                ><span aria-hidden="true">←</span> Previous</button>
+                <span aria-live="polite">Page <span class="font-semibold" x-text="page"></span> of <span class="font-semibold" x-text="totalPages"></span></span>
+                <button
+                    type="button"
+                    @click="page = Math.min(totalPages, page + 1)"
+                    :disabled="page === totalPages"
+                    aria-label="Next page"
+                    class="px-4 py-2 rounded-lg bg-gray-200 text-gray-800 dark:bg-gray-700 dark:text-gray-100 disabled:opacity-50 focus:outline-none focus:ring-2 focus:ring-blue-600"
+                >Next <span aria-hidden="true">→</span></button>
+            </nav>
+            </main>
         </div>
     </div>
     <script>
     function apiChecker() {
         return {
             apis: [],
+            scores: [],
+            projects: [],
+            activeProject: 'all',
             activeTab: 'all',
+            period: { name: 'monthly', suffix: '/month', factor: 1 },
+            changes: {},
+            tabs: [
+                { id: 'all', label: 'All APIs', icon: '☰', activeClass: 'bg-blue-700' },
+                { id: 'enabled', label: 'Enabled', icon: '✔', activeClass: 'bg-green-700' },
+                { id: 'disabled', label: 'Disabled', icon: '✖', activeClass: 'bg-red-700' },
+                { id: 'errors', label: 'Errors', icon: '⚠', activeClass: 'bg-yellow-700' },
+                { id: 'unknown', label: 'Undetermined', icon: '?', activeClass: 'bg-gray-600' }
+            ],
+            columns: [
+                { key: 'projectId', label: 'Project', sortable: true, multiProject: true },
+                { key: 'name', label: 'API Name', sortable: true },
+                { key: 'displayName', label: 'Display Name', sortable: true },
+                { key: 'status', label: 'Status', sortable: true },
+                { key: 'cost', label: 'Cost (USD)', sortable: true },
+                { key: 'pricingDetails', label: 'Pricing Details' },
+                { key: 'enabledBy', label: 'Enabled By', audit: true },
+                { key: 'checkedAt', label: 'Checked At', sortable: true }
+            ],
+            costTiers: ['CRITICAL', 'HIGH', 'MEDIUM', 'LOW', 'FREE'],
+            activeTier: 'all',
             searchTerm: '',
+            sortKey: 'name',
+            sortDir: 'asc',
+            page: 1,
+            pageSize: 50,
+            darkMode: document.documentElement.classList.contains('dark'),
+            live: false,
+            liveState: 'queued',
+            liveDone: 0,
+            liveTotal: 0,
+            get visibleScores() {
+                if (this.activeProject === 'all') return this.scores;
+                return this.scores.filter(s => s.project_id === this.activeProject);
+            },
+            get projectApis() {
+                if (this.activeProject === 'all') return this.apis;
+                return this.apis.filter(api => api.projectId === this.activeProject);
+            },
             get filteredApis() {
-                return this.apis.filter(api => {
+                return this.projectApis.filter(api => {
                     const matchesSearch = !this.searchTerm || 
                         api.name.toLowerCase().includes(this.searchTerm.toLowerCase()) ||
                         api.displayName.toLowerCase().includes(this.searchTerm.toLowerCase());
-                    if (this.activeTab === 'all') return matchesSearch;
-                    if (this.activeTab === 'enabled') return matchesSearch && api.status === 'ENABLED';
-                    if (this.activeTab === 'disabled') return matchesSearch && api.status === 'DISABLED';
-                    if (this.activeTab === 'errors') return matchesSearch && api.status === 'ERROR';
-                    return matchesSearch;
+                    const matchesTier = this.activeTier === 'all' || api.costTier === this.activeTier;
+                    if (this.activeTab === 'all') return matchesSearch && matchesTier;
+                    if (this.activeTab === 'changes') return matchesSearch && matchesTier && !!this.changeOf(api);
+                    return matchesSearch && matchesTier && this.statusGroup(api) === this.activeTab;
+                });
+            },
+            get sortedApis() {
+                const dir = this.sortDir === 'asc' ? 1 : -1;
+                const value = (api) => {
+                    switch (this.sortKey) {
+                        case 'cost': return this.costOf(api);
+                        case 'checkedAt': return new Date(api.checkedAt).getTime();
+                        default: return (api[this.sortKey] || '').toString().toLowerCase();
+                    }
+                };
+                return [...this.filteredApis].sort((a, b) => {
+                    const va = value(a), vb = value(b);
+                    if (va < vb) return -dir;
+                    if (va > vb) return dir;
+                    return 0;
                 });
             },
+            get totalPages() {
+                if (!this.pageSize) return 1;
+                return Math.max(1, Math.ceil(this.filteredApis.length / this.pageSize));
+            },
+            get pagedApis() {
+                if (!this.pageSize) return this.sortedApis;
+                const start = (this.page - 1) * this.pageSize;
+                return this.sortedApis.slice(start, start + this.pageSize);
+            },
+            sortBy(key) {
+                if (this.sortKey === key) {
+                    this.sortDir = this.sortDir === 'asc' ? 'desc' : 'asc';
+                } else {
+                    this.sortKey = key;
+                    this.sortDir = key === 'cost' || key === 'checkedAt' ? 'desc' : 'asc';
+                }
+                this.page = 1;
+            },
+            sortIndicator(key) {
+                if (this.sortKey !== key) return '';
+                return this.sortDir === 'asc' ? '▲' : '▼';
+            },
+            ariaSort(key) {
+                if (this.sortKey !== key) return 'none';
+                return this.sortDir === 'asc' ? 'ascending' : 'descending';
+            },
+            get visibleColumns() {
+                return this.columns.filter(column =>
+                    (!column.multiProject || this.projects.length > 1) && (!column.audit || this.hasAuditData));
+            },
+            get sortLabel() {
+                const column = this.columns.find(c => c.key === this.sortKey);
+                return column ? column.label : this.sortKey;
+            },
+            get activeTabLabel() {
+                return this.tabs.find(tab => tab.id === this.activeTab).label;
+            },
+            // moveTab selects the next or previous tab and focuses it, as arrow keys do in a tablist
+            moveTab(step) {
+                const index = this.tabs.findIndex(tab => tab.id === this.activeTab);
+                const next = Math.min(this.tabs.length - 1, Math.max(0, index + step));
+                this.activeTab = this.tabs[next].id;
+                this.$nextTick(() => document.getElementById('tab-' + this.activeTab).focus());
+            },
+            // statusIcon gives each status a symbol so it is not told apart by color alone
+            statusIcon(status) {
+                return { ENABLED: '✔', DISABLED: '✖', ENABLING: '↗', DISABLING: '↘', DEPRECATED: '⊘', STATE_UNSPECIFIED: '?', UNKNOWN: '?', SKIPPED: '»', ERROR: '⚠' }[status] || '';
+            },
+            // statusGroup puts an API under the enabled, disabled, errors or unknown tab;
+            // transitional and deprecated APIs count as enabled while they still serve calls
+            statusGroup(api) {
+                if (api.status === 'ERROR') return 'errors';
+                if (api.status === 'UNKNOWN' || api.status === 'STATE_UNSPECIFIED' || api.status === 'SKIPPED') return 'unknown';
+                return api.enabled ? 'enabled' : 'disabled';
+            },
+            // changeOf is the API's change since the baseline scan, undefined when unchanged
+            changeOf(api) {
+                return this.changes[(api.projectId || '') + '/' + api.name];
+            },
+            // changeClass highlights rows changed since the baseline, with a left border so print keeps it
+            changeClass(api) {
+                const change = this.changeOf(api);
+                if (!change) return '';
+                return {
+                    added: 'bg-green-50 dark:bg-green-900/30 border-l-4 border-green-600',
+                    removed: 'bg-red-50 dark:bg-red-900/30 border-l-4 border-red-600',
+                    changed: 'bg-indigo-50 dark:bg-indigo-900/30 border-l-4 border-indigo-600'
+                }[change.kind];
+            },
+            // changeLabel says what the API was in the baseline scan
+            changeLabel(api) {
+                const change = this.changeOf(api);
+                if (!change) return '';
+                const was = change.previous_status === 'NOT_LISTED' ? 'new' : 'was ' + change.previous_status;
+                const delta = Math.abs(change.cost_delta) >= 0.005 ? ', ' + (change.cost_delta > 0 ? '+' : '-') + this.money(Math.abs(change.cost_delta)) : '';
+                return '(' + was + delta + ')';
+            },
+            // tierClass colors a cost by its tier, as the console and PDF do
+            tierClass(tier) {
+                return {
+                    CRITICAL: 'text-red-700 dark:text-red-400 font-bold',
+                    HIGH: 'text-pink-700 dark:text-pink-400 font-bold',
+                    MEDIUM: 'text-yellow-800 dark:text-yellow-400 font-bold',
+                    LOW: 'text-cyan-800 dark:text-cyan-400',
+                    FREE: 'text-green-800 dark:text-green-400'
+                }[tier] || 'text-gray-700 dark:text-gray-300';
+            },
+            // costOf is the API's monthly estimate, which the cost thresholds apply to
+            costOf(api) {
+                return api.costInfo.estimated_cost || 0;
+            },
+            // money shows a monthly amount for the selected --period
+            money(monthly) {
+                return '$' + (monthly * this.period.factor).toFixed(2);
+            },
+            downloadCSV() {
+                const escape = (v) => {
+                    const s = (v === undefined || v === null) ? '' : String(v);
+                    return /[",\n]/.test(s) ? '"' + s.replace(/"/g, '""') + '"' : s;
+                };
+                const header = ['Project', 'API Name', 'Display Name', 'Status', 'Estimated Cost (USD' + this.period.suffix + ')', 'Cost Tier', 'Pricing Details', 'Checked At', 'Error'];
+                const rows = this.sortedApis.map(api => [
+                    api.projectId, api.name, api.displayName, api.status,
+                    (this.costOf(api) * this.period.factor).toFixed(2), api.costTier, api.costInfo.pricing_details,
+                    api.checkedAt, api.error
+                ].map(escape).join(','));
+                const blob = new Blob([[header.join(','), ...rows].join('\n')], { type: 'text/csv;charset=utf-8' });
+                const link = document.createElement('a');
+                link.href = URL.createObjectURL(blob);
+                link.download = 'google_api_checker_filtered.csv';
+                link.click();
+                URL.revokeObjectURL(link.href);
+            },
+            computeStats(apis) {
+                const total = apis.length;
+                const enabled = apis.filter(api => this.statusGroup(api) === 'enabled').length;
+                const disabled = apis.filter(api => this.statusGroup(api) === 'disabled').length;
+                const errors = apis.filter(api => this.statusGroup(api) === 'errors').length;
+                const unknown = apis.filter(api => this.statusGroup(api) === 'unknown').length;
+                const totalCost = apis.reduce((sum, api) => sum + this.costOf(api), 0);
+                return { total, enabled, disabled, errors, unknown, totalCost };
+            },
+            get hasAuditData() {
+                return this.apis.some(api => api.enabledBy);
+            },
             get stats() {
-                const total = this.apis.length;
-                const enabled = this.apis.filter(api => api.status === 'ENABLED').length;
-                const disabled = this.apis.filter(api => api.status === 'DISABLED').length;
-                const errors = this.apis.filter(api => api.status === 'ERROR').length;
-                const totalCost = this.apis.reduce((sum, api) => sum + (api.costInfo.estimatedCost || 0), 0);
-                return { total, enabled, disabled, errors, totalCost };
+                return this.computeStats(this.projectApis);
+            },
+            get projectStats() {
+                return this.projects.map(project => ({
+                    project,
+                    ...this.computeStats(this.apis.filter(api => api.projectId === project))
+                }));
+            },
+            // fromResult converts a streamed API result to the shape of the embedded rows
+            fromResult(r) {
+                const cost = r.cost_info || {};
+                return {
+                    projectId: r.project_id, name: r.name, displayName: r.display_name,
+                    status: r.status, enabled: r.enabled, checkedAt: r.checked_at,
+                    enabledBy: r.enabled_by, enabledAt: r.enabled_at, error: r.error,
+                    costInfo: {
+                        has_pricing: cost.has_pricing, unlimited_cost: cost.unlimited_cost,
+                        estimated_cost: cost.estimated_cost, currency: cost.currency,
+                        pricing_details: cost.pricing_details
+                    }
+                };
+            },
+            followScan(url) {
+                this.live = true;
+                const source = new EventSource(url);
+                source.addEventListener('result', (e) => {
+                    const api = this.fromResult(JSON.parse(e.data));
+                    this.apis.push(api);
+                    if (api.projectId && !this.projects.includes(api.projectId)) {
+                        this.projects = [...this.projects, api.projectId].sort();
+                    }
+                });
+                source.addEventListener('progress', (e) => {
+                    const status = JSON.parse(e.data);
+                    this.liveState = status.status;
+                    const progress = status.progress || [];
+                    this.liveDone = progress.reduce((sum, p) => sum + p.done, 0);
+                    this.liveTotal = progress.reduce((sum, p) => sum + p.total, 0);
+                });
+                ['done', 'failed'].forEach(name => source.addEventListener(name, () => {
+                    source.close();
+                    this.liveState = name;
+                    window.location.reload();
+                }));
+            },
+            toggleTheme() {
+                this.darkMode = !this.darkMode;
+                document.documentElement.classList.toggle('dark', this.darkMode);
+                localStorage.setItem('theme', this.darkMode ? 'dark' : 'light');
             },
             init() {
                 this.apis = JSON.parse(document.getElementById('apidata').textContent);
+                this.scores = JSON.parse(document.getElementById('scoredata').textContent) || [];
+                this.period = JSON.parse(document.getElementById('perioddata').textContent) || this.period;
+                const changes = JSON.parse(document.getElementById('changedata').textContent);
+                if (changes) {
+                    [...changes.added, ...changes.removed, ...changes.changed].forEach(change => {
+                        this.changes[(change.project_id || '') + '/' + change.api] = change;
+                    });
+                    this.tabs.push({ id: 'changes', label: 'Changes (' + Object.keys(this.changes).length + ')', icon: '⇄', activeClass: 'bg-indigo-700' });
+                }
+                this.columns.find(column => column.key === 'cost').label = 'Cost (USD' + this.period.suffix + ')';
+                this.projects = [...new Set(this.apis.map(api => api.projectId).filter(Boolean))].sort();
+                ['searchTerm', 'activeTab', 'activeProject', 'pageSize'].forEach(key => this.$watch(key, () => { this.page = 1; }));
+
+                // Print every matching row rather than just the current page
+                let savedPageSize = this.pageSize;
+                window.addEventListener('beforeprint', () => { savedPageSize = this.pageSize; this.pageSize = 0; });
+                window.addEventListener('afterprint', () => { this.pageSize = savedPageSize; });
+
+                const live = JSON.parse(document.getElementById('livedata').textContent);
+                if (live && live.events) this.followScan(live.events);
             }
         }
     }
     </script>
 </body>
-</html>`, generateJSONData(results), time.Now().Format("2006-01-02 15:04:05"))
+</html>`, generateJSONData(results), generateScoreData(report.Scores), generateLiveData(eventsURL), generatePeriodData(), generateChangeData(report.Changes), time.Now().Format("2006-01-02 15:04:05"), html.EscapeString(toolVersion()), reportBanners(report), htmlTrendSection(report.Trend))
 
-	_, err = file.WriteString(htmlContent)
+	_, err := io.WriteString(w, htmlContent)
 	return err
 }
 
+// generatePeriodData converts the cost period to JSON for Alpine.js
+func generatePeriodData() string {
+	data, err := json.Marshal(projection)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// generateChangeData converts the changes since the --baseline scan to JSON for Alpine.js, null without one
+func generateChangeData(changes *ScanDiff) string {
+	if changes == nil {
+		return "null"
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// keylessNotice explains what a --keyless report cannot show
+const keylessNotice = "Keyless scan of public Discovery data: whether APIs are enabled is not available"
+
+// skippedNotice explains a partial report, empty when no check was skipped
+func skippedNotice(report *Report) string {
+	if report.Summary.SkippedCount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Partial report: the Service Usage quota ran out and %d APIs were not checked (SKIPPED)", report.Summary.SkippedCount)
+}
+
+// reportBanners are the HTML report's partial-report and keyless notices, empty for other scans
+func reportBanners(report *Report) string {
+	var banners string
+	if notice := skippedNotice(report); notice != "" {
+		banners += `<p class="mt-2 inline-block bg-red-100 text-red-900 rounded px-3 py-1" role="alert">` + html.EscapeString(notice) + `</p> `
+	}
+	if report.Changes != nil {
+		banners += `<p class="mt-2 inline-block bg-indigo-100 text-indigo-900 rounded px-3 py-1" role="note">` + html.EscapeString(fmt.Sprintf("Compared with %s: %d APIs enabled, %d no longer enabled, %d changed",
+			report.Changes.Previous.Source, len(report.Changes.Added), len(report.Changes.Removed), len(report.Changes.Changed))) + `</p> `
+	}
+	if report.Summary.Keyless {
+		banners += `<p class="mt-2 inline-block bg-yellow-100 text-yellow-900 rounded px-3 py-1" role="note">` + html.EscapeString(keylessNotice) + `</p>`
+	}
+	return banners
+}
+
+// generateLiveData converts the live events URL to JSON for Alpine.js, null for a static report
+func generateLiveData(eventsURL string) string {
+	if eventsURL == "" {
+		return "null"
+	}
+	data, err := json.Marshal(map[string]string{"events": eventsURL})
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// generateScoreData converts project scores to JSON for Alpine.js
+func generateScoreData(scores []ProjectScore) string {
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
 // generateJSONData converts API results to JSON for Alpine.js
 func generateJSONData(results []APIResult) string {
 	type APIData struct {
-		Name        string    `json:"name"`
-		DisplayName string    `json:"displayName"`
-		Status      string    `json:"status"`
-		Enabled     bool      `json:"enabled"`
-		CostInfo    CostInfo  `json:"costInfo"`
-		CheckedAt   time.Time `json:"checkedAt"`
-		Error       string    `json:"error,omitempty"`
+		ProjectID   string     `json:"projectId,omitempty"`
+		Name        string     `json:"name"`
+		DisplayName string     `json:"displayName"`
+		Status      string     `json:"status"`
+		Enabled     bool       `json:"enabled"`
+		CostInfo    CostInfo   `json:"costInfo"`
+		CostTier    string     `json:"costTier,omitempty"`
+		CheckedAt   time.Time  `json:"checkedAt"`
+		EnabledBy   string     `json:"enabledBy,omitempty"`
+		EnabledAt   *time.Time `json:"enabledAt,omitempty"`
+		Error       string     `json:"error,omitempty"`
 	}
 
 	var apiData []APIData
 	for _, result := range results {
 		apiData = append(apiData, APIData{
+			ProjectID:   result.ProjectID,
 			Name:        result.Name,
 			DisplayName: result.DisplayName,
 			Status:      result.Status,
 			Enabled:     result.Enabled,
 			CostInfo:    result.CostInfo,
+			CostTier:    result.CostTier,
 			CheckedAt:   result.CheckedAt,
+			EnabledBy:   result.EnabledBy,
+			EnabledAt:   result.EnabledAt,
 			Error:       result.Error,
 		})
 	}
@@ -429,43 +1234,319 @@ func PrintReport(report *Report) {
 		bgYellow = "\033[43m"
 	)
 
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Printf(bold + cyan + "📊 GOOGLE API CHECKER - ANALYSIS REPORT" + reset + "\n")
-	fmt.Println(strings.Repeat("=", 80))
+	fmt.Fprintln(console, "\n"+strings.Repeat("=", 80))
+	fmt.Fprintf(console, bold+cyan+"📊 GOOGLE API CHECKER - ANALYSIS REPORT"+reset+"\n")
+	fmt.Fprintln(console, strings.Repeat("=", 80))
+	if len(report.Tags) > 0 {
+		fmt.Fprintf(console, "🏷️  Tags: %s\n", formatTags(report.Tags))
+	}
+	if report.Summary.Keyless {
+		fmt.Fprintf(console, yellow+"🔓 %s"+reset+"\n", keylessNotice)
+	}
+	if notice := skippedNotice(report); notice != "" {
+		fmt.Fprintf(console, bold+red+"⛔ %s"+reset+"\n", notice)
+	}
 
 	// Summary
-	fmt.Printf("\n" + bold + "📈 SUMMARY:" + reset + "\n")
-	fmt.Printf("   Total APIs checked: %s%d%s\n", blue, report.Summary.TotalAPIs, reset)
-	fmt.Printf("   Enabled APIs: %s%d%s\n", green, report.Summary.EnabledCount, reset)
-	fmt.Printf("   Disabled APIs: %s%d%s\n", yellow, report.Summary.DisabledCount, reset)
-	fmt.Printf("   Errors: %s%d%s\n", red, report.Summary.ErrorCount, reset)
-	fmt.Printf("   Total estimated monthly cost: %s$%.2f %s%s\n", magenta, report.Summary.TotalCost, report.Summary.Currency, reset)
+	fmt.Fprintf(console, "\n"+bold+"📈 SUMMARY:"+reset+"\n")
+	fmt.Fprintf(console, "   Total APIs checked: %s%d%s\n", blue, report.Summary.TotalAPIs, reset)
+	fmt.Fprintf(console, "   Enabled APIs: %s%d%s\n", green, report.Summary.EnabledCount, reset)
+	fmt.Fprintf(console, "   Disabled APIs: %s%d%s\n", yellow, report.Summary.DisabledCount, reset)
+	fmt.Fprintf(console, "   Errors: %s%d%s\n", red, report.Summary.ErrorCount, reset)
+	if report.Summary.UnknownCount > 0 {
+		fmt.Fprintf(console, "   Undetermined (no project): %d\n", report.Summary.UnknownCount)
+	}
+	if report.Summary.SkippedCount > 0 {
+		fmt.Fprintf(console, "   Skipped (quota exhausted): %s%d%s\n", red, report.Summary.SkippedCount, reset)
+	}
+	if report.Summary.CostSkipped {
+		fmt.Fprintf(console, "   Total estimated %s cost: %sskipped%s\n", projection.Name, magenta, reset)
+	} else {
+		fmt.Fprintf(console, "   Total estimated %s cost: %s$%.2f %s%s\n", projection.Name, magenta, projection.amount(report.Summary.TotalCost), report.Summary.Currency, reset)
+	}
+
+	if report.Summary.ActualCost != nil {
+		fmt.Fprintf(console, "   Actual cost last month: %s$%.2f %s%s\n", magenta, *report.Summary.ActualCost, report.Summary.Currency, reset)
+	}
+	if report.Summary.PotentialSavings > 0 {
+		fmt.Fprintf(console, "   Potential savings: %s$%.2f %s%s%s (%d APIs)\n", green, projection.amount(report.Summary.PotentialSavings), report.Summary.Currency, projection.Suffix, reset, len(report.Savings.Items))
+	}
+
+	printScores(report.Scores)
+
+	if len(report.Contacts) > 0 {
+		fmt.Fprintf(console, "\n"+bold+"👤 OWNERS & CONTACTS:"+reset+"\n")
+		for _, project := range report.Contacts {
+			fmt.Fprintf(console, "   %s: %s\n", project.ProjectID, project)
+		}
+	}
+
+	// Project-level findings
+	if len(report.Findings) > 0 {
+		fmt.Fprintf(console, "\n"+bold+yellow+"🧾 PROJECT FINDINGS (%d):"+reset+"\n", len(report.Findings))
+		for _, finding := range report.Findings {
+			color := reset
+			switch finding.Severity {
+			case "high":
+				color = red
+			case "medium":
+				color = yellow
+			}
+			fmt.Fprintf(console, "   %s• [%s] %s%s\n", color, strings.ToUpper(finding.Severity), finding, reset)
+			if len(finding.Recipients) > 0 {
+				fmt.Fprintf(console, "     → %s\n", strings.Join(finding.Recipients, ", "))
+			}
+		}
+	}
+
+	// Benchmark controls the scan found failing
+	if len(report.Compliance) > 0 {
+		fmt.Fprintf(console, "\n"+bold+yellow+"📋 CIS BENCHMARK CONTROLS FAILED (%d):"+reset+"\n", len(report.Compliance))
+		for _, finding := range report.Compliance {
+			fmt.Fprintf(console, "   "+red+"• %s"+reset+"\n", finding)
+		}
+	}
+
+	// Cost jumps since the previous scan
+	if len(report.CostAnomalies) > 0 {
+		fmt.Fprintf(console, "\n"+bgRed+white+bold+"📈 COST ANOMALIES SINCE PREVIOUS SCAN (%d):"+reset+"\n", len(report.CostAnomalies))
+		for _, anomaly := range report.CostAnomalies {
+			fmt.Fprintf(console, bold+red+"   • %s"+reset+"\n", anomaly)
+		}
+	}
+
+	// Abuse risk is shown right after the summary
+	if len(report.RiskScoredAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bgRed+white+bold+"🚨 MAPS PLATFORM ABUSE RISK (%d):"+reset+"\n", len(report.RiskScoredAPIs))
+		scored, hidden := topAPIs(report.RiskScoredAPIs)
+		for _, api := range scored {
+			color := green
+			switch riskLevel(*api.RiskScore) {
+			case "HIGH":
+				color = red
+			case "MEDIUM":
+				color = yellow
+			}
+			fmt.Fprintf(console, bold+color+"   • %s: %d/100 (%s)"+reset+"\n", api.DisplayName, *api.RiskScore, riskLevel(*api.RiskScore))
+			fmt.Fprintf(console, "     %s\n", strings.Join(api.RiskFactors, ", "))
+		}
+		printHidden("   ", hidden)
+	}
+
+	// Policy violations
+	if len(report.PolicyViolations) > 0 {
+		fmt.Fprintf(console, "\n"+bgRed+white+bold+"🚫 POLICY VIOLATIONS (%d):"+reset+"\n", len(report.PolicyViolations))
+		for _, violation := range report.PolicyViolations {
+			fmt.Fprintf(console, bold+red+"   • %s"+reset+"\n", violationText(violation))
+		}
+	}
+
+	// Drift from the expected manifest
+	if len(report.Drift) > 0 {
+		fmt.Fprintf(console, "\n"+bgRed+white+bold+"🧭 DRIFT FROM EXPECTED STATE (%d):"+reset+"\n", len(report.Drift))
+		for _, drift := range report.Drift {
+			fmt.Fprintf(console, bold+red+"   • %s"+reset+"\n", drift)
+		}
+	}
 
 	// Cost Analysis
 	if len(report.CostAnalysis.UnlimitedCostAPIs) > 0 {
-		fmt.Printf("\n"+bgRed+white+bold+"⚠️  UNLIMITED COST APIS (%d):"+reset+"\n", len(report.CostAnalysis.UnlimitedCostAPIs))
-		for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
-			fmt.Printf(bold+red+"   • %s"+reset+"\n", api.DisplayName)
-			fmt.Printf("     %s%s%s\n", yellow, api.CostInfo.PricingDetails, reset)
+		fmt.Fprintf(console, "\n"+bgRed+white+bold+"⚠️  UNLIMITED COST APIS (%d):"+reset+"\n", len(report.CostAnalysis.UnlimitedCostAPIs))
+		unlimited, hidden := topAPIs(mostExpensiveFirst(report.CostAnalysis.UnlimitedCostAPIs))
+		for _, api := range unlimited {
+			fmt.Fprintf(console, bold+red+"   • %s"+reset+"\n", api.DisplayName)
+			fmt.Fprintf(console, "     %s%s%s\n", yellow, api.CostInfo.PricingDetails, reset)
+			if api.EnabledBy != "" && api.EnabledAt != nil {
+				fmt.Fprintf(console, "     Enabled by %s on %s\n", api.EnabledBy, api.EnabledAt.Format("2006-01-02"))
+			}
+		}
+		printHidden("   ", hidden)
+	}
+
+	if len(report.IncidentAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bold+red+"🩺 ACTIVE INCIDENTS (%d APIs):"+reset+"\n", len(report.IncidentAPIs))
+		incidentAPIs, hidden := topAPIs(report.IncidentAPIs)
+		for _, api := range incidentAPIs {
+			fmt.Fprintf(console, bold+"   • %s"+reset+"\n", incidentAPILabel(api))
+			for _, incident := range api.Incidents {
+				fmt.Fprintf(console, "     %s\n", incident)
+			}
+		}
+		printHidden("   ", hidden)
+	}
+
+	if len(report.HighRiskAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bgYellow+bold+"🎯 COMMON ABUSE TARGETS (%d):"+reset+"\n", len(report.HighRiskAPIs))
+		highRisk, hidden := topAPIs(report.HighRiskAPIs)
+		for _, api := range highRisk {
+			fmt.Fprintf(console, bold+yellow+"   • %s"+reset+"\n", api.DisplayName)
+			fmt.Fprintf(console, "     %s\n", api.RiskNote)
+		}
+		printHidden("   ", hidden)
+	}
+
+	if len(report.CostAnalysis.ReconciledAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bold+blue+"🧮 ESTIMATED VS ACTUAL (last month):"+reset+"\n")
+		table := newConsoleTable(
+			tableColumn{Header: "API", Flexible: true, MinWidth: 12},
+			tableColumn{Header: "Estimated", Right: true},
+			tableColumn{Header: "Actual", Right: true},
+			tableColumn{Header: "Difference", Right: true, Drop: 1},
+		)
+		reconciled, hidden := topAPIs(report.CostAnalysis.ReconciledAPIs)
+		for _, api := range reconciled {
+			table.AddRow(incidentAPILabel(api),
+				fmt.Sprintf("$%.2f", api.CostInfo.EstimatedCost),
+				fmt.Sprintf("$%.2f", *api.CostInfo.ActualCost),
+				fmt.Sprintf("%+.2f", reconciliationGap(api)))
+		}
+		table.Render(console, consoleWidth())
+		printHidden("   ", hidden)
+	}
+
+	if len(report.CostAnalysis.CostAllocation) > 0 {
+		fmt.Fprintf(console, "\n"+bold+blue+"👥 BILLED COST BY LABEL (last month):"+reset+"\n")
+		table := newConsoleTable(
+			tableColumn{Header: "Label", Flexible: true, MinWidth: 12},
+			tableColumn{Header: "Cost", Right: true},
+			tableColumn{Header: "APIs", Right: true, Drop: 1},
+		)
+		for _, entry := range report.CostAnalysis.CostAllocation {
+			table.AddRow(entry.Label+"="+entry.Value, fmt.Sprintf("$%.2f", entry.Cost), strconv.Itoa(len(entry.APIs)))
+		}
+		table.Render(console, consoleWidth())
+	}
+
+	if len(report.CostAnalysis.FreeTierAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bold+green+"🆓 COVERED BY FREE TIER AT EXPECTED USAGE (%d):"+reset+"\n", len(report.CostAnalysis.FreeTierAPIs))
+		freeTier, hidden := topAPIs(report.CostAnalysis.FreeTierAPIs)
+		for _, api := range freeTier {
+			fmt.Fprintf(console, "   • %s: %s %s/month\n", api.DisplayName, formatQuantity(api.CostInfo.ExpectedUsage), api.CostInfo.UsageUnit)
 		}
+		printHidden("   ", hidden)
+	}
+
+	if len(report.UnusedAPIs) > 0 {
+		fmt.Fprintf(console, "\n"+bold+cyan+"🧹 ENABLED BUT UNUSED (no requests in 90 days) (%d):"+reset+"\n", len(report.UnusedAPIs))
+		unused, hidden := topAPIs(report.UnusedAPIs)
+		for _, api := range unused {
+			fmt.Fprintf(console, "   • %s (%s)\n", api.DisplayName, api.Name)
+		}
+		printHidden("   ", hidden)
 	}
 
 	if len(report.CostAnalysis.HighCostAPIs) > 0 {
-		fmt.Printf("\n" + bgYellow + bold + "💰 HIGH COST APIS (>$50/month):" + reset + "\n")
-		for _, api := range report.CostAnalysis.HighCostAPIs {
-			fmt.Printf(bold+magenta+"   • %s: $%.2f/month"+reset+"\n", api.DisplayName, api.CostInfo.EstimatedCost)
+		fmt.Fprintf(console, "\n"+bgYellow+bold+"💰 HIGH COST APIS (>%s):"+reset+"\n", formatCost(report.CostAnalysis.HighCostThreshold))
+		highCost, hidden := topAPIs(report.CostAnalysis.HighCostAPIs)
+		for _, api := range highCost {
+			fmt.Fprintf(console, bold+"   • %s: %s"+reset+"\n", api.DisplayName, coloredCostTier(api.CostTier, formatCost(api.CostInfo.EstimatedCost)+" ("+api.CostTier+")"))
 		}
+		printHidden("   ", hidden)
+	}
+
+	if groupBy != groupByNone {
+		printAPIGroups(report, groupBy)
+	}
+
+	// Check timing
+	if perf := report.Performance; perf != nil {
+		fmt.Fprintf(console, "\n"+bold+"⏱️  PERFORMANCE (%d checks):"+reset+"\n", perf.Checks)
+		throttleColor := green
+		if perf.Throttled > 0 {
+			throttleColor = red
+		}
+		fmt.Fprintf(console, "   p50 %dms | p95 %dms | max %dms | %sthrottled %d%s\n", perf.P50Ms, perf.P95Ms, perf.MaxMs, throttleColor, perf.Throttled, reset)
+		for _, api := range perf.Slowest {
+			fmt.Fprintf(console, "   • %s: %dms\n", api.Name, api.DurationMs)
+		}
+	}
+
+	// Quota the scan itself used
+	if overhead := report.Overhead; overhead != nil {
+		fmt.Fprintf(console, "\n"+bold+"📡 SCAN OVERHEAD (%d requests):"+reset+"\n", overhead.Requests)
+		throttleColor := green
+		if overhead.Throttled > 0 {
+			throttleColor = red
+		}
+		fmt.Fprintf(console, "   %sthrottled %d (%.1f%%)%s | failed %d\n", throttleColor, overhead.Throttled, overhead.ThrottleRate, reset, overhead.Failed)
+		table := newConsoleTable(
+			tableColumn{Header: "API", Flexible: true, MinWidth: 12},
+			tableColumn{Header: "Requests", Right: true},
+			tableColumn{Header: "Throttled", Right: true},
+			tableColumn{Header: "Failed", Right: true, Drop: 1},
+		)
+		for _, calls := range overhead.Services {
+			table.AddRow(calls.Service, strconv.Itoa(calls.Requests), strconv.Itoa(calls.Throttled), strconv.Itoa(calls.Failed))
+		}
+		table.Render(console, consoleWidth())
 	}
 
 	// Recommendations
 	if len(report.Recommendations) > 0 {
-		fmt.Printf("\n" + bold + blue + "💡 RECOMMENDATIONS:" + reset + "\n")
+		fmt.Fprintf(console, "\n"+bold+blue+"💡 RECOMMENDATIONS:"+reset+"\n")
 		for _, rec := range report.Recommendations {
-			fmt.Printf("   %s%s%s\n", green, rec, reset)
+			fmt.Fprintf(console, "   %s%s%s\n", green, rec, reset)
+		}
+	}
+
+	fmt.Fprintln(console, "\n"+strings.Repeat("=", 80))
+	fmt.Fprintf(console, "Report generated at: %s by Google API Checker %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"), report.ToolVersion)
+	fmt.Fprintln(console, strings.Repeat("=", 80))
+}
+
+// PrintSummaryReport prints a condensed report with counts and the enabled APIs only
+func PrintSummaryReport(report *Report) {
+	const (
+		reset  = "\033[0m"
+		bold   = "\033[1m"
+		red    = "\033[31m"
+		green  = "\033[32m"
+		yellow = "\033[33m"
+		blue   = "\033[34m"
+		cyan   = "\033[36m"
+	)
+
+	fmt.Fprintln(console, "\n"+strings.Repeat("=", 80))
+	fmt.Fprintf(console, bold+cyan+"📊 GOOGLE API CHECKER - SUMMARY"+reset+"\n")
+	fmt.Fprintln(console, strings.Repeat("=", 80))
+	if report.Summary.Keyless {
+		fmt.Fprintf(console, yellow+"🔓 %s"+reset+"\n", keylessNotice)
+	}
+	if notice := skippedNotice(report); notice != "" {
+		fmt.Fprintf(console, bold+red+"⛔ %s"+reset+"\n", notice)
+	}
+
+	fmt.Fprintf(console, "   Total APIs checked: %s%d%s\n", blue, report.Summary.TotalAPIs, reset)
+	fmt.Fprintf(console, "   Enabled APIs: %s%d%s\n", green, report.Summary.EnabledCount, reset)
+	fmt.Fprintf(console, "   Disabled APIs: %s%d%s\n", yellow, report.Summary.DisabledCount, reset)
+	fmt.Fprintf(console, "   Errors: %s%d%s\n", red, report.Summary.ErrorCount, reset)
+	if report.Summary.UnknownCount > 0 {
+		fmt.Fprintf(console, "   Undetermined (no project): %d\n", report.Summary.UnknownCount)
+	}
+	if report.Summary.SkippedCount > 0 {
+		fmt.Fprintf(console, "   Skipped (quota exhausted): %s%d%s\n", red, report.Summary.SkippedCount, reset)
+	}
+
+	printScores(report.Scores)
+
+	if len(report.EnabledAPIs) > 0 {
+		names := make([]string, 0, len(report.EnabledAPIs))
+		for _, api := range report.EnabledAPIs {
+			names = append(names, api.Name)
+		}
+		sort.Strings(names)
+
+		hidden := 0
+		if limit := consoleLimit(); limit > 0 && len(names) > limit {
+			names, hidden = names[:limit], len(names)-limit
+		}
+
+		fmt.Fprintf(console, "\n"+bold+"✅ ENABLED APIS:"+reset+"\n")
+		for _, name := range names {
+			fmt.Fprintf(console, "   • %s\n", name)
 		}
+		printHidden("   ", hidden)
 	}
 
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Printf("Report generated at: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
-	fmt.Println(strings.Repeat("=", 80))
+	fmt.Fprintln(console, strings.Repeat("=", 80))
 }
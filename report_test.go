@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestCostBreakdownSumsProjects(t *testing.T) {
+	priced := func(project, name, display string, cost float64) APIResult {
+		return APIResult{ProjectID: project, Name: name, DisplayName: display, Status: statusEnabled, Enabled: true,
+			CostInfo: CostInfo{HasPricing: true, EstimatedCost: cost, Currency: "USD"}}
+	}
+	report := GenerateReport([]APIResult{
+		priced("project-a", "compute.googleapis.com", "Compute Engine API", 100),
+		priced("project-b", "compute.googleapis.com", "Compute Engine API", 40),
+		priced("project-b", "vision.googleapis.com", "Cloud Vision API", 10),
+	})
+
+	breakdown := report.CostAnalysis.CostBreakdown
+	if breakdown["Compute Engine API"] != 140 || breakdown["Cloud Vision API"] != 10 {
+		t.Errorf("breakdown %v, want Compute Engine API 140 and Cloud Vision API 10", breakdown)
+	}
+	sum := 0.0
+	for _, cost := range breakdown {
+		sum += cost
+	}
+	if sum != report.Summary.TotalCost {
+		t.Errorf("breakdown sums to %v, total cost is %v", sum, report.Summary.TotalCost)
+	}
+}
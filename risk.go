@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// riskScoredCategory is the product category scored for key abuse risk
+const riskScoredCategory = "Maps & Location"
+
+// Risk score weights; they add up to 100
+const (
+	riskWeightEnabled         = 30
+	riskWeightUnrestrictedKey = 40
+	riskWeightNoBudgetAlert   = 30
+)
+
+// noBudgetFactor is the risk factor recorded when no budget alert covers the project
+const noBudgetFactor = "no budget alert on the project"
+
+// highRiskScore is the score at which an API is reported as high risk
+const highRiskScore = 70
+
+// apiKey is the subset of an API Keys API key used for risk scoring
+type apiKey struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	Restrictions *struct {
+		APITargets []struct {
+			Service string `json:"service"`
+		} `json:"apiTargets"`
+		BrowserKeyRestrictions *json.RawMessage `json:"browserKeyRestrictions"`
+		ServerKeyRestrictions  *json.RawMessage `json:"serverKeyRestrictions"`
+		AndroidKeyRestrictions *json.RawMessage `json:"androidKeyRestrictions"`
+		IOSKeyRestrictions     *json.RawMessage `json:"iosKeyRestrictions"`
+	} `json:"restrictions"`
+}
+
+// unrestrictedFor reports whether the key can call the service from any application
+func (k apiKey) unrestrictedFor(service string) bool {
+	r := k.Restrictions
+	if r == nil {
+		return true
+	}
+	if r.BrowserKeyRestrictions != nil || r.ServerKeyRestrictions != nil ||
+		r.AndroidKeyRestrictions != nil || r.IOSKeyRestrictions != nil {
+		return false
+	}
+	if len(r.APITargets) == 0 {
+		return true
+	}
+	for _, target := range r.APITargets {
+		if target.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// annotateRiskScores scores enabled Maps Platform results from API key restrictions and budget alerts
+func (c *GoogleAPIChecker) annotateRiskScores(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("risk scoring requires a project ID")
+	}
+
+	// Lookups that fail leave that factor unscored rather than failing the scan
+	var problems []string
+	keys, err := c.getAPIKeys()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	hasBudget, err := c.budgetAlert()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	for i := range results {
+		result := &results[i]
+		if !result.Enabled || getAPICategory(result.Name) != riskScoredCategory {
+			continue
+		}
+
+		score := riskWeightEnabled
+		factors := []string{"API enabled"}
+
+		if keys != nil {
+			var unrestricted []string
+			for _, key := range keys {
+				if key.unrestrictedFor(result.Name) {
+					unrestricted = append(unrestricted, key.DisplayName)
+					result.UnrestrictedKeys = append(result.UnrestrictedKeys, key.Name)
+				}
+			}
+			if len(unrestricted) > 0 {
+				score += riskWeightUnrestrictedKey
+				factors = append(factors, fmt.Sprintf("%d unrestricted API key(s): %s", len(unrestricted), strings.Join(unrestricted, ", ")))
+			}
+		}
+
+		if hasBudget != nil && !*hasBudget {
+			score += riskWeightNoBudgetAlert
+			factors = append(factors, noBudgetFactor)
+		}
+
+		result.RiskScore = &score
+		result.RiskFactors = factors
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("risk scores are partial: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// getAPIKeys lists the project's API keys; it returns nil and an error when they cannot be read
+func (c *GoogleAPIChecker) getAPIKeys() ([]apiKey, error) {
+	keys := []apiKey{}
+	pageToken := ""
+	for {
+		requestURL := fmt.Sprintf("https://apikeys.googleapis.com/v2/projects/%s/locations/global/keys", c.projectID)
+		if pageToken != "" {
+			requestURL += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		req, err := http.NewRequest("GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Add("X-Goog-Api-Key", c.token)
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list API keys: %v", err)
+		}
+
+		var result struct {
+			Keys          []apiKey `json:"keys"`
+			NextPageToken string   `json:"nextPageToken"`
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list API keys, status: %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API keys response: %v", err)
+		}
+
+		keys = append(keys, result.Keys...)
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return keys, nil
+}
+
+// hasBudgetAlert reports whether a budget with alert thresholds covers the project
+func (c *GoogleAPIChecker) hasBudgetAlert() (*bool, error) {
+	project, err := c.getProject()
+	if err != nil {
+		return nil, err
+	}
+
+	billingAccount, err := c.getBillingAccount()
+	if err != nil {
+		return nil, err
+	}
+	if billingAccount == "" {
+		// Without billing there is nothing to run up
+		covered := true
+		return &covered, nil
+	}
+
+	requestURL := fmt.Sprintf("https://billingbudgets.googleapis.com/v1/%s/budgets", billingAccount)
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Add("X-Goog-Api-Key", c.token)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to list budgets, status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Budgets []struct {
+			BudgetFilter struct {
+				Projects []string `json:"projects"`
+			} `json:"budgetFilter"`
+			ThresholdRules []json.RawMessage `json:"thresholdRules"`
+		} `json:"budgets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse budgets response: %v", err)
+	}
+
+	covered := false
+	for _, budget := range result.Budgets {
+		if len(budget.ThresholdRules) == 0 {
+			continue
+		}
+		// A budget without a project filter covers the whole billing account
+		if len(budget.BudgetFilter.Projects) == 0 {
+			covered = true
+			break
+		}
+		for _, p := range budget.BudgetFilter.Projects {
+			if p == "projects/"+project.ProjectNumber {
+				covered = true
+			}
+		}
+	}
+
+	return &covered, nil
+}
+
+// riskLevel names the band a risk score falls in
+func riskLevel(score int) string {
+	switch {
+	case score >= highRiskScore:
+		return "HIGH"
+	case score >= riskWeightEnabled+riskWeightNoBudgetAlert:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
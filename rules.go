@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+)
+
+// Rule scopes
+const (
+	ruleScopeAPI    = "api"    // Evaluated for every checked API
+	ruleScopeReport = "report" // Evaluated once per report
+)
+
+// RecommendationsConfig adds recommendation rules to the report
+type RecommendationsConfig struct {
+	// Defaults set to false drops the built-in general recommendations
+	Defaults *bool `yaml:"defaults"`
+	// Rules are evaluated in order after the built-in findings
+	Rules []RecommendationRule `yaml:"rules"`
+}
+
+// RecommendationRule adds Message to the recommendations when the When condition holds,
+// e.g. when: cost > 100 && category == "AI", message: "{display_name}: get approval from the ML platform team"
+type RecommendationRule struct {
+	When    string `yaml:"when"`
+	Message string `yaml:"message"`
+	// Scope is "api" (default) to check every API or "report" to check the report totals once
+	Scope string `yaml:"scope"`
+}
+
+// defaultRecommendationRules are the built-in general recommendations
+var defaultRecommendationRules = []RecommendationRule{
+	{Scope: ruleScopeReport, When: "total_cost > total_cost_threshold", Message: "💸 Total estimated monthly cost is high: ${total_cost:%.2f}. Consider reviewing usage patterns."},
+	{Scope: ruleScopeAPI, When: `status == "DEPRECATED" && enabled`, Message: "⊘ {display_name} is deprecated but still enabled. Plan a migration before it is shut down."},
+	{Scope: ruleScopeReport, When: "disabled_count > 0", Message: "🔒 {disabled_count} APIs are currently disabled. Review if any are needed for your application."},
+	{Scope: ruleScopeReport, When: "true", Message: "📊 Set up billing alerts and budget limits in Google Cloud Console"},
+	{Scope: ruleScopeReport, When: "true", Message: "🔍 Regularly monitor API usage and costs"},
+	{Scope: ruleScopeReport, When: "true", Message: "⚡ Consider using quotas and rate limiting for high-cost APIs"},
+}
+
+// apiRuleVariables are the names API rules can use
+var apiRuleVariables = map[string]bool{
+	"name": true, "display_name": true, "project": true, "environment": true, "status": true,
+	"enabled": true, "category": true, "cost": true, "unlimited_cost": true, "has_pricing": true,
+	"actual_cost": true, "risk_score": true, "request_count_90d": true, "resource_count": true,
+	"duration_ms": true, "throttled": true,
+}
+
+// reportRuleVariables are the names report rules can use
+var reportRuleVariables = map[string]bool{
+	"total_apis": true, "enabled_count": true, "disabled_count": true, "error_count": true, "unknown_count": true,
+	"total_cost": true, "actual_cost": true, "total_cost_threshold": true, "high_cost_threshold": true,
+	"unlimited_count": true, "high_cost_count": true, "unused_count": true, "empty_count": true,
+	"high_risk_count": true, "incident_count": true, "violation_count": true,
+	"throttled_count": true, "p95_ms": true, "potential_savings": true,
+}
+
+// templatePlaceholder matches {name} and {name:%.2f} in rule messages
+var templatePlaceholder = regexp.MustCompile(`\{([a-z_0-9]+)(?::(%[^}]+))?\}`)
+
+// compiledRule is a recommendation rule ready to evaluate
+type compiledRule struct {
+	when    *expr
+	message string
+	scope   string
+}
+
+// compileRecommendationRules checks and compiles the configured rules, after the defaults unless disabled
+func compileRecommendationRules(cfg RecommendationsConfig) ([]*compiledRule, error) {
+	var rules []RecommendationRule
+	if cfg.Defaults == nil || *cfg.Defaults {
+		rules = append(rules, defaultRecommendationRules...)
+	}
+	rules = append(rules, cfg.Rules...)
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		scope := rule.Scope
+		if scope == "" {
+			scope = ruleScopeAPI
+		}
+		known := apiRuleVariables
+		switch scope {
+		case ruleScopeAPI:
+		case ruleScopeReport:
+			known = reportRuleVariables
+		default:
+			return nil, fmt.Errorf("recommendation rule %d: unknown scope %q (use api or report)", i+1, rule.Scope)
+		}
+
+		if rule.When == "" || rule.Message == "" {
+			return nil, fmt.Errorf("recommendation rule %d: when and message are required", i+1)
+		}
+		when, err := compileExpr(rule.When, known)
+		if err != nil {
+			return nil, fmt.Errorf("recommendation rule %d: %v", i+1, err)
+		}
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(rule.Message, -1) {
+			if !known[match[1]] {
+				return nil, fmt.Errorf("recommendation rule %d: unknown variable {%s} in message", i+1, match[1])
+			}
+		}
+
+		compiled = append(compiled, &compiledRule{when: when, message: rule.Message, scope: scope})
+	}
+	return compiled, nil
+}
+
+// evaluateRules returns the messages of the rules that match the report or its APIs
+func evaluateRules(rules []*compiledRule, report *Report) []string {
+	var messages []string
+	reportVars := reportRuleVars(report)
+	for _, rule := range rules {
+		if rule.scope == ruleScopeReport {
+			if rule.matches(reportVars) {
+				messages = append(messages, renderRuleMessage(rule.message, reportVars))
+			}
+			continue
+		}
+
+		for _, apis := range [][]APIResult{report.EnabledAPIs, report.DisabledAPIs, report.UnknownAPIs} {
+			for _, api := range apis {
+				vars := apiRuleVars(api)
+				if rule.matches(vars) {
+					messages = append(messages, renderRuleMessage(rule.message, vars))
+				}
+			}
+		}
+	}
+	return messages
+}
+
+// matches evaluates the condition, treating evaluation errors as no match
+func (r *compiledRule) matches(vars map[string]interface{}) bool {
+	ok, err := r.when.EvalBool(vars)
+	if err != nil {
+		log.Printf("Warning: recommendation rule %q: %v", r.when.source, err)
+		return false
+	}
+	return ok
+}
+
+// apiRuleVars returns the variables API rules see for one result
+func apiRuleVars(api APIResult) map[string]interface{} {
+	vars := map[string]interface{}{
+		"name":              api.Name,
+		"display_name":      api.DisplayName,
+		"project":           api.ProjectID,
+		"environment":       api.Environment,
+		"status":            api.Status,
+		"enabled":           api.Enabled,
+		"category":          getAPICategory(api.Name),
+		"cost":              api.CostInfo.EstimatedCost,
+		"unlimited_cost":    api.CostInfo.UnlimitedCost,
+		"has_pricing":       api.CostInfo.HasPricing,
+		"actual_cost":       nil,
+		"risk_score":        nil,
+		"request_count_90d": nil,
+		"resource_count":    nil,
+		"duration_ms":       float64(api.DurationMs),
+		"throttled":         api.Throttled,
+	}
+	if api.CostInfo.ActualCost != nil {
+		vars["actual_cost"] = *api.CostInfo.ActualCost
+	}
+	if api.RiskScore != nil {
+		vars["risk_score"] = float64(*api.RiskScore)
+	}
+	if api.RequestCount90d != nil {
+		vars["request_count_90d"] = float64(*api.RequestCount90d)
+	}
+	if api.ResourceCount != nil {
+		vars["resource_count"] = float64(*api.ResourceCount)
+	}
+	return vars
+}
+
+// reportRuleVars returns the variables report rules see
+func reportRuleVars(report *Report) map[string]interface{} {
+	vars := map[string]interface{}{
+		"total_apis":           float64(report.Summary.TotalAPIs),
+		"enabled_count":        float64(report.Summary.EnabledCount),
+		"disabled_count":       float64(report.Summary.DisabledCount),
+		"error_count":          float64(report.Summary.ErrorCount),
+		"unknown_count":        float64(report.Summary.UnknownCount),
+		"total_cost":           report.Summary.TotalCost,
+		"actual_cost":          nil,
+		"total_cost_threshold": report.CostAnalysis.TotalCostThreshold,
+		"high_cost_threshold":  report.CostAnalysis.HighCostThreshold,
+		"unlimited_count":      float64(len(report.CostAnalysis.UnlimitedCostAPIs)),
+		"high_cost_count":      float64(len(report.CostAnalysis.HighCostAPIs)),
+		"unused_count":         float64(len(report.UnusedAPIs)),
+		"empty_count":          float64(len(report.EmptyAPIs)),
+		"high_risk_count":      float64(len(report.HighRiskAPIs)),
+		"incident_count":       float64(len(report.IncidentAPIs)),
+		"violation_count":      float64(len(report.PolicyViolations)),
+		"throttled_count":      0.0,
+		"p95_ms":               nil,
+		"potential_savings":    report.Summary.PotentialSavings,
+	}
+	if report.Performance != nil {
+		vars["throttled_count"] = float64(report.Performance.Throttled)
+		vars["p95_ms"] = float64(report.Performance.P95Ms)
+	}
+	if report.Summary.ActualCost != nil {
+		vars["actual_cost"] = *report.Summary.ActualCost
+	}
+	return vars
+}
+
+// renderRuleMessage fills {name} and {name:%.2f} placeholders from the variables
+func renderRuleMessage(message string, vars map[string]interface{}) string {
+	return templatePlaceholder.ReplaceAllStringFunc(message, func(placeholder string) string {
+		match := templatePlaceholder.FindStringSubmatch(placeholder)
+		value := vars[match[1]]
+		if match[2] != "" && value != nil {
+			return fmt.Sprintf(match[2], value)
+		}
+		switch v := value.(type) {
+		case nil:
+			return "n/a"
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	})
+}
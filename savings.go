@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Savings actions
+const (
+	savingsDisable = "disable" // Disable an API nothing uses
+	savingsQuota   = "quota"   // Cap an expensive API with a quota at the high-cost threshold
+)
+
+// SavingsItem is the estimated monthly saving of acting on one recommendation
+type SavingsItem struct {
+	ProjectID   string  `json:"project_id,omitempty"`
+	Name        string  `json:"name"`
+	DisplayName string  `json:"display_name"`
+	Action      string  `json:"action"`
+	Monthly     float64 `json:"monthly"`
+}
+
+// SavingsEstimate is what the disable and quota recommendations would save per month, from the cost model
+type SavingsEstimate struct {
+	Total float64       `json:"total"`
+	Items []SavingsItem `json:"items"`
+}
+
+// estimateSavings prices the disable and quota recommendations. Disabling an API saves its whole
+// estimated cost; a quota saves what is estimated above the environment's high-cost threshold.
+// An API is only counted once, for disabling when it is a candidate for both
+func estimateSavings(report *Report, policy *Policy) SavingsEstimate {
+	estimate := SavingsEstimate{}
+	counted := make(map[string]bool)
+	add := func(api APIResult, action string, monthly float64) {
+		key := resultKey(api.ProjectID, api.Name)
+		if counted[key] || monthly <= 0 {
+			return
+		}
+		counted[key] = true
+		estimate.Items = append(estimate.Items, SavingsItem{
+			ProjectID:   api.ProjectID,
+			Name:        api.Name,
+			DisplayName: api.DisplayName,
+			Action:      action,
+			Monthly:     monthly,
+		})
+		estimate.Total += monthly
+	}
+
+	for _, apis := range [][]APIResult{report.EmptyAPIs, report.UnusedAPIs} {
+		for _, api := range apis {
+			add(api, savingsDisable, api.CostInfo.EstimatedCost)
+		}
+	}
+	for _, apis := range [][]APIResult{report.CostAnalysis.UnlimitedCostAPIs, report.CostAnalysis.HighCostAPIs} {
+		for _, api := range apis {
+			add(api, savingsQuota, quotaSavings(api, policy))
+		}
+	}
+
+	sort.SliceStable(estimate.Items, func(i, j int) bool {
+		return estimate.Items[i].Monthly > estimate.Items[j].Monthly
+	})
+	return estimate
+}
+
+// quotaSavings is the estimated cost above the high-cost threshold of the API's environment
+func quotaSavings(api APIResult, policy *Policy) float64 {
+	return max(0, api.CostInfo.EstimatedCost-policy.ThresholdsFor(api.Environment).HighCost)
+}
+
+// savingsFor returns the saving estimated for an API, 0 when there is none
+func (s SavingsEstimate) savingsFor(api APIResult) float64 {
+	for _, item := range s.Items {
+		if item.ProjectID == api.ProjectID && item.Name == api.Name {
+			return item.Monthly
+		}
+	}
+	return 0
+}
+
+// savingsNote is appended to a recommendation line, empty when nothing is saved
+func savingsNote(monthly float64) string {
+	if monthly <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (saves ~%s)", formatCost(monthly))
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out requests so that a shared budget of queries per second is respected
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter creates a limiter for the given queries per second; zero or less means unlimited
+func NewRateLimiter(qps float64) *RateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / qps)}
+}
+
+// Wait blocks until the next request may be sent
+func (l *RateLimiter) Wait() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+// ProjectScanError records a project whose scan failed
+type ProjectScanError struct {
+	ProjectID string
+	Err       error
+}
+
+func (e *ProjectScanError) Error() string {
+	return fmt.Sprintf("project %q: %v", e.ProjectID, e.Err)
+}
+
+// ScanOutput is everything collected by a multi-project scan, in project order
+type ScanOutput struct {
+	Results  []APIResult
+	Findings []Finding
+	Contacts []ProjectContacts
+	// Overhead is the quota the scan consumed, nil when it sent no requests
+	Overhead *ScanOverhead
+}
+
+// ScanProjects scans several projects, running up to parallel projects at once.
+// Each project gets its own worker pool of threads workers; all projects share
+// the rate limiter in options so the combined scan stays within org-level quotas.
+// The output is returned along with any per-project failures; a cancelled scan returns the
+// results checked before it stopped.
+func ScanProjects(token string, projects []string, threads, parallel int, options CheckerOptions) (*ScanOutput, error) {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > len(projects) {
+		parallel = len(projects)
+	}
+
+	// Concurrent projects would fight over a single animated bar
+	if parallel > 1 {
+		options.ProgressLines = true
+	}
+
+	projectResults := make([][]APIResult, len(projects))
+	projectFindings := make([][]Finding, len(projects))
+	projectContacts := make([]*ProjectContacts, len(projects))
+	projectErrors := make([]error, len(projects))
+	projectCalls := make([]*callCounter, len(projects))
+
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, projectID := range projects {
+		wg.Add(1)
+		go func(i int, projectID string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			checker := NewGoogleAPIChecker(token, projectID, threads, options)
+			projectCalls[i] = &checker.calls
+			results, err := checker.CheckAllAPIs()
+			// A cancelled project keeps the results checked before it stopped
+			projectResults[i] = results
+			if err != nil {
+				projectErrors[i] = &ProjectScanError{ProjectID: projectID, Err: err}
+				return
+			}
+			projectFindings[i] = checker.Findings()
+			projectContacts[i] = checker.Contacts()
+		}(i, projectID)
+	}
+	wg.Wait()
+
+	output := &ScanOutput{}
+	for i, results := range projectResults {
+		output.Results = append(output.Results, results...)
+		output.Findings = append(output.Findings, projectFindings[i]...)
+		if projectContacts[i] != nil {
+			output.Contacts = append(output.Contacts, *projectContacts[i])
+		}
+	}
+
+	output.Overhead = mergeOverhead(projectCalls...)
+
+	return output, errors.Join(projectErrors...)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// outputSchemas maps each output file kind to the Go value it is encoded from
+var outputSchemas = map[string]struct {
+	Title string
+	Value interface{}
+}{
+	"results": {"Google API Checker results", ResultsFile{}},
+	"report":  {"Google API Checker report", Report{}},
+}
+
+// GenerateSchema builds a JSON Schema document for the named output kind
+func GenerateSchema(kind string) (map[string]interface{}, error) {
+	output, ok := outputSchemas[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema: %s (available: results, report)", kind)
+	}
+
+	schema := schemaForType(reflect.TypeOf(output.Value))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = fmt.Sprintf("https://github.com/r1z4x/GoogleAPIChecker/schemas/%s.schema.json", kind)
+	schema["title"] = output.Title
+	return schema, nil
+}
+
+// schemaForType converts a Go type into a JSON Schema fragment following encoding/json rules
+func schemaForType(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes nil slices as null
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": schemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaForType(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := field.Name
+			omitEmpty := false
+			if tag := field.Tag.Get("json"); tag != "" {
+				parts := strings.Split(tag, ",")
+				if parts[0] == "-" {
+					continue
+				}
+				if parts[0] != "" {
+					name = parts[0]
+				}
+				for _, option := range parts[1:] {
+					if option == "omitempty" {
+						omitEmpty = true
+					}
+				}
+			}
+
+			properties[name] = schemaForType(field.Type)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// newSchemaCmd creates the subcommand that prints or writes the output JSON Schemas
+func newSchemaCmd() *cobra.Command {
+	var outDir string
+
+	cmd := &cobra.Command{
+		Use:   "schema [results|report]",
+		Short: "Print JSON Schemas for the results and report files",
+		Long: `Print the JSON Schema describing results.json or the report JSON, generated from
+the Go structs so the schema always matches the tool version. With --out-dir
+both schemas are written as <kind>.schema.json files.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"results", "report"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outDir != "" {
+				for kind := range outputSchemas {
+					if err := writeSchemaFile(kind, filepath.Join(outDir, kind+".schema.json")); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			kind := "results"
+			if len(args) == 1 {
+				kind = args[0]
+			}
+
+			schema, err := GenerateSchema(kind)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(schema)
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out-dir", "", "Write all schemas into this directory instead of printing")
+	return cmd
+}
+
+// writeSchemaFile writes the schema for kind to filename
+func writeSchemaFile(kind, filename string) error {
+	schema, err := GenerateSchema(kind)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create schema file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return fmt.Errorf("failed to encode schema: %v", err)
+	}
+
+	fmt.Printf("✅ Schema written to: %s\n", filename)
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import "fmt"
+
+// Score weights for the overall project score; they add up to 100
+const (
+	scoreWeightSecurity    = 40
+	scoreWeightCostHygiene = 40
+	scoreWeightReliability = 20
+)
+
+// Points deducted from a 100-point category per finding
+const (
+	penaltyHighRiskScore   = 15
+	penaltyMediumRiskScore = 5
+	penaltyAbuseTarget     = 5
+	penaltyPolicyViolation = 10
+	penaltyUnlimitedCost   = 20
+	penaltyHighCost        = 10
+	penaltyUnusedAPI       = 5
+)
+
+// ProjectScore is the 0-100 score and letter grade for one project in a scan
+type ProjectScore struct {
+	ProjectID   string `json:"project_id,omitempty"`
+	Score       int    `json:"score"`
+	Grade       string `json:"grade"`
+	Security    int    `json:"security"`
+	CostHygiene int    `json:"cost_hygiene"`
+	Reliability int    `json:"reliability"`
+}
+
+// String formats the score for console and text output
+func (s ProjectScore) String() string {
+	return fmt.Sprintf("%d/100 (%s) - security %d, cost hygiene %d, reliability %d",
+		s.Score, s.Grade, s.Security, s.CostHygiene, s.Reliability)
+}
+
+// Label names the project a score belongs to
+func (s ProjectScore) Label() string {
+	if s.ProjectID == "" {
+		return "Scan"
+	}
+	return s.ProjectID
+}
+
+// computeScores grades each project from the report's findings and the error rate of its results
+func computeScores(report *Report, results []APIResult) []ProjectScore {
+	type tally struct {
+		total, errors  int
+		security, cost int
+	}
+
+	var order []string
+	tallies := make(map[string]*tally)
+	get := func(projectID string) *tally {
+		t, ok := tallies[projectID]
+		if !ok {
+			t = &tally{}
+			tallies[projectID] = t
+			order = append(order, projectID)
+		}
+		return t
+	}
+
+	for _, result := range results {
+		t := get(result.ProjectID)
+		t.total++
+		if result.Error != "" {
+			t.errors++
+		}
+	}
+
+	for _, api := range report.RiskScoredAPIs {
+		switch riskLevel(*api.RiskScore) {
+		case "HIGH":
+			get(api.ProjectID).security += penaltyHighRiskScore
+		case "MEDIUM":
+			get(api.ProjectID).security += penaltyMediumRiskScore
+		}
+	}
+	for _, api := range report.HighRiskAPIs {
+		get(api.ProjectID).security += penaltyAbuseTarget
+	}
+	for _, violation := range report.PolicyViolations {
+		if violation.Rule == "total_cost" {
+			get(violation.ProjectID).cost += penaltyPolicyViolation
+		} else {
+			get(violation.ProjectID).security += penaltyPolicyViolation
+		}
+	}
+	for _, api := range report.CostAnalysis.UnlimitedCostAPIs {
+		get(api.ProjectID).cost += penaltyUnlimitedCost
+	}
+	for _, api := range report.CostAnalysis.HighCostAPIs {
+		get(api.ProjectID).cost += penaltyHighCost
+	}
+	for _, api := range report.UnusedAPIs {
+		get(api.ProjectID).cost += penaltyUnusedAPI
+	}
+
+	scores := make([]ProjectScore, 0, len(order))
+	for _, projectID := range order {
+		t := tallies[projectID]
+		reliability := 100
+		if t.total > 0 {
+			reliability = 100 - t.errors*100/t.total
+		}
+
+		score := ProjectScore{
+			ProjectID:   projectID,
+			Security:    clampScore(100 - t.security),
+			CostHygiene: clampScore(100 - t.cost),
+			Reliability: reliability,
+		}
+		score.Score = (score.Security*scoreWeightSecurity +
+			score.CostHygiene*scoreWeightCostHygiene +
+			score.Reliability*scoreWeightReliability) / 100
+		score.Grade = letterGrade(score.Score)
+		scores = append(scores, score)
+	}
+
+	return scores
+}
+
+// clampScore keeps a category score within 0-100
+func clampScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+// letterGrade maps a 0-100 score to a letter grade
+func letterGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
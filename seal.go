@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted bundles and encrypted state share one format: a magic line, the scrypt salt, the GCM
+// nonce and the data sealed with AES-256-GCM under a key derived from a passphrase and the salt
+const (
+	sealSaltSize  = 16
+	sealKeySize   = 32 // AES-256
+	sealNonceSize = 12 // the GCM nonce cipher.NewGCM uses
+)
+
+// sealCipher derives the AES-GCM cipher for a passphrase and salt
+func sealCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, sealKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// newSealSalt returns a random salt for sealCipher
+func newSealSalt() ([]byte, error) {
+	salt := make([]byte, sealSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+	return salt, nil
+}
+
+// sealData encrypts data with the cipher derived from salt and frames it behind magic;
+// additional is authenticated but not stored
+func sealData(gcm cipher.AEAD, magic string, salt, data, additional []byte) ([]byte, error) {
+	nonce := make([]byte, sealNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := append([]byte(magic), salt...)
+	sealed = append(sealed, nonce...)
+	return gcm.Seal(sealed, nonce, data, additional), nil
+}
+
+// isSealed reports whether data starts with the magic of a sealed format
+func isSealed(data []byte, magic string) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// sealedSalt returns the salt of data sealed by sealData, to derive its cipher; ok is false when
+// data is not sealed behind magic or is truncated
+func sealedSalt(data []byte, magic string) (salt []byte, ok bool) {
+	if !isSealed(data, magic) || len(data) < len(magic)+sealSaltSize+sealNonceSize {
+		return nil, false
+	}
+	return data[len(magic) : len(magic)+sealSaltSize], true
+}
+
+// openSealed decrypts data sealed by sealData with the cipher derived from its salt
+func openSealed(gcm cipher.AEAD, magic string, data, additional []byte) ([]byte, error) {
+	if _, ok := sealedSalt(data, magic); !ok {
+		return nil, fmt.Errorf("sealed data is truncated")
+	}
+	data = data[len(magic)+sealSaltSize:]
+	return gcm.Open(nil, data[:sealNonceSize], data[sealNonceSize:], additional)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBundleEncryption(t *testing.T) {
+	sealed, err := encryptBundle([]byte("zip data"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := decryptBundle(sealed, "passphrase"); err != nil || string(plain) != "zip data" {
+		t.Fatalf("got %q, %v", plain, err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       string
+	}{
+		{"wrong passphrase", sealed, "other", "wrong passphrase"},
+		{"plain zip", []byte("PK\x03\x04"), "passphrase", "not an encrypted bundle"},
+		{"truncated", sealed[:len(bundleMagic)+sealSaltSize+sealNonceSize-1], "passphrase", "truncated"},
+		{"tampered", append(sealed[:len(sealed)-1:len(sealed)-1], sealed[len(sealed)-1]^1), "passphrase", "corrupted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decryptBundle(test.data, test.passphrase)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %v, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestStateEncryption(t *testing.T) {
+	stateSecretCache.secret = "state passphrase"
+	defer func() { stateSecretCache.secret = "" }()
+
+	local := &localStateStore{dir: t.TempDir()}
+	store := newEncryptedStateStore(local)
+	store.encrypt = true
+	if err := store.Save("a.json", []byte("state")); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := local.Load("a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSealed(raw, stateMagic) {
+		t.Fatalf("saved state is not sealed: %q", raw)
+	}
+	if plain, err := store.Load("a.json"); err != nil || string(plain) != "state" {
+		t.Fatalf("got %q, %v", plain, err)
+	}
+
+	// The object name is authenticated, so a sealed object copied to another name does not open
+	if err := local.Save("b.json", raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("b.json"); err == nil || !strings.Contains(err.Error(), "wrong state key") {
+		t.Errorf("renamed object: error %v", err)
+	}
+
+	// State saved before encryption was turned on still loads
+	if err := local.Save("plain.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := store.Load("plain.json"); err != nil || string(plain) != "{}" {
+		t.Errorf("plain state: got %q, %v", plain, err)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenEnvVar is read when neither --token nor --token-from is given
+const tokenEnvVar = "GOOGLE_API_CHECKER_TOKEN"
+
+// redactedPlaceholder replaces secrets in any output
+const redactedPlaceholder = "[REDACTED]"
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// registerSecret marks a value that must never appear in logs, errors or output files
+func registerSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < 4 {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	// Tokens are registered again each time they are read, e.g. by a long-running server
+	for _, registered := range secrets {
+		if registered == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// redactSecrets replaces every registered secret in s
+func redactSecrets(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	return s
+}
+
+// redactingWriter redacts secrets from everything written through it
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, redactSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// resolveToken returns the API token from --token, --token-from or the environment
+func resolveToken(token, tokenFrom string) (string, error) {
+	if token != "" && tokenFrom != "" {
+		return "", fmt.Errorf("use either --token or --token-from, not both")
+	}
+	if token != "" {
+		return token, nil
+	}
+	if tokenFrom == "" {
+		return os.Getenv(tokenEnvVar), nil
+	}
+
+	source, ref, ok := strings.Cut(tokenFrom, ":")
+	if !ok || ref == "" {
+		return "", fmt.Errorf("invalid --token-from %q (use env:VAR, file:path or secretmanager:projects/P/secrets/S)", tokenFrom)
+	}
+
+	switch source {
+	case "env":
+		value := os.Getenv(ref)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is empty", ref)
+		}
+		return strings.TrimSpace(value), nil
+	case "file":
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "secretmanager":
+		return accessSecretVersion(ref)
+	default:
+		return "", fmt.Errorf("unsupported token source %q (use env, file or secretmanager)", source)
+	}
+}
+
+// accessSecretVersion reads a secret payload from Google Secret Manager.
+// name is projects/P/secrets/S, optionally followed by /versions/V (defaults to latest).
+func accessSecretVersion(name string) (string, error) {
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	accessToken, err := gcloudAccessToken()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", endpoints.resolve(fmt.Sprintf("https://secretmanager.googleapis.com/v1/%s:access", name)), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: apiTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("failed to access secret %s, status: %d", name, resp.StatusCode)
+	}
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse secret response: %v", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret payload: %v", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Access tokens are reused until shortly before they expire; gcloud does not report the expiry of
+// the tokens it prints, which are valid for an hour
+const (
+	gcloudTokenLifetime = 50 * time.Minute
+	tokenExpiryMargin   = time.Minute
+)
+
+var (
+	cachedTokenMu      sync.Mutex
+	cachedToken        string
+	cachedTokenExpires time.Time
+)
+
+// gcloudAccessToken returns an OAuth access token from GOOGLE_OAUTH_ACCESS_TOKEN, the gcloud CLI
+// or, when gcloud is not installed, the GCE/GKE metadata server. Tokens from gcloud and the
+// metadata server are cached until they expire.
+func gcloudAccessToken() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		registerSecret(token)
+		return token, nil
+	}
+
+	cachedTokenMu.Lock()
+	defer cachedTokenMu.Unlock()
+	if cachedToken != "" && time.Now().Before(cachedTokenExpires) {
+		return cachedToken, nil
+	}
+
+	var token string
+	var expires time.Time
+	// Without gcloud, e.g. in a Kubernetes CronJob, use the workload's service account
+	if _, err := exec.LookPath("gcloud"); err != nil {
+		if token, expires, err = metadataAccessToken(); err != nil {
+			return "", err
+		}
+	} else {
+		output, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to get a Google access token (set GOOGLE_OAUTH_ACCESS_TOKEN or run gcloud auth login): %v", err)
+		}
+		token, expires = strings.TrimSpace(string(output)), time.Now().Add(gcloudTokenLifetime)
+	}
+
+	registerSecret(token)
+	cachedToken, cachedTokenExpires = token, expires
+	return token, nil
+}
+
+// metadataAccessToken returns an access token for the default service account from the metadata
+// server and when it is to be refreshed
+func metadataAccessToken() (string, time.Time, error) {
+	req, err := http.NewRequest("GET", "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Metadata-Flavor", "Google")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get a Google access token (set GOOGLE_OAUTH_ACCESS_TOKEN, install gcloud or run on GCE/GKE): %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", time.Time{}, fmt.Errorf("failed to get an access token from the metadata server, status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse metadata server token: %v", err)
+	}
+
+	return result.AccessToken, time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin), nil
+}
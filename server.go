@@ -0,0 +1,854 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// serverTokenEnvVar holds the bearer token clients must send when --auth-token-from is not given
+const serverTokenEnvVar = "GOOGLE_API_CHECKER_SERVER_TOKEN"
+
+// Server limits
+const (
+	maxStoredScans   = 100              // Finished scans kept in memory for GET requests
+	maxLongPoll      = 60 * time.Second // Longest ?wait= accepted on GET /api/v1/scans/{id}
+	defaultMaxQueued = 100              // Scans waiting for a worker before new ones are refused
+	streamTokenLife  = time.Hour        // How long the dashboard's link to a scan's events opens it
+)
+
+// Scan states
+const (
+	scanQueued    = "queued"
+	scanRunning   = "running"
+	scanDone      = "done"
+	scanFailed    = "failed"
+	scanCancelled = "cancelled"
+)
+
+// Errors starting or cancelling a scan
+var (
+	errQueueFull    = errors.New("too many queued scans, try again later")
+	errTenantLimit  = errors.New("too many queued or running scans for this tenant, try again later")
+	errScanFinished = errors.New("scan has already finished")
+)
+
+// ScanRequest is the body of POST /api/v1/scans
+type ScanRequest struct {
+	Projects       []string          `json:"projects"`
+	Profile        string            `json:"profile,omitempty"`
+	SkipCost       bool              `json:"skip_cost,omitempty"`
+	AuditLogs      bool              `json:"audit_logs,omitempty"`
+	UsageMetrics   bool              `json:"usage_metrics,omitempty"`
+	PublishMetrics bool              `json:"publish_metrics,omitempty"`
+	RiskScore      bool              `json:"risk_score,omitempty"`
+	BillingCheck   bool              `json:"billing_check,omitempty"`
+	Reconcile      bool              `json:"reconcile,omitempty"`
+	Incidents      bool              `json:"incidents,omitempty"`
+	Contacts       bool              `json:"contacts,omitempty"`
+	AssetInventory bool              `json:"asset_inventory,omitempty"`
+	Checks         []string          `json:"checks,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// validate rejects unknown profiles and checks and invalid tags
+func (request ScanRequest) validate() error {
+	if request.Profile != "" {
+		if _, err := LookupProfile(request.Profile); err != nil {
+			return err
+		}
+	}
+	if err := validateTags(request.Tags); err != nil {
+		return err
+	}
+	return applyChecks(&CheckerOptions{}, request.Checks)
+}
+
+// apply returns the base checker options with the scan settings of a validated request
+func (request ScanRequest) apply(options CheckerOptions) CheckerOptions {
+	options.SkipCost = options.SkipCost || request.SkipCost
+	options.AuditLogs = request.AuditLogs
+	options.UsageMetrics = request.UsageMetrics
+	options.PublishMetrics = request.PublishMetrics
+	options.RiskScoring = request.RiskScore
+	options.BillingCheck = request.BillingCheck
+	options.Reconcile = request.Reconcile
+	options.Incidents = request.Incidents
+	options.Contacts = request.Contacts
+	options.AssetCounts = request.AssetInventory
+	applyChecks(&options, request.Checks)
+	if request.Profile != "" {
+		options.Profile, _ = LookupProfile(request.Profile)
+	}
+	return options
+}
+
+// ScanProgress is how far a project's scan has got
+type ScanProgress struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`
+}
+
+// ScanStatus is the state of a remote scan as returned by the API
+type ScanStatus struct {
+	ID         string         `json:"id"`
+	Tenant     string         `json:"tenant,omitempty"`
+	Status     string         `json:"status"`
+	Request    ScanRequest    `json:"request"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Progress   []ScanProgress `json:"progress,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Report     *Report        `json:"report,omitempty"`
+	// Results are the API results checked so far, replaced by the complete results when the scan finishes
+	Results []APIResult `json:"-"`
+}
+
+// remoteScan tracks one scan; updated is closed and replaced whenever the scan changes
+type remoteScan struct {
+	tenant *serverTenant
+
+	mu      sync.Mutex
+	status  ScanStatus
+	updated chan struct{}
+	// cancel stops the scan's checks once it runs
+	cancel context.CancelFunc
+	// streamTokens open the scan's event stream for the dashboard, by expiry
+	streamTokens map[string]time.Time
+}
+
+// issueStreamToken returns a random token that opens only the scan's event stream until it expires,
+// so the dashboard does not have to put the client's own credential in the page
+func (s *remoteScan) issueStreamToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create stream token: %v", err)
+	}
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for issued, expires := range s.streamTokens {
+		if now.After(expires) {
+			delete(s.streamTokens, issued)
+		}
+	}
+	if s.streamTokens == nil {
+		s.streamTokens = make(map[string]time.Time)
+	}
+	s.streamTokens[token] = now.Add(streamTokenLife)
+	return token, nil
+}
+
+// validStreamToken reports whether the token was issued for the scan and has not expired
+func (s *remoteScan) validStreamToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.streamTokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+// snapshot returns a copy of the status and the channel closed on the next change
+func (s *remoteScan) snapshot() (ScanStatus, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Progress = append([]ScanProgress(nil), s.status.Progress...)
+	return status, s.updated
+}
+
+// update changes the status under the lock and wakes up waiting readers
+func (s *remoteScan) update(change func(status *ScanStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(&s.status)
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// finished reports whether the scan has reached a final state
+func (status ScanStatus) finished() bool {
+	return status.Status == scanDone || status.Status == scanFailed || status.Status == scanCancelled
+}
+
+// requestCancel cancels a queued scan at once. A running scan's checks are stopped and it becomes
+// cancelled when they have.
+func (s *remoteScan) requestCancel() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.status.finished():
+		return errScanFinished
+	case s.status.Status == scanQueued:
+		now := time.Now()
+		s.status.Status = scanCancelled
+		s.status.FinishedAt = &now
+		close(s.updated)
+		s.updated = make(chan struct{})
+	default:
+		s.cancel()
+	}
+	return nil
+}
+
+// ScanServer runs scans requested over HTTP. Each tenant only sees its own scans.
+type ScanServer struct {
+	tenants []*serverTenant
+	options CheckerOptions
+	queue   chan *remoteScan
+
+	mu    sync.Mutex
+	scans map[string]*remoteScan
+	order []string
+}
+
+// NewScanServer creates a server for the tenants running up to workers scans at once with the base
+// checker options; up to maxQueued further scans wait for a worker
+func NewScanServer(tenants []*serverTenant, options CheckerOptions, workers, maxQueued int) *ScanServer {
+	if workers < 1 {
+		workers = 1
+	}
+	if maxQueued < 1 {
+		maxQueued = defaultMaxQueued
+	}
+	s := &ScanServer{
+		tenants: tenants,
+		options: options,
+		queue:   make(chan *remoteScan, maxQueued),
+		scans:   make(map[string]*remoteScan),
+	}
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+// Handler returns the HTTP handler for the scan API
+func (s *ScanServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scans", s.authenticated(s.handleScans))
+	mux.HandleFunc("/api/v1/scans/", s.handleStreamToken(s.authenticated(s.handleScan)))
+	mux.HandleFunc("/api/v1/credentials", s.authenticated(s.handleCredentials))
+	return mux
+}
+
+// authenticated rejects requests without a bearer token or OIDC ID token granted a role by a tenant.
+// Browsers cannot set headers on page loads or EventSource, so GET requests may pass the token as
+// ?access_token= instead.
+func (s *ScanServer) authenticated(next func(http.ResponseWriter, *http.Request, *serverTenant, role)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && r.Method == http.MethodGet {
+			token = r.URL.Query().Get("access_token")
+			ok = token != ""
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		tenant, granted := findAccess(r.Context(), s.tenants, token)
+		if tenant == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next(w, r, tenant, granted)
+	}
+}
+
+// handleStreamToken serves GET /api/v1/scans/{id}/events?stream_token= for the dashboard, and passes
+// every other request on to next
+func (s *ScanServer) handleStreamToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("stream_token")
+		id, view, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/scans/"), "/")
+		if token == "" || view != "events" || r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		s.mu.Lock()
+		scan, ok := s.scans[id]
+		s.mu.Unlock()
+		if !ok || !scan.validStreamToken(token) {
+			writeJSONError(w, http.StatusUnauthorized, "invalid or expired stream token")
+			return
+		}
+		s.streamEvents(w, r, scan)
+	}
+}
+
+// handleScans starts a scan for POST /api/v1/scans
+func (s *ScanServer) handleScans(w http.ResponseWriter, r *http.Request, tenant *serverTenant, granted role) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use POST to start a scan")
+		return
+	}
+	if !allowed(w, granted, roleOperator, "start scans") {
+		return
+	}
+
+	var request ScanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid scan request: %v", err))
+		return
+	}
+	if err := request.validate(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := tenant.checkProjects(&request); err != nil {
+		writeJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	scan, err := s.enqueue(tenant, request)
+	if errors.Is(err, errTenantLimit) {
+		writeJSONError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	status, _ := scan.snapshot()
+	w.Header().Set("Location", "/api/v1/scans/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// handleScan serves GET /api/v1/scans/{id}, GET /api/v1/scans/{id}/events, GET /api/v1/scans/{id}/dashboard
+// and DELETE /api/v1/scans/{id}
+func (s *ScanServer) handleScan(w http.ResponseWriter, r *http.Request, tenant *serverTenant, granted role) {
+	id, view, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/scans/"), "/")
+	if r.Method != http.MethodGet && !(r.Method == http.MethodDelete && view == "") {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodDelete)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use GET to read a scan or DELETE to cancel it")
+		return
+	}
+
+	scan, ok := s.lookup(tenant, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if allowed(w, granted, roleOperator, "cancel scans") {
+			s.handleCancel(w, scan)
+		}
+		return
+	}
+
+	switch view {
+	case "":
+	case "events":
+		s.streamEvents(w, r, scan)
+		return
+	case "dashboard":
+		s.serveDashboard(w, r, scan)
+		return
+	default:
+		writeJSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	status, updated := scan.snapshot()
+
+	// Long-poll: hold the request until the scan finishes or the wait runs out
+	if wait := r.URL.Query().Get("wait"); wait != "" && !status.finished() {
+		timeout, err := time.ParseDuration(wait)
+		if err != nil || timeout <= 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid wait %q (use a duration such as 30s)", wait))
+			return
+		}
+		if timeout > maxLongPoll {
+			timeout = maxLongPoll
+		}
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+		for !status.finished() {
+			select {
+			case <-updated:
+				status, updated = scan.snapshot()
+			case <-deadline.C:
+				writeJSON(w, http.StatusOK, status)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleCancel cancels a scan: 200 with the cancelled scan when it was queued, 202 while a running
+// scan stops, 409 when it has already finished
+func (s *ScanServer) handleCancel(w http.ResponseWriter, scan *remoteScan) {
+	if err := scan.requestCancel(); err != nil {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	status, _ := scan.snapshot()
+	code := http.StatusOK
+	if !status.finished() {
+		code = http.StatusAccepted
+	}
+	writeJSON(w, code, status)
+}
+
+// serveDashboard renders the HTML report for a scan; while the scan runs the page follows its events
+func (s *ScanServer) serveDashboard(w http.ResponseWriter, r *http.Request, scan *remoteScan) {
+	status, _ := scan.snapshot()
+	report := status.Report
+	if report == nil {
+		report = &Report{}
+	}
+
+	eventsURL := ""
+	if !status.finished() {
+		token, err := scan.issueStreamToken()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// Results already shown are skipped when the page subscribes
+		query := url.Values{"after": {strconv.Itoa(len(status.Results))}, "stream_token": {token}}
+		eventsURL = "/api/v1/scans/" + status.ID + "/events?" + query.Encode()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := writeHTMLReport(w, report, status.Results, eventsURL); err != nil {
+		log.Printf("Warning: failed to write dashboard: %v", err)
+	}
+}
+
+// streamEvents sends the scan's status as server-sent events until it finishes. While the scan
+// runs, each checked API is sent as a "result" event; ?after=N skips the first N results.
+func (s *ScanServer) streamEvents(w http.ResponseWriter, r *http.Request, scan *remoteScan) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	sent := 0
+	if after := r.URL.Query().Get("after"); after != "" {
+		var err error
+		if sent, err = strconv.Atoi(after); err != nil || sent < 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid after %q (use a number of results)", after))
+			return
+		}
+	}
+	if status, _ := scan.snapshot(); sent > len(status.Results) {
+		sent = len(status.Results)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		status, updated := scan.snapshot()
+		if !status.finished() {
+			for ; sent < len(status.Results); sent++ {
+				data, err := json.Marshal(status.Results[sent])
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "event: result\ndata: %s\n\n", data)
+			}
+		}
+
+		event := "progress"
+		if status.finished() {
+			event = status.Status
+		} else {
+			// The full report is only sent once, with the final event
+			status.Report = nil
+		}
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+
+		if status.finished() {
+			return
+		}
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// enqueue records a new scan for the tenant and queues it for a worker
+func (s *ScanServer) enqueue(tenant *serverTenant, request ScanRequest) (*remoteScan, error) {
+	id, err := newScanID()
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &remoteScan{
+		tenant: tenant,
+		status: ScanStatus{
+			ID:        id,
+			Tenant:    tenant.name,
+			Status:    scanQueued,
+			Request:   request,
+			CreatedAt: time.Now(),
+		},
+		updated: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tenant.maxScans > 0 && s.activeLocked(tenant) >= tenant.maxScans {
+		return nil, errTenantLimit
+	}
+	select {
+	case s.queue <- scan:
+	default:
+		return nil, errQueueFull
+	}
+	s.scans[id] = scan
+	s.order = append(s.order, id)
+	s.evictLocked()
+	return scan, nil
+}
+
+// lookup returns the tenant's scan with the given ID; other tenants' scans are not found
+func (s *ScanServer) lookup(tenant *serverTenant, id string) (*remoteScan, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scan, ok := s.scans[id]
+	if !ok || scan.tenant != tenant {
+		return nil, false
+	}
+	return scan, true
+}
+
+// activeLocked counts the tenant's queued and running scans
+func (s *ScanServer) activeLocked(tenant *serverTenant) int {
+	active := 0
+	for _, scan := range s.scans {
+		if status, _ := scan.snapshot(); scan.tenant == tenant && !status.finished() {
+			active++
+		}
+	}
+	return active
+}
+
+// evictLocked forgets the oldest finished scans beyond maxStoredScans
+func (s *ScanServer) evictLocked() {
+	for i := 0; len(s.order) > maxStoredScans && i < len(s.order); {
+		status, _ := s.scans[s.order[i]].snapshot()
+		if !status.finished() {
+			i++
+			continue
+		}
+		delete(s.scans, s.order[i])
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+}
+
+// worker runs queued scans one at a time
+func (s *ScanServer) worker() {
+	for scan := range s.queue {
+		s.run(scan)
+	}
+}
+
+// scanObserver records a remote scan's results and progress as its checks finish
+type scanObserver struct {
+	NopObserver
+	scan *remoteScan
+	id   string
+}
+
+func (o scanObserver) OnAPIDone(event APIEvent) {
+	o.scan.update(func(status *ScanStatus) {
+		status.Results = append(status.Results, *event.Result)
+		for i := range status.Progress {
+			if status.Progress[i].ProjectID == event.ProjectID {
+				status.Progress[i].Done, status.Progress[i].Total = event.Done, event.Total
+				return
+			}
+		}
+		status.Progress = append(status.Progress, ScanProgress{ProjectID: event.ProjectID, Done: event.Done, Total: event.Total})
+		sort.Slice(status.Progress, func(i, j int) bool { return status.Progress[i].ProjectID < status.Progress[j].ProjectID })
+	})
+}
+
+func (o scanObserver) OnError(event APIEvent, err error) {
+	if event.API == "" {
+		log.Printf("Warning: scan %s of %s: %v", o.id, event.ProjectID, err)
+	}
+}
+
+// run executes a scan and records its report
+func (s *ScanServer) run(scan *remoteScan) {
+	status, _ := scan.snapshot()
+	request := status.Request
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := false
+	scan.update(func(status *ScanStatus) {
+		// A scan cancelled while queued is not run
+		if status.finished() {
+			return
+		}
+		now := time.Now()
+		status.Status = scanRunning
+		status.StartedAt = &now
+		scan.cancel = cancel
+		started = true
+	})
+	if !started {
+		log.Printf("Scan %s was cancelled before it started", status.ID)
+		return
+	}
+
+	options := request.apply(s.options)
+	options.Observer = scanObserver{scan: scan, id: status.ID}
+	options.Context = ctx
+
+	projects := request.Projects
+	if len(projects) == 0 {
+		projects = []string{""}
+	}
+
+	log.Printf("Scan %s started for %s", status.ID, strings.Join(projects, ", "))
+	output, err := ScanProjects(scan.tenant.token(), projects, threads, parallelProjects, options)
+	cancelled := ctx.Err() != nil
+
+	// The report assigns the cost tiers, so it comes before the history that keeps them
+	var report *Report
+	if err == nil || len(output.Results) > 0 {
+		report = GenerateReportWithPolicy(output.Results, PolicyFromConfig(config))
+		report.SetFindings(output.Findings)
+		report.Overhead = output.Overhead
+		report.SetContacts(output.Contacts)
+		report.Tags = request.Tags
+		report.Summary.CostSkipped = options.SkipCost
+	}
+
+	// A cancelled scan's partial results would distort the history's trends
+	if scan.tenant.history != nil && len(output.Results) > 0 && !cancelled {
+		if _, historyErr := SaveHistory(scan.tenant.history, output.Results, request.Tags); historyErr != nil {
+			log.Printf("Warning: failed to save scan %s for tenant %s: %v", status.ID, scan.tenant.name, historyErr)
+		}
+	}
+
+	scan.update(func(status *ScanStatus) {
+		now := time.Now()
+		status.FinishedAt = &now
+		status.Report = report
+		status.Results = output.Results
+		status.Status = scanDone
+		switch {
+		case cancelled:
+			// The report covers the checks finished before the scan stopped
+			status.Status = scanCancelled
+		case err != nil:
+			status.Error = redactSecrets(err.Error())
+			if report == nil {
+				status.Status = scanFailed
+			}
+		}
+	})
+	if cancelled {
+		log.Printf("Scan %s cancelled", status.ID)
+		return
+	}
+	log.Printf("Scan %s finished", status.ID)
+}
+
+// serverTenants returns the config file's tenants, or a single tenant using the server token and the
+// config file's access grants
+func serverTenants(authTokenFrom string) ([]*serverTenant, error) {
+	if len(config.Tenants) > 0 {
+		if authTokenFrom != "" {
+			return nil, fmt.Errorf("--auth-token-from cannot be used with tenants in the config file; set auth_token_from per tenant")
+		}
+		if len(config.Access) > 0 {
+			return nil, fmt.Errorf("access in the config file cannot be used with tenants; set access per tenant")
+		}
+		return loadTenants(config.Tenants)
+	}
+
+	authToken := os.Getenv(serverTokenEnvVar)
+	if authTokenFrom != "" {
+		var err error
+		if authToken, err = resolveToken("", authTokenFrom); err != nil {
+			return nil, err
+		}
+	}
+	if authToken == "" && len(config.Access) == 0 {
+		return nil, fmt.Errorf("the server needs a client token: set --auth-token-from or $%s, or define access or tenants in the config file", serverTokenEnvVar)
+	}
+
+	// The server token has full access, as before roles existed
+	owners := make(map[string]string)
+	var grants []accessGrant
+	if authToken != "" {
+		registerSecret(authToken)
+		owners[authToken] = "the server token"
+		grants = append(grants, accessGrant{role: roleAdmin, token: authToken})
+	}
+	access, err := loadGrants(config.Access, "the config file's access", owners)
+	if err != nil {
+		return nil, err
+	}
+	return []*serverTenant{{grants: append(grants, access...), apiToken: apiToken, tokenSource: "server token"}}, nil
+}
+
+// newScanID returns a random scan identifier
+func newScanID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create scan ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// writeJSONError writes an error response
+func writeJSONError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+// newServeCmd creates the subcommand that runs the scan API server
+func newServeCmd() *cobra.Command {
+	var listen string
+	var grpcListen string
+	var authTokenFrom string
+	var workers int
+	var maxQueued int
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP API that other systems can call to trigger scans",
+		Long: `Run an HTTP API for triggering scans remotely:
+
+  POST /api/v1/scans                 start a scan, returns its ID
+  GET  /api/v1/scans/{id}            status and report (?wait=30s to long-poll)
+  GET  /api/v1/scans/{id}/events     progress and results as server-sent events
+  GET  /api/v1/scans/{id}/dashboard  HTML report that fills in live while the scan runs
+  DELETE /api/v1/scans/{id}          cancel a queued or running scan
+  GET|PUT /api/v1/credentials        show or replace the Google API token scans use
+
+With --grpc-listen the same scans are also available over gRPC through the
+ScanService defined in proto/scanner/v1/scanner.proto.
+
+Every request must send "Authorization: Bearer <token>" with the token from
+--auth-token-from or $` + serverTokenEnvVar + `. With tenants in the config file,
+each tenant sends its own token and only sees its own scans. The access section
+of the config file grants further tokens and Google identities the viewer,
+operator or admin role.`,
+		Example: `  googleapichecker serve --listen :8080 --auth-token-from env:SCANNER_TOKEN
+  curl -H "Authorization: Bearer $SCANNER_TOKEN" -d '{"projects":["my-project"]}' localhost:8080/api/v1/scans`,
+		Args:    cobra.NoArgs,
+		PreRunE: requireServerToken,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if maxQueued < 1 {
+				return fmt.Errorf("--max-queued must be at least 1")
+			}
+			tenants, err := serverTenants(authTokenFrom)
+			if err != nil {
+				return err
+			}
+
+			server := NewScanServer(tenants, buildCheckerOptions(), workers, maxQueued)
+			httpServer := &http.Server{
+				Addr:              listen,
+				Handler:           server.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			var grpcServer *grpc.Server
+			if grpcListen != "" {
+				listener, err := net.Listen("tcp", grpcListen)
+				if err != nil {
+					return fmt.Errorf("failed to listen on %s: %v", grpcListen, err)
+				}
+				grpcServer = NewGRPCServer(server)
+				go func() {
+					if err := grpcServer.Serve(listener); err != nil {
+						log.Printf("Warning: gRPC server failed: %v", err)
+					}
+				}()
+				fmt.Printf("🌐 gRPC scan service listening on %s\n", grpcListen)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if grpcServer != nil {
+					grpcServer.GracefulStop()
+				}
+				httpServer.Shutdown(shutdown)
+			}()
+
+			if len(config.Tenants) > 0 {
+				fmt.Printf("👥 Serving %d tenant(s)\n", len(tenants))
+			}
+			fmt.Printf("🌐 Scan API listening on %s\n", listen)
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("server failed: %v", err)
+			}
+			fmt.Println("👋 Server stopped")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&grpcListen, "grpc-listen", "", "Also serve the gRPC ScanService on this address, e.g. :9090")
+	cmd.Flags().StringVar(&authTokenFrom, "auth-token-from", "", "Read the bearer token clients must send from env:VAR, file:path or secretmanager:projects/P/secrets/S")
+	cmd.Flags().IntVar(&workers, "max-scans", 1, "Number of scans to run at once; further scans are queued")
+	cmd.Flags().IntVar(&maxQueued, "max-queued", defaultMaxQueued, "Number of scans that can wait for a free slot; further requests are refused with 503")
+	return cmd
+}
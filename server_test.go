@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testScanServer serves one running scan with two results, readable with the client token "secret"
+func testScanServer(t *testing.T) (*httptest.Server, *remoteScan) {
+	t.Helper()
+	tenant := &serverTenant{name: "default", grants: []accessGrant{{role: roleViewer, token: "secret"}}}
+	server := NewScanServer([]*serverTenant{tenant}, CheckerOptions{}, 1, 1)
+	now := time.Now()
+	scan := &remoteScan{
+		tenant: tenant,
+		status: ScanStatus{
+			ID:        "scan1",
+			Status:    scanRunning,
+			CreatedAt: now,
+			StartedAt: &now,
+			Results: []APIResult{
+				{ProjectID: "p", Name: "compute.googleapis.com", Status: statusEnabled, Enabled: true},
+				{ProjectID: "p", Name: "vision.googleapis.com", Status: statusDisabled},
+			},
+		},
+		updated: make(chan struct{}),
+	}
+	server.scans[scan.status.ID] = scan
+	server.order = append(server.order, scan.status.ID)
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return httpServer, scan
+}
+
+// getEvents reads the event stream up to the first progress event
+func getEvents(t *testing.T, target string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body strings.Builder
+	buf := make([]byte, 4096)
+	for !strings.Contains(body.String(), "event: progress") {
+		n, err := resp.Body.Read(buf)
+		body.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	return resp.StatusCode, body.String()
+}
+
+func TestStreamEventsAfter(t *testing.T) {
+	server, _ := testScanServer(t)
+	events := server.URL + "/api/v1/scans/scan1/events?access_token=secret&after="
+
+	tests := []struct {
+		after   string
+		code    int
+		results int
+	}{
+		{"", http.StatusOK, 2},
+		{"1", http.StatusOK, 1},
+		{"2", http.StatusOK, 0},
+		{"99", http.StatusOK, 0},
+		{"-1", http.StatusBadRequest, 0},
+		{"abc", http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.after, func(t *testing.T) {
+			code, body := getEvents(t, events+test.after)
+			if code != test.code {
+				t.Fatalf("status %d, want %d: %s", code, test.code, body)
+			}
+			if results := strings.Count(body, "event: result"); results != test.results {
+				t.Errorf("got %d results, want %d", results, test.results)
+			}
+		})
+	}
+}
+
+func TestDashboardStreamToken(t *testing.T) {
+	server, _ := testScanServer(t)
+
+	resp, err := http.Get(server.URL + "/api/v1/scans/scan1/dashboard?access_token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(page), "secret") {
+		t.Fatal("the dashboard contains the client token")
+	}
+	match := regexp.MustCompile(`stream_token=([0-9a-f]+)`).FindSubmatch(page)
+	if match == nil {
+		t.Fatal("the dashboard has no stream token")
+	}
+	token := string(match[1])
+
+	if code, _ := getEvents(t, server.URL+"/api/v1/scans/scan1/events?stream_token="+token); code != http.StatusOK {
+		t.Errorf("events with the stream token: status %d", code)
+	}
+	for name, target := range map[string]string{
+		"unknown token":  "/api/v1/scans/scan1/events?stream_token=0123",
+		"other view":     "/api/v1/scans/scan1?stream_token=" + token,
+		"dashboard view": "/api/v1/scans/scan1/dashboard?stream_token=" + token,
+	} {
+		if code, _ := getEvents(t, server.URL+target); code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", name, code)
+		}
+	}
+}
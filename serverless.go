@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// bigQueryInsertBatch is the number of rows sent per tabledata.insertAll request
+const bigQueryInsertBatch = 500
+
+// DeployScanRequest is the body of a serverless scan trigger: a scan request, optionally wrapped in a
+// Pub/Sub push message, that can also scan every project linked to billing accounts
+type DeployScanRequest struct {
+	ScanRequest
+	BillingAccounts []string `json:"billing_accounts,omitempty"`
+}
+
+// DeployScanResponse is returned once a serverless scan has finished and its results are stored
+type DeployScanResponse struct {
+	ID            string      `json:"id"`
+	Projects      []string    `json:"projects"`
+	Results       int         `json:"results"`
+	ResultsObject string      `json:"results_object,omitempty"`
+	ReportObject  string      `json:"report_object,omitempty"`
+	BigQueryRows  int         `json:"bigquery_rows,omitempty"`
+	Summary       SummaryInfo `json:"summary"`
+	Error         string      `json:"error,omitempty"`
+}
+
+// pubsubPush is the envelope Pub/Sub push subscriptions POST; data holds the scan request
+type pubsubPush struct {
+	Message struct {
+		Data       []byte            `json:"data"`
+		Attributes map[string]string `json:"attributes"`
+		MessageID  string            `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// DeployService runs one scan per HTTP request, as Cloud Run and Cloud Functions expect: the scan
+// finishes before the response is sent, and the results go to Cloud Storage and BigQuery
+type DeployService struct {
+	options CheckerOptions
+	// store keeps each scan's results and report; nil when no state backend is set
+	store StateStore
+	// table receives a row per API result; nil when no BigQuery table is set
+	table *bigQueryTable
+
+	// mu runs one scan at a time, so several triggers arriving at once do not share the quota
+	mu sync.Mutex
+}
+
+// Handler returns the HTTP handler receiving scan triggers on /
+func (s *DeployService) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleTrigger)
+	return mux
+}
+
+// handleTrigger runs the scan of a direct request or a Pub/Sub push message. A malformed Pub/Sub
+// message is acknowledged rather than rejected, as Pub/Sub would redeliver it forever.
+func (s *DeployService) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use POST to trigger a scan")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request: %v", err))
+		return
+	}
+
+	request, messageID, err := parseDeployTrigger(body)
+	if err == nil {
+		err = request.validate()
+	}
+	if err == nil && len(request.Projects) == 0 && len(request.BillingAccounts) == 0 && len(projectIDs) == 0 && len(billingAccounts) == 0 {
+		err = fmt.Errorf("no projects to scan: send projects or billing_accounts, or set --project or --billing-account")
+	}
+	if err != nil {
+		if messageID != "" {
+			log.Printf("Warning: dropping Pub/Sub message %s: %v", messageID, err)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := s.scan(r.Context(), request)
+	if err != nil {
+		// Pub/Sub retries the message after a server error
+		log.Printf("Warning: scan %s failed: %v", response.ID, err)
+		response.Error = redactSecrets(err.Error())
+		writeJSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseDeployTrigger decodes a scan request, unwrapping it from a Pub/Sub push message. It returns
+// the message ID when the body is a push message. An empty request or message data scans the
+// --project projects and --billing-account accounts.
+func parseDeployTrigger(body []byte) (DeployScanRequest, string, error) {
+	var request DeployScanRequest
+	data := bytes.TrimSpace(body)
+
+	var push pubsubPush
+	messageID := ""
+	if json.Unmarshal(data, &push) == nil && push.Message.MessageID != "" {
+		messageID = push.Message.MessageID
+		data = bytes.TrimSpace(push.Message.Data)
+	}
+	if len(data) == 0 {
+		return request, messageID, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return request, messageID, fmt.Errorf("invalid scan request: %v", err)
+	}
+	return request, messageID, nil
+}
+
+// scan runs the requested scan and stores its results and report
+func (s *DeployService) scan(ctx context.Context, request DeployScanRequest) (DeployScanResponse, error) {
+	var response DeployScanResponse
+	id, err := newScanID()
+	if err != nil {
+		return response, err
+	}
+	response.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	options := request.apply(s.options)
+	// A request cancelled by the platform's timeout stops the scan
+	options.Context = ctx
+
+	projects, accounts := request.Projects, request.BillingAccounts
+	if len(projects) == 0 && len(accounts) == 0 {
+		projects, accounts = projectIDs, billingAccounts
+	}
+	if len(accounts) > 0 {
+		if projects, err = billingAccountProjects(apiToken, accounts, projects, options); err != nil {
+			return response, err
+		}
+	}
+	if len(projects) == 0 {
+		return response, fmt.Errorf("no projects with billing enabled are linked to %s", strings.Join(accounts, ", "))
+	}
+	response.Projects = projects
+
+	log.Printf("Scan %s started for %s", id, strings.Join(projects, ", "))
+	output, err := ScanProjects(apiToken, projects, threads, parallelProjects, options)
+	if err != nil && (len(output.Results) == 0 || ctx.Err() != nil) {
+		return response, fmt.Errorf("error checking APIs: %v", err)
+	}
+	if err != nil {
+		log.Printf("Warning: some projects could not be scanned: %v", err)
+		response.Error = redactSecrets(err.Error())
+	}
+
+	report := GenerateReportWithPolicy(output.Results, PolicyFromConfig(config))
+	report.SetFindings(output.Findings)
+	report.Overhead = output.Overhead
+	report.SetContacts(output.Contacts)
+	report.Tags = request.Tags
+	report.Summary.CostSkipped = options.SkipCost
+	response.Results = len(output.Results)
+	response.Summary = report.Summary
+
+	if s.store != nil {
+		if response.ResultsObject, response.ReportObject, err = saveDeployScan(s.store, output.Results, report, request.Tags); err != nil {
+			return response, err
+		}
+	}
+	if s.table != nil {
+		if response.BigQueryRows, err = s.table.insertResults(id, time.Now(), output.Results); err != nil {
+			return response, err
+		}
+	}
+	log.Printf("Scan %s finished with %d results", id, len(output.Results))
+	return response, nil
+}
+
+// saveDeployScan keeps the results in the history format, so history and diff read them, with the
+// report next to them
+func saveDeployScan(store StateStore, results []APIResult, report *Report, tags map[string]string) (string, string, error) {
+	resultsObject, err := SaveHistory(store, results, tags)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return resultsObject, "", fmt.Errorf("failed to encode report: %v", err)
+	}
+	reportObject := "report_" + strings.TrimPrefix(resultsObject, "results_")
+	if err := store.Save(reportObject, append(data, '\n')); err != nil {
+		return resultsObject, "", err
+	}
+	return resultsObject, reportObject, nil
+}
+
+// bigQueryTable streams API results into an existing BigQuery table
+type bigQueryTable struct {
+	project, dataset, table string
+	client                  *http.Client
+}
+
+// parseBigQueryTable parses a table given as project.dataset.table
+func parseBigQueryTable(spec string) (*bigQueryTable, error) {
+	parts := strings.Split(spec, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid BigQuery table %q (use project.dataset.table)", spec)
+	}
+	return &bigQueryTable{
+		project: parts[0],
+		dataset: parts[1],
+		table:   parts[2],
+		client:  &http.Client{Timeout: 60 * time.Second, Transport: apiTransport},
+	}, nil
+}
+
+func (t *bigQueryTable) String() string {
+	return t.project + "." + t.dataset + "." + t.table
+}
+
+// insertResults streams a row per result and returns how many rows were inserted. Insert IDs let
+// BigQuery drop rows that are sent twice.
+func (t *bigQueryTable) insertResults(scanID string, scannedAt time.Time, results []APIResult) (int, error) {
+	type row struct {
+		InsertID string                 `json:"insertId"`
+		JSON     map[string]interface{} `json:"json"`
+	}
+
+	inserted := 0
+	for start := 0; start < len(results); start += bigQueryInsertBatch {
+		end := min(start+bigQueryInsertBatch, len(results))
+		rows := make([]row, 0, end-start)
+		for _, result := range results[start:end] {
+			rows = append(rows, row{
+				InsertID: scanID + "/" + result.ProjectID + "/" + result.Name,
+				JSON: map[string]interface{}{
+					"scan_id":        scanID,
+					"scanned_at":     scannedAt.UTC().Format(time.RFC3339),
+					"project_id":     result.ProjectID,
+					"environment":    result.Environment,
+					"name":           result.Name,
+					"display_name":   result.DisplayName,
+					"status":         result.Status,
+					"enabled":        result.Enabled,
+					"estimated_cost": result.CostInfo.EstimatedCost,
+					"currency":       result.CostInfo.Currency,
+					"cost_tier":      result.CostTier,
+					"unlimited_cost": result.CostInfo.UnlimitedCost,
+					"error":          result.Error,
+				},
+			})
+		}
+		if err := t.insert(rows); err != nil {
+			return inserted, err
+		}
+		inserted += len(rows)
+	}
+	return inserted, nil
+}
+
+// insert sends one tabledata.insertAll request and fails when any row is rejected
+func (t *bigQueryTable) insert(rows interface{}) error {
+	accessToken, err := gcloudAccessToken()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{"rows": rows})
+	if err != nil {
+		return fmt.Errorf("failed to encode BigQuery rows: %v", err)
+	}
+
+	requestURL := fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		url.PathEscape(t.project), url.PathEscape(t.dataset), url.PathEscape(t.table))
+	req, err := http.NewRequest("POST", endpoints.resolve(requestURL), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to insert rows into %s: %v", t, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("failed to insert rows into %s, status: %d", t, resp.StatusCode)
+	}
+
+	var response struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to parse BigQuery insert response: %v", err)
+	}
+	if len(response.InsertErrors) > 0 {
+		first := response.InsertErrors[0]
+		message := "rejected"
+		if len(first.Errors) > 0 {
+			message = first.Errors[0].Message
+		}
+		return fmt.Errorf("BigQuery rejected %d rows of %s, e.g. row %d: %s", len(response.InsertErrors), t, first.Index, message)
+	}
+	return nil
+}
+
+// defaultDeployListen is the listen address on Cloud Run, which passes the port in $PORT
+func defaultDeployListen() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// newServerlessCmd creates the subcommand that runs the scan service for Cloud Run and Cloud Functions
+func newServerlessCmd() *cobra.Command {
+	var listen string
+	var bigQuery string
+
+	cmd := &cobra.Command{
+		Use:   "serverless",
+		Short: "Run a scan service for Cloud Run or Cloud Functions, triggered over HTTP or by Pub/Sub",
+		Long: `Run the deploy mode scan service. Every POST to / runs one scan and answers
+once it has finished, as Cloud Run and Cloud Functions expect, so Cloud Scheduler
+can run scheduled scans without a server of your own.
+
+The body is a scan request as accepted by "serve", plus billing_accounts to
+scan every project linked to them. It can also be a Pub/Sub push message whose
+data is such a request. An empty request scans the --project projects and the
+--billing-account accounts.
+
+Results are kept with --state-backend in Cloud Storage, where "history" and
+"diff" read them, and streamed with --bigquery-table into BigQuery. Requests are
+not authenticated by the service: deploy it without unauthenticated access and
+let Cloud Run check the caller's identity.`,
+		Example: `  googleapichecker serverless --token-from secretmanager:projects/P/secrets/api-key \
+    --billing-account 012345-6789AB-CDEF01 --state-backend gs://my-bucket/scans \
+    --bigquery-table my-project.api_scans.results`,
+		Args:    cobra.NoArgs,
+		PreRunE: requireToken,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service := &DeployService{options: buildCheckerOptions()}
+			service.options.NoProgress = true
+
+			if stateBackend != "" {
+				store, err := OpenStateStore(stateBackend, "", "history")
+				if err != nil {
+					return err
+				}
+				service.store = store
+				fmt.Printf("🗄️  Keeping scan results in %s\n", store)
+			}
+			if bigQuery != "" {
+				table, err := parseBigQueryTable(bigQuery)
+				if err != nil {
+					return err
+				}
+				service.table = table
+				fmt.Printf("📤 Streaming results into BigQuery table %s\n", table)
+			}
+			if service.store == nil && service.table == nil {
+				fmt.Println("⚠️  Neither --state-backend nor --bigquery-table is set; results are only returned in the response")
+			}
+
+			httpServer := &http.Server{
+				Addr:              listen,
+				Handler:           service.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			// Cloud Run sends SIGTERM before stopping an instance
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				httpServer.Shutdown(shutdown)
+			}()
+
+			fmt.Printf("🌐 Scan service listening on %s\n", listen)
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("server failed: %v", err)
+			}
+			fmt.Println("👋 Server stopped")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", defaultDeployListen(), "Address to listen on; on Cloud Run the port comes from $PORT")
+	cmd.Flags().StringSliceVar(&billingAccounts, "billing-account", nil, "Scan every project linked to these billing accounts when a request names no projects")
+	cmd.Flags().StringVar(&stateBackend, "state-backend", "", "Keep each scan's results and report in Cloud Storage (gs://bucket/prefix)")
+	cmd.Flags().StringVar(&bigQuery, "bigquery-table", "", "Stream a row per API result into this existing BigQuery table (project.dataset.table)")
+	return cmd
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ServiceNow record types policy violations can be filed as
+const (
+	serviceNowIncident      = "incident"
+	serviceNowChangeRequest = "change_request"
+)
+
+// ServiceNowConfig configures the ServiceNow records created for policy violations with --servicenow
+type ServiceNowConfig struct {
+	// InstanceURL is the instance, e.g. https://example.service-now.com
+	InstanceURL string `yaml:"instance_url"`
+	// Username and PasswordFrom are the credentials of the integration user; the password is read
+	// from env:VAR, file:path or secretmanager:projects/P/secrets/S
+	Username     string `yaml:"username"`
+	PasswordFrom string `yaml:"password_from"`
+	// Record is incident (default) or change_request
+	Record string `yaml:"record"`
+	// AssignmentGroup, Urgency and Impact are set on every record when given
+	AssignmentGroup string `yaml:"assignment_group"`
+	Urgency         string `yaml:"urgency"`
+	Impact          string `yaml:"impact"`
+	// Rules limits the violations filed to these rules, e.g. disallowed_api; empty files all
+	Rules []string `yaml:"rules"`
+	// Fields are further record fields, e.g. category or a custom u_ field
+	Fields map[string]string `yaml:"fields"`
+}
+
+// validate rejects unknown record types and rules
+func (c ServiceNowConfig) validate() error {
+	if c.Record != "" && c.Record != serviceNowIncident && c.Record != serviceNowChangeRequest {
+		return fmt.Errorf("servicenow.record must be %s or %s, not %q", serviceNowIncident, serviceNowChangeRequest, c.Record)
+	}
+	for _, rule := range c.Rules {
+		if rule != "disallowed_api" && rule != "total_cost" {
+			return fmt.Errorf("servicenow.rules: unknown rule %q (use disallowed_api or total_cost)", rule)
+		}
+	}
+	return nil
+}
+
+// serviceNowClient files violations with --servicenow, nil without it
+var serviceNowClient *ServiceNowClient
+
+// ServiceNowClient creates records with the ServiceNow Table API
+type ServiceNowClient struct {
+	config   ServiceNowConfig
+	table    string
+	password string
+	client   *http.Client
+}
+
+// NewServiceNowClient checks the servicenow settings of the config file and reads the password
+func NewServiceNowClient(c ServiceNowConfig) (*ServiceNowClient, error) {
+	if c.InstanceURL == "" || c.Username == "" || c.PasswordFrom == "" {
+		return nil, fmt.Errorf("--servicenow needs servicenow.instance_url, username and password_from in the config file")
+	}
+	if parsed, err := url.Parse(c.InstanceURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("servicenow.instance_url must be an https:// URL")
+	}
+	password, err := resolveToken("", c.PasswordFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ServiceNow password: %v", err)
+	}
+	registerSecret(password)
+
+	table := c.Record
+	if table == "" {
+		table = serviceNowIncident
+	}
+	return &ServiceNowClient{
+		config:   c,
+		table:    table,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Table is the record type the client creates
+func (s *ServiceNowClient) Table() string {
+	return s.table
+}
+
+// violationCorrelationID identifies a violation across scans, so an open record is not filed twice
+func violationCorrelationID(violation PolicyViolation) string {
+	sum := sha256.Sum256([]byte(violation.ProjectID + "/" + violation.Rule + "/" + violation.API))
+	return "googleapichecker-" + hex.EncodeToString(sum[:12])
+}
+
+// FileViolations creates a record for each violation that has no open record yet and sets the
+// violation's Ticket to the new or open record's number. It returns how many records were created
+// and how many violations already had an open record.
+func (s *ServiceNowClient) FileViolations(violations []PolicyViolation) (created, open int, err error) {
+	for i, violation := range violations {
+		if !s.files(violation.Rule) {
+			continue
+		}
+
+		correlationID := violationCorrelationID(violation)
+		number, err := s.openRecord(correlationID)
+		if err != nil {
+			return created, open, err
+		}
+		if number != "" {
+			open++
+		} else {
+			if number, err = s.createRecord(violation, correlationID); err != nil {
+				return created, open, err
+			}
+			created++
+		}
+		violations[i].Ticket = number
+	}
+	return created, open, nil
+}
+
+// files reports whether violations of the rule are filed
+func (s *ServiceNowClient) files(rule string) bool {
+	if len(s.config.Rules) == 0 {
+		return true
+	}
+	for _, filed := range s.config.Rules {
+		if filed == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// openRecord returns the number of the active record with the correlation ID, or "" when there is none
+func (s *ServiceNowClient) openRecord(correlationID string) (string, error) {
+	query := url.Values{
+		"sysparm_query":  {"active=true^correlation_id=" + correlationID},
+		"sysparm_fields": {"number"},
+		"sysparm_limit":  {"1"},
+	}
+	var response struct {
+		Result []struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := s.do(http.MethodGet, s.tableURL()+"?"+query.Encode(), nil, &response); err != nil {
+		return "", fmt.Errorf("failed to look up ServiceNow %s records: %v", s.table, err)
+	}
+	if len(response.Result) == 0 {
+		return "", nil
+	}
+	return response.Result[0].Number, nil
+}
+
+// createRecord files a violation and returns the new record's number
+func (s *ServiceNowClient) createRecord(violation PolicyViolation, correlationID string) (string, error) {
+	fields := make(map[string]string, len(s.config.Fields)+6)
+	for name, value := range s.config.Fields {
+		fields[name] = value
+	}
+	fields["short_description"] = "Google API policy violation: " + violationText(violation)
+	fields["description"] = violationDescription(violation)
+	fields["correlation_id"] = correlationID
+	for name, value := range map[string]string{
+		"assignment_group": s.config.AssignmentGroup,
+		"urgency":          s.config.Urgency,
+		"impact":           s.config.Impact,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ServiceNow record: %v", err)
+	}
+	var response struct {
+		Result struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := s.do(http.MethodPost, s.tableURL(), body, &response); err != nil {
+		return "", fmt.Errorf("failed to create ServiceNow %s: %v", s.table, err)
+	}
+	return response.Result.Number, nil
+}
+
+// violationDescription is the record description, with what to do about the violation
+func violationDescription(violation PolicyViolation) string {
+	lines := []string{
+		"Google API Checker found a policy violation.",
+		"",
+		"Project: " + violation.ProjectID,
+		"Environment: " + violation.Environment,
+		"Rule: " + violation.Rule,
+	}
+	if violation.API != "" {
+		lines = append(lines, "API: "+violation.API)
+	}
+	lines = append(lines, "", violation.Message)
+	switch violation.Rule {
+	case "disallowed_api":
+		lines = append(lines, "", fmt.Sprintf("Remediation: gcloud services disable %s --project=%s", violation.API, violation.ProjectID))
+	case "total_cost":
+		lines = append(lines, "", "Remediation: disable unused APIs or raise the environment's total_cost limit")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableURL is the Table API endpoint of the record type
+func (s *ServiceNowClient) tableURL() string {
+	return strings.TrimSuffix(s.config.InstanceURL, "/") + "/api/now/table/" + s.table
+}
+
+// do sends a Table API request and decodes the JSON response into result
+func (s *ServiceNowClient) do(method, target string, body []byte, result interface{}) error {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.SetBasicAuth(s.config.Username, s.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
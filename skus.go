@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// maxCostSKUs is how many SKUs are kept per API, the largest contributors first
+const maxCostSKUs = 3
+
+// SKUCost is a billing SKU's part of an API's estimated cost
+type SKUCost struct {
+	Name      string  `json:"name"`
+	UnitPrice float64 `json:"unit_price"`
+	Unit      string  `json:"unit"`
+	// EstimatedCost is the SKU's typical share of the API's estimated monthly cost
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// skuPrice is a billing SKU's list price and its typical share of the service's spend
+type skuPrice struct {
+	Name      string
+	UnitPrice float64 // USD per Unit
+	Unit      string
+	Share     float64 // Typical fraction of the service's bill, used to split the estimate
+}
+
+// skuPrices are the main billing SKUs of the services in the pricing table
+var skuPrices = map[string][]skuPrice{
+	"compute.googleapis.com": {
+		{"N1 Predefined Instance Core", 0.031611, "vCPU-hour", 0.6},
+		{"N1 Predefined Instance Ram", 0.004237, "GiB-hour", 0.3},
+		{"Storage PD Capacity", 0.04, "GiB-month", 0.1},
+	},
+	"storage.googleapis.com": {
+		{"Standard Storage US Multi-region", 0.026, "GiB-month", 0.7},
+		{"Network Internet Egress", 0.12, "GiB", 0.2},
+		{"Class A Operations", 0.05, "10K operations", 0.1},
+	},
+	"bigquery.googleapis.com": {
+		{"Analysis", 6.25, "TiB", 0.8},
+		{"Active Logical Storage", 0.02, "GiB-month", 0.2},
+	},
+	"pubsub.googleapis.com": {
+		{"Message Delivery Basic", 40, "TiB", 0.9},
+		{"Retained Acknowledged Messages", 0.27, "GiB-month", 0.1},
+	},
+	"cloudfunctions.googleapis.com": {
+		{"CPU Time", 0.00001, "GHz-second", 0.45},
+		{"Memory Time", 0.0000025, "GiB-second", 0.35},
+		{"Invocations", 0.40, "1M invocations", 0.2},
+	},
+	"firestore.googleapis.com": {
+		{"Document Reads", 0.06, "100K reads", 0.5},
+		{"Document Writes", 0.18, "100K writes", 0.35},
+		{"Stored Data", 0.18, "GiB-month", 0.15},
+	},
+	"datastore.googleapis.com": {
+		{"Entity Reads", 0.06, "100K reads", 0.5},
+		{"Entity Writes", 0.18, "100K writes", 0.35},
+		{"Stored Data", 0.18, "GiB-month", 0.15},
+	},
+	"maps.googleapis.com": {
+		{"Dynamic Maps", 7.00, "1K loads", 0.7},
+		{"Static Maps", 2.00, "1K loads", 0.3},
+	},
+	"places.googleapis.com": {
+		{"Place Details", 17.00, "1K requests", 0.6},
+		{"Autocomplete - Per Request", 2.83, "1K requests", 0.4},
+	},
+	"geocoding.googleapis.com": {
+		{"Geocoding", 5.00, "1K requests", 1},
+	},
+	"cloudsql.googleapis.com": {
+		{"DB custom CORE", 0.0413, "vCPU-hour", 0.55},
+		{"DB custom RAM", 0.007, "GiB-hour", 0.3},
+		{"Storage PD SSD", 0.17, "GiB-month", 0.15},
+	},
+	"container.googleapis.com": {
+		{"Cluster Management Fee", 0.10, "cluster-hour", 1},
+	},
+	"vision.googleapis.com": {
+		{"Label Detection", 1.50, "1K units", 0.6},
+		{"Text Detection", 1.50, "1K units", 0.4},
+	},
+	"speech.googleapis.com": {
+		{"Speech Recognition", 0.024, "minute", 1},
+	},
+	"translate.googleapis.com": {
+		{"Translation", 20.00, "1M characters", 1},
+	},
+	"dataflow.googleapis.com": {
+		{"vCPU Time Batch", 0.056, "vCPU-hour", 0.7},
+		{"RAM Time Batch", 0.003557, "GiB-hour", 0.3},
+	},
+	"dataproc.googleapis.com": {
+		{"Dataproc vCPU", 0.01, "vCPU-hour", 1},
+	},
+	"appengine.googleapis.com": {
+		{"Frontend Instances F1", 0.05, "instance-hour", 0.8},
+		{"Outgoing Network Traffic", 0.12, "GiB", 0.2},
+	},
+}
+
+// skuBreakdown splits an API's estimated cost over its top SKUs by their typical share; nil when
+// the API has no SKUs in the pricing table
+func skuBreakdown(apiName string, estimatedCost float64) []SKUCost {
+	prices := append([]skuPrice(nil), skuPrices[apiName]...)
+	if len(prices) == 0 {
+		return nil
+	}
+	sort.SliceStable(prices, func(i, j int) bool { return prices[i].Share > prices[j].Share })
+
+	skus := make([]SKUCost, 0, min(len(prices), maxCostSKUs))
+	for _, price := range prices[:min(len(prices), maxCostSKUs)] {
+		skus = append(skus, SKUCost{
+			Name:          price.Name,
+			UnitPrice:     price.UnitPrice,
+			Unit:          price.Unit,
+			EstimatedCost: estimatedCost * price.Share,
+		})
+	}
+	return skus
+}
+
+// hasSKUs reports whether any result has a SKU breakdown
+func hasSKUs(results []APIResult) bool {
+	for _, result := range results {
+		if len(result.CostInfo.SKUs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSKUCSV writes one row per SKU of every API with a SKU breakdown
+func writeSKUCSV(filename string, results []APIResult, delimiter rune) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+
+	if err := writer.Write([]string{"project", "name", "display_name", "sku", "unit_price", "unit", "estimated_cost"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %v", err)
+	}
+
+	for _, result := range results {
+		for _, sku := range result.CostInfo.SKUs {
+			row := []string{result.ProjectID, result.Name, result.DisplayName, sku.Name,
+				strconv.FormatFloat(sku.UnitPrice, 'f', -1, 64), sku.Unit,
+				strconv.FormatFloat(projection.amount(sku.EstimatedCost), 'f', 2, 64)}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %v", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateStore keeps state that must survive between runs, such as the scan history
+type StateStore interface {
+	// Save writes the named object, replacing any existing one
+	Save(name string, data []byte) error
+	// Load reads the named object
+	Load(name string) ([]byte, error)
+	// List returns the names of objects starting with prefix, sorted
+	List(prefix string) ([]string, error)
+	// String describes where the state is kept
+	String() string
+}
+
+// OpenStateStore opens the state for one kind of data. An empty backend keeps it in
+// localDir; gs://bucket/prefix keeps it in Cloud Storage under prefix/kind. Objects are
+// encrypted when --encrypt-state is set.
+func OpenStateStore(backend, localDir, kind string) (StateStore, error) {
+	if backend == "" {
+		return newEncryptedStateStore(&localStateStore{dir: localDir}), nil
+	}
+
+	location, ok := strings.CutPrefix(backend, "gs://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported state backend %q (use gs://bucket/prefix)", backend)
+	}
+	bucket, prefix, _ := strings.Cut(location, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid state backend %q: missing bucket", backend)
+	}
+
+	return newEncryptedStateStore(&gcsStateStore{
+		bucket: bucket,
+		prefix: path.Join(prefix, kind),
+		client: &http.Client{Timeout: 60 * time.Second, Transport: apiTransport},
+	}), nil
+}
+
+// localStateStore keeps state as files in a directory
+type localStateStore struct {
+	dir string
+}
+
+func (s *localStateStore) Save(name string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	return nil
+}
+
+func (s *localStateStore) Load(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return data, nil
+}
+
+func (s *localStateStore) List(prefix string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, prefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state: %v", err)
+	}
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.Base(file)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *localStateStore) String() string {
+	return s.dir
+}
+
+// gcsStateStore keeps state as objects in a Cloud Storage bucket
+type gcsStateStore struct {
+	bucket string
+	prefix string
+	client *http.Client
+}
+
+// object returns the full object name for a state name
+func (s *gcsStateStore) object(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+// do sends an authenticated Cloud Storage request and returns the response body
+func (s *gcsStateStore) do(method, requestURL string, body []byte, what string) ([]byte, error) {
+	accessToken, err := gcloudAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, endpoints.resolve(requestURL), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %v", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to %s, status: %d", what, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %v", what, err)
+	}
+	return data, nil
+}
+
+func (s *gcsStateStore) Save(name string, data []byte) error {
+	requestURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		url.PathEscape(s.bucket), url.QueryEscape(s.object(name)))
+	_, err := s.do("POST", requestURL, data, "upload "+name+" to "+s.String())
+	return err
+}
+
+func (s *gcsStateStore) Load(name string) ([]byte, error) {
+	requestURL := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media",
+		url.PathEscape(s.bucket), url.PathEscape(s.object(name)))
+	return s.do("GET", requestURL, nil, "download "+name+" from "+s.String())
+}
+
+func (s *gcsStateStore) List(prefix string) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("prefix", s.object(prefix))
+		query.Set("fields", "items(name),nextPageToken")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		requestURL := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o?%s", url.PathEscape(s.bucket), query.Encode())
+
+		data, err := s.do("GET", requestURL, nil, "list "+s.String())
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse object list: %v", err)
+		}
+
+		for _, item := range result.Items {
+			names = append(names, path.Base(item.Name))
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *gcsStateStore) String() string {
+	return fmt.Sprintf("gs://%s/%s", s.bucket, s.prefix)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// stateMagic starts encrypted state objects, sealed with sealData like encrypted bundles
+const stateMagic = "GACSTATE1\n"
+
+// keyringStateKey is the keychain entry holding the generated state key when no passphrase is given
+const keyringStateKey = "state-key"
+
+// Encryption of the saved state, set from --encrypt-state and --state-passphrase-from
+var (
+	encryptState        bool
+	statePassphraseFrom string
+)
+
+// stateSecretCache holds the secret once it has been read, so a passphrase in Secret Manager is
+// fetched at most once per run
+var stateSecretCache struct {
+	mu     sync.Mutex
+	secret string
+}
+
+// stateSecret returns the passphrase from --state-passphrase-from or the key in the OS keychain.
+// When create is set and neither exists, a random key is generated and stored in the keychain.
+func stateSecret(create bool) (string, error) {
+	stateSecretCache.mu.Lock()
+	defer stateSecretCache.mu.Unlock()
+	if stateSecretCache.secret != "" {
+		return stateSecretCache.secret, nil
+	}
+
+	if statePassphraseFrom != "" {
+		passphrase, err := resolveToken("", statePassphraseFrom)
+		if err != nil {
+			return "", fmt.Errorf("state passphrase: %v", err)
+		}
+		if passphrase == "" {
+			return "", fmt.Errorf("state passphrase is empty")
+		}
+		registerSecret(passphrase)
+		stateSecretCache.secret = passphrase
+		return passphrase, nil
+	}
+
+	key, err := keyring.Get(keyringService, keyringStateKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		if !create {
+			return "", fmt.Errorf("no state key in the OS keychain (pass --state-passphrase-from)")
+		}
+		random := make([]byte, sealKeySize)
+		if _, err := rand.Read(random); err != nil {
+			return "", fmt.Errorf("failed to generate state key: %v", err)
+		}
+		key = hex.EncodeToString(random)
+		if err := keyring.Set(keyringService, keyringStateKey, key); err != nil {
+			return "", fmt.Errorf("failed to store state key in keychain (or pass --state-passphrase-from): %v", err)
+		}
+		fmt.Println("🔑 Generated a state encryption key and saved it to the OS keychain")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read state key from keychain: %v", err)
+	}
+	registerSecret(key)
+	stateSecretCache.secret = key
+	return key, nil
+}
+
+// encryptedStateStore encrypts what it saves with AES-GCM when encrypt is set and decrypts any
+// encrypted object it loads, so histories written before encryption was turned on still load
+type encryptedStateStore struct {
+	StateStore
+	encrypt bool
+
+	mu sync.Mutex
+	// salt is reused for every object the store seals, so a run derives the key only once
+	salt []byte
+	// ciphers are the derived ciphers by salt
+	ciphers map[string]cipher.AEAD
+}
+
+// newEncryptedStateStore wraps a store with the encryption chosen by the flags
+func newEncryptedStateStore(store StateStore) *encryptedStateStore {
+	return &encryptedStateStore{StateStore: store, encrypt: encryptState || statePassphraseFrom != "", ciphers: make(map[string]cipher.AEAD)}
+}
+
+// cipherFor derives the AES-GCM cipher for a salt, once per salt
+func (s *encryptedStateStore) cipherFor(salt []byte, create bool) (cipher.AEAD, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gcm, ok := s.ciphers[string(salt)]; ok {
+		return gcm, nil
+	}
+
+	secret, err := stateSecret(create)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := sealCipher(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+	s.ciphers[string(salt)] = gcm
+	// Seal with the salt of state already loaded so that one derivation serves the whole run
+	if s.salt == nil {
+		s.salt = salt
+	}
+	return gcm, nil
+}
+
+func (s *encryptedStateStore) Save(name string, data []byte) error {
+	if !s.encrypt {
+		return s.StateStore.Save(name, data)
+	}
+
+	s.mu.Lock()
+	salt := s.salt
+	s.mu.Unlock()
+	if salt == nil {
+		var err error
+		if salt, err = newSealSalt(); err != nil {
+			return err
+		}
+	}
+	gcm, err := s.cipherFor(salt, true)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealData(gcm, stateMagic, salt, data, []byte(stateMagic+name))
+	if err != nil {
+		return err
+	}
+	return s.StateStore.Save(name, sealed)
+}
+
+func (s *encryptedStateStore) Load(name string) ([]byte, error) {
+	data, err := s.StateStore.Load(name)
+	if err != nil || !isSealed(data, stateMagic) {
+		return data, err
+	}
+
+	salt, ok := sealedSalt(data, stateMagic)
+	if !ok {
+		return nil, fmt.Errorf("%s: encrypted state is truncated", name)
+	}
+	gcm, err := s.cipherFor(salt, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s is encrypted: %v", name, err)
+	}
+
+	plain, err := openSealed(gcm, stateMagic, data, []byte(stateMagic+name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: wrong state key or corrupted data", name)
+	}
+	return plain, nil
+}
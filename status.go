@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// incidentsURL is the public Google Cloud status dashboard incident feed
+const incidentsURL = "https://status.cloud.google.com/incidents.json"
+
+// Incident is an ongoing Google Cloud status dashboard incident affecting an API
+type Incident struct {
+	ID       string    `json:"id"`
+	Summary  string    `json:"summary"`
+	Severity string    `json:"severity"`
+	Begin    time.Time `json:"begin"`
+	URL      string    `json:"url"`
+}
+
+// statusProductAPIs maps status dashboard product titles that have no billing service
+// equivalent to the API they affect
+var statusProductAPIs = map[string]string{
+	"Persistent Disk":                "compute.googleapis.com",
+	"Cloud Load Balancing":           "compute.googleapis.com",
+	"Virtual Private Cloud (VPC)":    "compute.googleapis.com",
+	"Identity and Access Management": "iam.googleapis.com",
+	"Artifact Registry":              "artifactregistry.googleapis.com",
+	"Container Registry":             "containerregistry.googleapis.com",
+	"Cloud DNS":                      "dns.googleapis.com",
+	"Cloud Composer":                 "composer.googleapis.com",
+	"Memorystore for Redis":          "redis.googleapis.com",
+	"Firebase Authentication":        "identitytoolkit.googleapis.com",
+	"Cloud Firestore":                "firestore.googleapis.com",
+	"Vertex AI Online Prediction":    "aiplatform.googleapis.com",
+	"Vertex AI Gemini API":           "aiplatform.googleapis.com",
+	"Google Maps Platform":           "maps.googleapis.com",
+}
+
+// incidentProductAPI resolves a status dashboard product title such as "Google Compute Engine"
+// to an API name, or "" when it is not known
+func incidentProductAPI(title string) string {
+	candidates := []string{
+		title,
+		strings.TrimPrefix(title, "Google "),
+		strings.TrimPrefix(title, "Google Cloud "),
+	}
+	for _, candidate := range candidates {
+		if apiName, ok := statusProductAPIs[candidate]; ok {
+			return apiName
+		}
+		if apiName, ok := billingServiceAPIs[candidate]; ok {
+			return apiName
+		}
+	}
+	return ""
+}
+
+// annotateIncidents attaches ongoing status dashboard incidents to the enabled APIs they affect
+func (c *GoogleAPIChecker) annotateIncidents(results []APIResult) error {
+	incidents, err := c.getActiveIncidents()
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		if results[i].Enabled {
+			results[i].Incidents = incidents[results[i].Name]
+		}
+	}
+	return nil
+}
+
+// getActiveIncidents fetches the status dashboard feed and returns ongoing incidents by API name
+func (c *GoogleAPIChecker) getActiveIncidents() (map[string][]Incident, error) {
+	// The feed is public; the API key is not sent to it
+	req, err := http.NewRequest("GET", incidentsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch status dashboard incidents: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch status dashboard incidents, status: %d", resp.StatusCode)
+	}
+
+	var feed []struct {
+		ID               string     `json:"id"`
+		Number           string     `json:"number"`
+		Begin            time.Time  `json:"begin"`
+		End              *time.Time `json:"end"`
+		ExternalDesc     string     `json:"external_desc"`
+		Severity         string     `json:"severity"`
+		URI              string     `json:"uri"`
+		AffectedProducts []struct {
+			Title string `json:"title"`
+		} `json:"affected_products"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to parse status dashboard incidents: %v", err)
+	}
+
+	incidents := make(map[string][]Incident)
+	for _, entry := range feed {
+		if entry.End != nil {
+			continue
+		}
+
+		incident := Incident{
+			ID:       entry.Number,
+			Summary:  strings.TrimSpace(entry.ExternalDesc),
+			Severity: entry.Severity,
+			Begin:    entry.Begin,
+			URL:      "https://status.cloud.google.com/" + strings.TrimPrefix(entry.URI, "/"),
+		}
+		if incident.ID == "" {
+			incident.ID = entry.ID
+		}
+
+		seen := make(map[string]bool)
+		for _, product := range entry.AffectedProducts {
+			apiName := incidentProductAPI(product.Title)
+			if apiName == "" || seen[apiName] {
+				continue
+			}
+			seen[apiName] = true
+			incidents[apiName] = append(incidents[apiName], incident)
+		}
+	}
+
+	return incidents, nil
+}
+
+// String describes the incident on one line
+func (i Incident) String() string {
+	return fmt.Sprintf("[%s] %s (since %s) %s", strings.ToUpper(i.Severity), i.Summary, i.Begin.Format("2006-01-02 15:04 MST"), i.URL)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// The summary layout, set from --summary-template
+var (
+	summaryTemplateFile string
+	summaryTemplate     *template.Template
+)
+
+// defaultSummaryTemplate is the layout of the summary export; --summary-template replaces it.
+// Templates get the report (the same fields as the report JSON) and summaryFuncs.
+const defaultSummaryTemplate = `Google API Checker Summary Report
+Generated: {{.GeneratedAt.Format "2006-01-02 15:04:05"}}
+Tool version: {{.ToolVersion}}
+{{if .Tags}}Tags: {{tags .Tags}}
+{{end}}{{if .Summary.Keyless}}{{keylessNotice}}
+{{end}}{{with skippedNotice .}}{{.}}
+{{end}}
+SUMMARY:
+  Total APIs: {{.Summary.TotalAPIs}}
+  Enabled: {{.Summary.EnabledCount}}
+  Disabled: {{.Summary.DisabledCount}}
+  Errors: {{.Summary.ErrorCount}}
+{{if .Summary.UnknownCount}}  Undetermined: {{.Summary.UnknownCount}}
+{{end}}{{if .Summary.SkippedCount}}  Skipped (quota exhausted): {{.Summary.SkippedCount}}
+{{end}}  Total Cost: ${{money (amount .Summary.TotalCost)}} {{.Summary.Currency}}{{period}}
+{{if gt .Summary.PotentialSavings 0.0}}  Potential Savings: ${{money (amount .Summary.PotentialSavings)}} {{.Summary.Currency}}{{period}}
+{{range .Savings.Items}}    - {{.DisplayName}}: {{.Action}}, {{cost .Monthly}}
+{{end}}{{end}}
+{{if .Scores}}SCORE:
+{{range .Scores}}  {{.Label}}: {{.}}
+{{end}}
+{{end}}{{with .CostAnalysis.UnlimitedCostAPIs}}UNLIMITED COST APIS ({{len .}}):
+{{range .}}  • {{.DisplayName}}
+{{end}}
+{{end}}{{with .Findings}}PROJECT FINDINGS ({{len .}}):
+{{range .}}  • [{{upper .Severity}}] {{.}}
+{{if .Recipients}}    → {{join .Recipients ", "}}
+{{end}}{{end}}
+{{end}}{{with .Compliance}}CIS BENCHMARK CONTROLS FAILED ({{len .}}, {{cisBenchmark}}):
+{{range .}}  • {{.}}
+{{end}}
+{{end}}{{with .Contacts}}OWNERS & CONTACTS:
+{{range .}}  • {{.ProjectID}}: {{.}}
+{{end}}
+{{end}}{{with .CostAnomalies}}COST ANOMALIES SINCE PREVIOUS SCAN ({{len .}}):
+{{range .}}  • {{.}}
+{{end}}
+{{end}}{{with .RiskScoredAPIs}}MAPS PLATFORM ABUSE RISK ({{len .}}):
+{{range .}}  • {{.DisplayName}}: {{.RiskScore}}/100 ({{riskLevel .RiskScore}}) - {{join .RiskFactors ", "}}
+{{end}}
+{{end}}{{with .PolicyViolations}}POLICY VIOLATIONS ({{len .}}):
+{{range .}}  • {{violation .}}
+{{end}}
+{{end}}{{with .Drift}}DRIFT FROM EXPECTED STATE ({{len .}}):
+{{range .}}  • {{.}}
+{{end}}
+{{end}}{{with .IncidentAPIs}}ACTIVE INCIDENTS ({{len .}} APIs):
+{{range $api := .}}{{range .Incidents}}  • {{incidentLabel $api}}: {{.}}
+{{end}}{{end}}
+{{end}}{{with .HighRiskAPIs}}COMMON ABUSE TARGETS ({{len .}}):
+{{range .}}  • {{.DisplayName}}: {{.RiskNote}}
+{{end}}
+{{end}}{{with .Summary.ActualCost}}ESTIMATED VS ACTUAL (last month): ${{money $.Summary.TotalCost}} estimated, ${{money .}} billed
+{{range $.CostAnalysis.ReconciledAPIs}}  • {{.DisplayName}}: ${{money .CostInfo.EstimatedCost}} estimated, ${{money .CostInfo.ActualCost}} actual ({{printf "%+.2f" (gap .)}})
+{{end}}
+{{end}}{{with .CostAnalysis.CostAllocation}}BILLED COST BY LABEL (last month):
+{{range .}}  • {{.Label}}={{.Value}}: ${{money .Cost}} across {{len .APIs}} APIs
+{{end}}
+{{end}}{{with .CostAnalysis.FreeTierAPIs}}COVERED BY FREE TIER ({{len .}}):
+{{range .}}  • {{.DisplayName}}: {{quantity .CostInfo.ExpectedUsage}} {{.CostInfo.UsageUnit}}/month
+{{end}}
+{{end}}{{with .UnusedAPIs}}ENABLED BUT UNUSED APIS ({{len .}}):
+{{range .}}  • {{.DisplayName}} ({{.Name}})
+{{end}}
+{{end}}{{with .CostAnalysis.HighCostAPIs}}HIGH COST APIS ({{len .}}):
+{{range .}}  • {{.DisplayName}}: {{cost .CostInfo.EstimatedCost}}
+{{end}}
+{{end}}{{with .Performance}}PERFORMANCE ({{.Checks}} checks):
+  p50 {{.P50Ms}}ms, p95 {{.P95Ms}}ms, max {{.MaxMs}}ms, {{.Throttled}} throttled
+{{range .Slowest}}  • {{.Name}}: {{.DurationMs}}ms
+{{end}}
+{{end}}{{with .Recommendations}}RECOMMENDATIONS:
+{{range .}}  • {{.}}
+{{end}}{{end}}`
+
+// summaryFuncs are the functions summary templates can call besides the text/template builtins
+func summaryFuncs() template.FuncMap {
+	return template.FuncMap{
+		// amount converts a monthly amount to the --period, period is its suffix, e.g. /month
+		"amount": func(monthly float64) float64 { return projection.amount(monthly) },
+		"period": func() string { return projection.Suffix },
+		// cost formats a monthly amount for the --period, e.g. $12.00/month
+		"cost":          formatCost,
+		"money":         func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+		"quantity":      formatQuantity,
+		"upper":         strings.ToUpper,
+		"join":          strings.Join,
+		"tags":          formatTags,
+		"violation":     violationText,
+		"incidentLabel": incidentAPILabel,
+		"riskLevel":     riskLevel,
+		"gap":           reconciliationGap,
+		"skippedNotice": skippedNotice,
+		"keylessNotice": func() string { return keylessNotice },
+		"cisBenchmark":  func() string { return cisBenchmark },
+	}
+}
+
+// LoadSummaryTemplate parses a summary template file, or the default layout when filename is empty
+func LoadSummaryTemplate(filename string) (*template.Template, error) {
+	text := defaultSummaryTemplate
+	name := "summary"
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read summary template: %v", err)
+		}
+		text, name = string(data), filename
+	}
+
+	tmpl, err := template.New(name).Funcs(summaryFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// ExportSummary exports a summary report rendered with options.SummaryTemplate, or the default layout
+func ExportSummary(report *Report, options ExportOptions) error {
+	filename := options.Artifacts.Path("summary", "txt")
+
+	tmpl := options.SummaryTemplate
+	if tmpl == nil {
+		var err error
+		if tmpl, err = LoadSummaryTemplate(""); err != nil {
+			return err
+		}
+	}
+
+	// Render first so a failing template does not leave a half-written file
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return fmt.Errorf("failed to render summary: %v", err)
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to create summary file: %v", err)
+	}
+
+	fmt.Printf("✅ Summary exported to: %s\n", filename)
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultConsoleWidth is the width tables are fitted to when neither the terminal nor $COLUMNS tells
+const defaultConsoleWidth = 120
+
+// tableGap separates table columns
+const tableGap = "  "
+
+// consoleWidth is the width console tables are fitted to: the terminal's, else $COLUMNS
+func consoleWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultConsoleWidth
+}
+
+// tableColumn describes a column of a console table and how it gives way on narrow terminals
+type tableColumn struct {
+	Header string
+	Right  bool // Right-aligned, e.g. amounts
+	// Flexible columns are truncated with an ellipsis, down to MinWidth, before any column is dropped
+	Flexible bool
+	MinWidth int
+	// Drop orders the columns left out when truncating is not enough, highest first; 0 is never dropped
+	Drop int
+}
+
+// consoleTable lays out rows in columns fitted to the console width
+type consoleTable struct {
+	Indent  string
+	Columns []tableColumn
+	rows    [][]string
+}
+
+// newConsoleTable creates a table indented like the report sections
+func newConsoleTable(columns ...tableColumn) *consoleTable {
+	return &consoleTable{Indent: "   ", Columns: columns}
+}
+
+// AddRow adds a row; cells may hold color codes
+func (t *consoleTable) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// visibleWidth is the number of runes shown for s, not counting color codes
+func visibleWidth(s string) int {
+	return len([]rune(ansiEscape.ReplaceAllString(s, "")))
+}
+
+// fitCell truncates s with an ellipsis to width and pads it to width; colors are lost when truncating
+func fitCell(s string, width int, right bool) string {
+	if visibleWidth(s) > width {
+		runes := []rune(ansiEscape.ReplaceAllString(s, ""))
+		if width <= 1 {
+			s = string(runes[:width])
+		} else {
+			s = string(runes[:width-1]) + "…"
+		}
+	}
+	padding := strings.Repeat(" ", width-visibleWidth(s))
+	if right {
+		return padding + s
+	}
+	return s + padding
+}
+
+// layout returns the width of each column and which are shown, so the table fits in width
+func (t *consoleTable) layout(width int) ([]int, []bool) {
+	natural := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		natural[i] = visibleWidth(column.Header)
+		for _, row := range t.rows {
+			if i < len(row) {
+				natural[i] = max(natural[i], visibleWidth(row[i]))
+			}
+		}
+	}
+
+	// Columns with nothing in them take no room
+	shown := make([]bool, len(t.Columns))
+	for i := range shown {
+		shown[i] = natural[i] > 0
+	}
+	for {
+		widths := make([]int, len(t.Columns))
+		total := len(t.Indent)
+		count := 0
+		for i := range t.Columns {
+			if shown[i] {
+				widths[i] = natural[i]
+				total += natural[i]
+				count++
+			}
+		}
+		total += len(tableGap) * max(count-1, 0)
+
+		// Take the excess from the widest flexible columns first
+		for excess := total - width; excess > 0; {
+			widest := -1
+			for i, column := range t.Columns {
+				if shown[i] && column.Flexible && widths[i] > max(column.MinWidth, 1) && (widest < 0 || widths[i] > widths[widest]) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			excess--
+			total--
+		}
+		if total <= width {
+			return widths, shown
+		}
+
+		drop := -1
+		for i, column := range t.Columns {
+			if shown[i] && column.Drop > 0 && (drop < 0 || column.Drop > t.Columns[drop].Drop) {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			// Nothing left to give way; the terminal wraps the rest
+			return widths, shown
+		}
+		shown[drop] = false
+	}
+}
+
+// Render writes the header, unless no column has one, and the rows fitted to width
+func (t *consoleTable) Render(w io.Writer, width int) {
+	widths, shown := t.layout(width)
+	line := func(cells []string) {
+		var parts []string
+		for i, column := range t.Columns {
+			if !shown[i] {
+				continue
+			}
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			parts = append(parts, fitCell(cell, widths[i], column.Right))
+		}
+		fmt.Fprintln(w, t.Indent+strings.TrimRight(strings.Join(parts, tableGap), " "))
+	}
+
+	headers := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		headers[i] = column.Header
+	}
+	if strings.TrimSpace(strings.Join(headers, "")) != "" {
+		line(headers)
+	}
+	for _, row := range t.rows {
+		line(row)
+	}
+}
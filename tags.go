@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagKeyPattern limits tag keys to names that are safe in filters and file formats
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// parseTags parses key=value tags such as trigger=ci or release=v42
+func parseTags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	tags := make(map[string]string, len(values))
+	for _, value := range values {
+		key, tagValue, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tag %q (use key=value)", value)
+		}
+		tags[key] = tagValue
+	}
+	if err := validateTags(tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// validateTags checks tag keys, e.g. from a remote scan request
+func validateTags(tags map[string]string) error {
+	for key := range tags {
+		if !tagKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid tag key %q (use letters, digits, '.', '_' or '-')", key)
+		}
+	}
+	return nil
+}
+
+// matchTags reports whether tags has every key and value in filter
+func matchTags(tags, filter map[string]string) bool {
+	for key, value := range filter {
+		if tagValue, ok := tags[key]; !ok || tagValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTags lists the tags as key=value sorted by key, e.g. "release=v42, trigger=ci"
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// serverTenant is a team using the scan API with its own client tokens, credentials and results
+type serverTenant struct {
+	name string
+	// grants are the roles of the tenant's client tokens and Google identities
+	grants   []accessGrant
+	projects []string
+	// maxScans caps the tenant's queued and running scans, 0 is no cap
+	maxScans int
+	// history keeps the tenant's finished scans, nil keeps them in memory only
+	history StateStore
+
+	// mu guards the Google API token, which admins can replace while the server runs
+	mu           sync.Mutex
+	apiToken     string
+	tokenSource  string
+	tokenUpdated *time.Time
+}
+
+// token returns the Google API token for the tenant's next scan
+func (t *serverTenant) token() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.apiToken
+}
+
+// setAPIToken replaces the Google API token used by the tenant's later scans
+func (t *serverTenant) setAPIToken(token, source string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.apiToken, t.tokenSource, t.tokenUpdated = token, source, &now
+}
+
+// credentialsStatus describes the tenant's Google API token without revealing it
+func (t *serverTenant) credentialsStatus() CredentialsStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return CredentialsStatus{Tenant: t.name, Source: t.tokenSource, UpdatedAt: t.tokenUpdated}
+}
+
+// loadTenants resolves the tokens and result storage of the tenants in the config file
+func loadTenants(tenants []TenantConfig) ([]*serverTenant, error) {
+	names := make(map[string]bool)
+	owners := make(map[string]string)
+	loaded := make([]*serverTenant, 0, len(tenants))
+
+	for i, tenant := range tenants {
+		if tenant.Name == "" {
+			return nil, fmt.Errorf("tenant %d: missing name", i+1)
+		}
+		if names[tenant.Name] {
+			return nil, fmt.Errorf("tenant %s is defined twice", tenant.Name)
+		}
+		names[tenant.Name] = true
+
+		if tenant.MaxScans < 0 {
+			return nil, fmt.Errorf("tenant %s: max_scans must not be negative", tenant.Name)
+		}
+		if tenant.AuthTokenFrom == "" && len(tenant.Access) == 0 {
+			return nil, fmt.Errorf("tenant %s: missing auth_token_from or access", tenant.Name)
+		}
+		// The tenant's own auth token has full access, as before roles existed
+		access := tenant.Access
+		if tenant.AuthTokenFrom != "" {
+			access = append([]AccessConfig{{Role: "admin", TokenFrom: tenant.AuthTokenFrom}}, access...)
+		}
+		grants, err := loadGrants(access, "tenant "+tenant.Name, owners)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %v", tenant.Name, err)
+		}
+
+		tenantToken, tokenSource := apiToken, "server token"
+		if tenant.TokenFrom != "" {
+			tokenSource = tenant.TokenFrom
+			if tenantToken, err = resolveToken("", tenant.TokenFrom); err != nil {
+				return nil, fmt.Errorf("tenant %s: %v", tenant.Name, err)
+			}
+		}
+		if tenantToken == "" {
+			return nil, fmt.Errorf("tenant %s: no API token (set token_from or pass --token)", tenant.Name)
+		}
+		registerSecret(tenantToken)
+
+		var history StateStore
+		if tenant.HistoryDir != "" || tenant.StateBackend != "" {
+			// Tenants sharing a bucket still get separate prefixes
+			if history, err = OpenStateStore(tenant.StateBackend, tenant.HistoryDir, path.Join(tenant.Name, "history")); err != nil {
+				return nil, fmt.Errorf("tenant %s: %v", tenant.Name, err)
+			}
+		}
+
+		loaded = append(loaded, &serverTenant{
+			name:        tenant.Name,
+			grants:      grants,
+			projects:    tenant.Projects,
+			maxScans:    tenant.MaxScans,
+			history:     history,
+			apiToken:    tenantToken,
+			tokenSource: tokenSource,
+		})
+	}
+	return loaded, nil
+}
+
+// allows reports whether the tenant may scan the project
+func (t *serverTenant) allows(project string) bool {
+	if len(t.projects) == 0 {
+		return true
+	}
+	for _, allowed := range t.projects {
+		if allowed == project {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProjects defaults an empty project list to the tenant's projects and rejects projects it may not scan
+func (t *serverTenant) checkProjects(request *ScanRequest) error {
+	if len(request.Projects) == 0 {
+		request.Projects = t.projects
+	}
+	for _, project := range request.Projects {
+		if !t.allows(project) {
+			return fmt.Errorf("tenant %s may not scan project %s", t.name, project)
+		}
+	}
+	return nil
+}
+
+// requireServerToken needs the server's API token unless every tenant brings its own
+func requireServerToken(cmd *cobra.Command, args []string) error {
+	if len(config.Tenants) > 0 {
+		ownTokens := true
+		for _, tenant := range config.Tenants {
+			ownTokens = ownTokens && tenant.TokenFrom != ""
+		}
+		if ownTokens {
+			return nil
+		}
+	}
+	return requireToken(cmd, args)
+}
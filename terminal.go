@@ -0,0 +1,94 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// terminalCaps describes what stdout can render
+type terminalCaps struct {
+	Color   bool // ANSI color escapes
+	Unicode bool // Emoji, braille spinners and block characters
+}
+
+// terminal holds the detected capabilities; everything is on until initCommand runs detection
+var terminal = terminalCaps{Color: true, Unicode: true}
+
+// console is where the report and progress output go, adapted to the terminal
+var console io.Writer = os.Stdout
+
+// ansiEscape matches the SGR color sequences used in console output
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// emojiSymbol matches emoji and pictographs without an ASCII fallback, with the space that follows them
+var emojiSymbol = regexp.MustCompile(`[\p{So}\x{FE0F}\x{200D}]+ ?`)
+
+// asciiSymbols are the ASCII fallbacks for symbols that carry meaning
+var asciiSymbols = strings.NewReplacer(
+	"⚠️", "[!]",
+	"🚨", "[!]",
+	"✅", "[ok]",
+	"❌", "[x]",
+	"⛔", "[-]",
+	"⚠", "[!]",
+	"✔", "[ok]",
+	"✖", "[x]",
+	"↗", "[+]",
+	"↘", "[-]",
+	"⊘", "[dep]",
+	"•", "-",
+	"→", "->",
+	"…", "...",
+	"█", "#",
+	"░", ".",
+)
+
+// detectTerminal works out what the file can render; noColor and ascii come from --no-color and --ascii
+func detectTerminal(file *os.File, noColor, ascii bool) terminalCaps {
+	interactive := isTerminal(file)
+	return terminalCaps{
+		Color: !noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" &&
+			interactive && enableVirtualTerminal(file),
+		Unicode: !ascii && unicodeConsole(),
+	}
+}
+
+// consoleWriter strips colors and replaces symbols the terminal cannot render
+type consoleWriter struct {
+	w    io.Writer
+	caps terminalCaps
+}
+
+func (c consoleWriter) Write(p []byte) (int, error) {
+	text := string(p)
+	if !c.caps.Color {
+		text = ansiEscape.ReplaceAllString(text, "")
+	}
+	if !c.caps.Unicode {
+		text = emojiSymbol.ReplaceAllString(asciiSymbols.Replace(text), "")
+	}
+	if _, err := io.WriteString(c.w, text); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// spinnerFrames returns the spinner animation the terminal can draw
+func spinnerFrames() []string {
+	if !terminal.Unicode {
+		return []string{"|", "/", "-", "\\"}
+	}
+	return []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+}
+
+// setupConsole detects the terminal and routes console output through the matching fallbacks
+func setupConsole(noColor, ascii bool) {
+	terminal = detectTerminal(os.Stdout, noColor, ascii)
+	if terminal.Color && terminal.Unicode {
+		console = os.Stdout
+		return
+	}
+	console = consoleWriter{os.Stdout, terminal}
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// enableVirtualTerminal is a no-op; Unix terminals handle ANSI escapes
+func enableVirtualTerminal(file *os.File) bool {
+	return true
+}
+
+// unicodeConsole reports whether the locale is UTF-8, assuming it is when no locale is set
+func unicodeConsole() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToUpper(locale)
+			return strings.Contains(locale, "UTF-8") || strings.Contains(locale, "UTF8")
+		}
+	}
+	return true
+}
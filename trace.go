@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// traceVerbosity is the -v count at which requests and parse decisions are traced (-vv)
+const traceVerbosity = 2
+
+// traceOutput receives trace lines; they go to stderr so the report on stdout stays clean
+var traceOutput io.Writer = redactingWriter{os.Stderr}
+
+// traceMu keeps trace lines from several workers from interleaving
+var traceMu sync.Mutex
+
+// traceWorkerKey is the context key holding the worker a request is sent for
+type traceWorkerKey struct{}
+
+// tracePricerKey is the context key holding the pricing worker a lookup is made for
+type tracePricerKey struct{}
+
+// withTraceWorker tags the requests made with ctx with the worker sending them
+func withTraceWorker(ctx context.Context, worker int) context.Context {
+	return context.WithValue(ctx, traceWorkerKey{}, worker)
+}
+
+// withTracePricer tags the lookups made with ctx with the pricing worker making them
+func withTracePricer(ctx context.Context, pricer int) context.Context {
+	return context.WithValue(ctx, tracePricerKey{}, pricer)
+}
+
+// tracing reports whether -vv tracing is on
+func (c *GoogleAPIChecker) tracing() bool {
+	return c.options.Verbosity >= traceVerbosity
+}
+
+// trace prints a -vv trace line labelled with the project and the worker in ctx, secrets redacted
+func (c *GoogleAPIChecker) trace(ctx context.Context, format string, args ...interface{}) {
+	if !c.tracing() {
+		return
+	}
+
+	label := "[main]"
+	if worker, ok := ctx.Value(traceWorkerKey{}).(int); ok {
+		label = fmt.Sprintf("[worker %d]", worker)
+	} else if pricer, ok := ctx.Value(tracePricerKey{}).(int); ok {
+		label = fmt.Sprintf("[pricer %d]", pricer)
+	}
+	if c.projectID != "" {
+		label = "[" + c.projectID + "]" + label
+	}
+
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	fmt.Fprintf(traceOutput, "%s %s %s\n", time.Now().Format("15:04:05.000"), label, fmt.Sprintf(format, args...))
+}
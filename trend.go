@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxTrendScans is how many of the latest history scans the HTML report's trend charts show
+const maxTrendScans = 90
+
+// TrendPoint is the enabled-API count and estimated monthly cost of one scan in the history
+type TrendPoint struct {
+	GeneratedAt  time.Time `json:"generated_at"`
+	EnabledCount int       `json:"enabled_count"`
+	TotalCost    float64   `json:"total_cost"`
+}
+
+// LoadTrend summarizes the latest scans in the history, oldest first. Scans that cannot be
+// read are skipped with a warning.
+func LoadTrend(store StateStore) ([]TrendPoint, error) {
+	files, err := historyFiles(store)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > maxTrendScans {
+		files = files[len(files)-maxTrendScans:]
+	}
+
+	var trend []TrendPoint
+	for _, name := range files {
+		data, err := store.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		resultsFile, err := decodeResultsFile(data, name)
+		if err != nil {
+			log.Printf("Warning: skipping %s in the trend: %v", name, err)
+			continue
+		}
+
+		point := TrendPoint{GeneratedAt: resultsFile.GeneratedAt}
+		for _, result := range resultsFile.Results {
+			if result.Enabled {
+				point.EnabledCount++
+				point.TotalCost += enabledCost(result)
+			}
+		}
+		trend = append(trend, point)
+	}
+	return trend, nil
+}
+
+// trendChart draws values as an SVG line chart labelled with the first and last scan dates
+func trendChart(title string, trend []TrendPoint, value func(TrendPoint) float64, format func(float64) string, color string) string {
+	const (
+		width   = 480.0
+		height  = 160.0
+		padding = 24.0
+	)
+
+	low, high := value(trend[0]), value(trend[0])
+	for _, point := range trend {
+		low, high = min(low, value(point)), max(high, value(point))
+	}
+	if high == low {
+		// A flat line is drawn across the middle
+		low, high = low-1, high+1
+	}
+
+	var points []string
+	for i, point := range trend {
+		x := padding + float64(i)*(width-2*padding)/float64(len(trend)-1)
+		y := height - padding - (value(point)-low)*(height-2*padding)/(high-low)
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	first, last := trend[0], trend[len(trend)-1]
+	label := fmt.Sprintf("%s: %s on %s, %s on %s", title, format(value(first)), first.GeneratedAt.Format("2006-01-02"),
+		format(value(last)), last.GeneratedAt.Format("2006-01-02"))
+	return fmt.Sprintf(`<figure class="bg-white dark:bg-gray-800 rounded-lg p-4 shadow-md">
+                    <figcaption class="text-gray-700 dark:text-gray-300 font-medium mb-2">%s <span class="font-semibold">%s</span></figcaption>
+                    <svg viewBox="0 0 %.0f %.0f" class="w-full h-40" role="img" aria-label="%s">
+                        <polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>
+                        <text x="%.0f" y="%.0f" class="fill-current text-gray-500" font-size="11">%s</text>
+                        <text x="%.0f" y="%.0f" class="fill-current text-gray-500" font-size="11" text-anchor="end">%s</text>
+                    </svg>
+                </figure>`,
+		html.EscapeString(title), html.EscapeString(format(value(last))), width, height, html.EscapeString(label),
+		color, strings.Join(points, " "),
+		padding, height-4, first.GeneratedAt.Format("2006-01-02"),
+		width-padding, height-4, last.GeneratedAt.Format("2006-01-02"))
+}
+
+// htmlTrendSection is the HTML report's cost and enabled-API charts over the scan history,
+// empty with fewer than two scans
+func htmlTrendSection(trend []TrendPoint) string {
+	if len(trend) < 2 {
+		return ""
+	}
+
+	cost := trendChart("Estimated cost", trend, func(p TrendPoint) float64 { return p.TotalCost }, formatCost, "#7e22ce")
+	enabled := trendChart("Enabled APIs", trend, func(p TrendPoint) float64 { return float64(p.EnabledCount) },
+		func(v float64) string { return fmt.Sprintf("%.0f", v) }, "#15803d")
+	return fmt.Sprintf(`
+            <section class="mb-8" aria-labelledby="trend-heading">
+                <h2 id="trend-heading" class="text-xl font-semibold text-gray-800 dark:text-gray-100 mb-4">Trend over the last %d scans</h2>
+                <div class="grid grid-cols-1 md:grid-cols-2 gap-6">
+                %s
+                %s
+                </div>
+            </section>`, len(trend), cost, enabled)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UnlimitedCostPolicy decides which APIs are flagged with unlimited cost potential. An API is
+// flagged when it has usage-based pricing, no quota caps its usage and no budget alert covers
+// the project.
+type UnlimitedCostPolicy struct {
+	// LookupQuotas reads each priced API's consumer quota limits; any finite limit caps its usage.
+	// Without it, the pricing table's list of APIs without a default quota cap is used
+	LookupQuotas bool `yaml:"lookup_quotas"`
+	// LookupBudgets looks up whether a budget alert covers the project, which clears the flag
+	LookupBudgets bool `yaml:"lookup_budgets"`
+	// Uncapped and Capped say whether an API's usage is capped, overriding the lookups, e.g. for
+	// quotas you set yourself
+	Uncapped []string `yaml:"uncapped"`
+	Capped   []string `yaml:"capped"`
+}
+
+// quotaCap says whether an API's usage is capped and why
+func (c *GoogleAPIChecker) quotaCap(ctx context.Context, result *APIResult) (bool, string) {
+	policy := c.options.UnlimitedCost
+	switch {
+	case containsService(policy.Capped, result.Name):
+		return true, "quota cap set in the config file"
+	case containsService(policy.Uncapped, result.Name):
+		return false, "no quota cap per the config file"
+	case !policy.LookupQuotas:
+		// The pricing provider marks APIs Google sets no default quota cap on
+		if result.CostInfo.UnlimitedCost {
+			return false, "no default quota cap"
+		}
+		return true, "default quota cap"
+	}
+
+	quotas, err := c.getQuotaInfo(result.Name)
+	if err != nil {
+		c.trace(ctx, "%s: no quota limits: %v", result.Name, err)
+		if result.CostInfo.UnlimitedCost {
+			return false, "no default quota cap (quota lookup failed)"
+		}
+		return true, "default quota cap (quota lookup failed)"
+	}
+	finite := 0
+	for _, quota := range quotas {
+		if quota.Limit >= 0 {
+			finite++
+		}
+	}
+	if finite == 0 {
+		return false, "no finite quota limit"
+	}
+	return true, fmt.Sprintf("%d finite quota limit(s)", finite)
+}
+
+// classifyUnlimitedCost applies the unlimited-cost policy to a priced API, recording the reasoning
+func (c *GoogleAPIChecker) classifyUnlimitedCost(ctx context.Context, result *APIResult) {
+	cost := &result.CostInfo
+	if !cost.HasPricing {
+		cost.UnlimitedCost = false
+		return
+	}
+
+	capped, capReason := c.quotaCap(ctx, result)
+	reasons := []string{"usage-based pricing", capReason}
+
+	budget := "budget alerts not checked"
+	covered := false
+	if c.options.UnlimitedCost.LookupBudgets && !capped && c.useRealAPI && c.projectID != "" {
+		if hasBudget, err := c.budgetAlert(); err != nil {
+			budget = "budget alert lookup failed"
+		} else if *hasBudget {
+			budget, covered = "budget alert covers the project", true
+		} else {
+			budget = "no budget alert"
+		}
+	}
+	if !capped {
+		reasons = append(reasons, budget)
+	}
+
+	cost.UnlimitedCost = !capped && !covered
+	verdict := "not unlimited"
+	if cost.UnlimitedCost {
+		verdict = "unlimited"
+	}
+	cost.UnlimitedReason = verdict + ": " + strings.Join(reasons, ", ")
+	c.trace(ctx, "%s: %s", result.Name, cost.UnlimitedReason)
+}
+
+// budgetAlert reports whether a budget alert covers the project, looking it up once per checker
+func (c *GoogleAPIChecker) budgetAlert() (*bool, error) {
+	c.budgetOnce.Do(func() {
+		c.budget, c.budgetErr = c.hasBudgetAlert()
+	})
+	return c.budget, c.budgetErr
+}
+
+// containsService reports whether the list names the service, with or without .googleapis.com
+func containsService(services []string, apiName string) bool {
+	short := strings.TrimSuffix(apiName, "."+defaultUniverseDomain)
+	for _, service := range services {
+		if service == apiName || service == short {
+			return true
+		}
+	}
+	return false
+}
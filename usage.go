@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// usageWindow is the look-back period for request metrics
+const usageWindow = 90 * 24 * time.Hour
+
+// annotateUsage fills RequestCount90d on enabled results from Cloud Monitoring request metrics
+func (c *GoogleAPIChecker) annotateUsage(results []APIResult) error {
+	if c.projectID == "" {
+		return fmt.Errorf("usage metrics require a project ID")
+	}
+
+	counts, err := c.getRequestCounts()
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		if !results[i].Enabled {
+			continue
+		}
+		// Services without a time series received no requests in the window
+		count := counts[results[i].Name]
+		results[i].RequestCount90d = &count
+	}
+
+	return nil
+}
+
+// getRequestCounts returns the total API request count per service over usageWindow
+func (c *GoogleAPIChecker) getRequestCounts() (map[string]int64, error) {
+	end := time.Now().UTC()
+	start := end.Add(-usageWindow)
+
+	query := url.Values{}
+	query.Set("filter", `metric.type="serviceruntime.googleapis.com/api/request_count"`)
+	query.Set("interval.startTime", start.Format(time.RFC3339))
+	query.Set("interval.endTime", end.Format(time.RFC3339))
+	query.Set("aggregation.alignmentPeriod", fmt.Sprintf("%ds", int(usageWindow.Seconds())))
+	query.Set("aggregation.perSeriesAligner", "ALIGN_SUM")
+	query.Set("aggregation.crossSeriesReducer", "REDUCE_SUM")
+	query.Set("aggregation.groupByFields", "resource.labels.service")
+
+	counts := make(map[string]int64)
+	pageToken := ""
+	for {
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		requestURL := fmt.Sprintf("https://monitoring.googleapis.com/v3/projects/%s/timeSeries?%s", c.projectID, query.Encode())
+		req, err := http.NewRequest("GET", requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Add("X-Goog-Api-Key", c.token)
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, err := c.doRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query request metrics: %v", err)
+		}
+
+		var result struct {
+			TimeSeries []struct {
+				Resource struct {
+					Labels map[string]string `json:"labels"`
+				} `json:"resource"`
+				Points []struct {
+					Value struct {
+						Int64Value string `json:"int64Value"`
+					} `json:"value"`
+				} `json:"points"`
+			} `json:"timeSeries"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to query request metrics, status: %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse request metrics response: %v", err)
+		}
+
+		for _, series := range result.TimeSeries {
+			service := series.Resource.Labels["service"]
+			for _, point := range series.Points {
+				value, err := strconv.ParseInt(point.Value.Int64Value, 10, 64)
+				if err == nil {
+					counts[service] += value
+				}
+			}
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, populated via -ldflags "-X main.Version=..." (see Makefile)
+var (
+	Version   = "dev"
+	Commit    = "none"
+	BuildDate = "unknown"
+)
+
+// toolVersion returns the release version, falling back to module build info for go install builds
+func toolVersion() string {
+	if Version != "dev" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Version
+}
+
+// newVersionCmd creates the subcommand that prints build metadata
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("Google API Checker %s\n", toolVersion())
+			fmt.Printf("  Commit:     %s\n", Commit)
+			fmt.Printf("  Built:      %s\n", BuildDate)
+			fmt.Printf("  Go version: %s\n", runtime.Version())
+			fmt.Printf("  Platform:   %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		},
+	}
+}